@@ -0,0 +1,485 @@
+package acl
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-gost/core/logger"
+	xauth "github.com/go-gost/x/auth"
+	ctxvalue "github.com/go-gost/x/internal/ctx"
+	"github.com/go-gost/x/internal/loader"
+	"github.com/go-gost/x/internal/matcher"
+	xnet "github.com/go-gost/x/internal/net"
+)
+
+const (
+	// the subject prefix of the rules of a client.
+	SubjectUser = "user:"
+	// the subject prefix of the rules of a group.
+	SubjectGroup = "group:"
+	// the subject of the rules of all the clients, including the unauthenticated ones.
+	SubjectAll = "*"
+)
+
+// ACL controls the destinations the clients can access.
+type ACL interface {
+	// Allowed reports whether the client of ctx is allowed to access the destination addr.
+	Allowed(ctx context.Context, network, addr string, opts ...AllowOption) bool
+}
+
+type AllowOptions struct {
+	// the IP addresses the domain of the destination resolves to.
+	IPs []net.IP
+}
+
+type AllowOption func(opts *AllowOptions)
+
+// IPsAllowOption sets the IP addresses the domain of the destination resolves to,
+// they are matched by the IP and CIDR matchers of the rules, and the destination
+// is allowed only if it is allowed for all of them.
+func IPsAllowOption(ips []net.IP) AllowOption {
+	return func(opts *AllowOptions) {
+		opts.IPs = ips
+	}
+}
+
+// Rule is an access rule of the ACL, in the format:
+//
+//	<subject> allow|deny [<matcher>...]
+//
+// The subject is user:<client ID>, group:<group> or * for all the clients.
+// A matcher is a network (tcp or udp), a port or port range prefixed by a colon (:443, :8000-9000),
+// an IP address or CIDR (matched by the addresses the domains resolve to), a wildcard domain (*.example.com), a domain (.example.com for the domain and its subdomains),
+// or * for any host. The rule is matched if all the kinds of its matchers are matched,
+// so the rule without matchers matches all the destinations.
+type Rule struct {
+	Subject  string
+	Allow    bool
+	hosts    []string
+	cidrs    []*net.IPNet
+	ports    []*xnet.PortRange
+	networks []string
+
+	domainMatcher   matcher.Matcher
+	wildcardMatcher matcher.Matcher
+	cidrMatcher     matcher.Matcher
+}
+
+// ParseRule parses a rule of the ACL.
+func ParseRule(s string) (*Rule, error) {
+	fields := strings.Fields(s)
+	if len(fields) < 2 {
+		return nil, fmt.Errorf("invalid rule: %s", s)
+	}
+
+	r := &Rule{
+		Subject: fields[0],
+	}
+	if r.Subject != SubjectAll &&
+		!strings.HasPrefix(r.Subject, SubjectUser) &&
+		!strings.HasPrefix(r.Subject, SubjectGroup) {
+		return nil, fmt.Errorf("invalid subject %s: %s", r.Subject, s)
+	}
+
+	switch strings.ToLower(fields[1]) {
+	case "allow":
+		r.Allow = true
+	case "deny":
+	default:
+		return nil, fmt.Errorf("invalid action %s: %s", fields[1], s)
+	}
+
+	var domains, wildcards []string
+	for _, v := range fields[2:] {
+		switch {
+		case v == "*":
+		case strings.EqualFold(v, "tcp"), strings.EqualFold(v, "udp"):
+			r.networks = append(r.networks, strings.ToLower(v))
+		case strings.HasPrefix(v, ":"):
+			pr := &xnet.PortRange{}
+			if err := pr.Parse(v[1:]); err != nil {
+				return nil, fmt.Errorf("invalid port %s: %s", v, s)
+			}
+			r.ports = append(r.ports, pr)
+		case net.ParseIP(v) != nil:
+			ip := net.ParseIP(v)
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			r.cidrs = append(r.cidrs, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+		case strings.Contains(v, "/"):
+			_, inet, err := net.ParseCIDR(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid CIDR %s: %s", v, s)
+			}
+			r.cidrs = append(r.cidrs, inet)
+		case strings.ContainsAny(v, "*?"):
+			wildcards = append(wildcards, v)
+		default:
+			domains = append(domains, v)
+		}
+	}
+
+	r.hosts = append(domains, wildcards...)
+	if len(domains) > 0 {
+		r.domainMatcher = matcher.DomainMatcher(domains)
+	}
+	if len(wildcards) > 0 {
+		r.wildcardMatcher = matcher.WildcardMatcher(wildcards)
+	}
+	if len(r.cidrs) > 0 {
+		r.cidrMatcher = matcher.CIDRMatcher(r.cidrs)
+	}
+	return r, nil
+}
+
+// dest is the destination to be matched.
+type dest struct {
+	network string
+	// the domain of the destination, empty if the destination is an IP address.
+	domain string
+	// the IP address of the destination, or the address its domain resolves to.
+	ip   string
+	port int
+}
+
+func (r *Rule) match(d *dest) bool {
+	if len(r.hosts) > 0 || r.cidrMatcher != nil {
+		matched := d.domain != "" &&
+			(r.domainMatcher != nil && r.domainMatcher.Match(d.domain) ||
+				r.wildcardMatcher != nil && r.wildcardMatcher.Match(d.domain)) ||
+			d.ip != "" && r.cidrMatcher != nil && r.cidrMatcher.Match(d.ip)
+		if !matched {
+			return false
+		}
+	}
+	if len(r.ports) > 0 {
+		matched := false
+		for _, pr := range r.ports {
+			if pr.Contains(d.port) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	if len(r.networks) > 0 {
+		matched := false
+		for _, network := range r.networks {
+			if network == d.network {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+type options struct {
+	rules       []string
+	fileLoader  loader.Loader
+	redisLoader loader.Loader
+	httpLoader  loader.Loader
+	period      time.Duration
+	logger      logger.Logger
+}
+
+type Option func(opts *options)
+
+func RulesOption(rules []string) Option {
+	return func(opts *options) {
+		opts.rules = rules
+	}
+}
+
+func ReloadPeriodOption(period time.Duration) Option {
+	return func(opts *options) {
+		opts.period = period
+	}
+}
+
+func FileLoaderOption(fileLoader loader.Loader) Option {
+	return func(opts *options) {
+		opts.fileLoader = fileLoader
+	}
+}
+
+func RedisLoaderOption(redisLoader loader.Loader) Option {
+	return func(opts *options) {
+		opts.redisLoader = redisLoader
+	}
+}
+
+func HTTPLoaderOption(httpLoader loader.Loader) Option {
+	return func(opts *options) {
+		opts.httpLoader = httpLoader
+	}
+}
+
+func LoggerOption(logger logger.Logger) Option {
+	return func(opts *options) {
+		opts.logger = logger
+	}
+}
+
+// localACL is an ACL of the ordered rules.
+// The rules of the client take precedence over the rules of its groups,
+// which take precedence over the rules of all the clients,
+// and the first matched rule of them decides the access.
+// The destinations not matched by any rule are allowed.
+type localACL struct {
+	users      map[string][]*Rule
+	groups     map[string][]*Rule
+	all        []*Rule
+	cancelFunc context.CancelFunc
+	options    options
+	mu         sync.RWMutex
+}
+
+// NewACL creates and initializes a new ACL.
+func NewACL(opts ...Option) ACL {
+	var options options
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.logger == nil {
+		options.logger = logger.Default()
+	}
+
+	ctx, cancel := context.WithCancel(context.TODO())
+
+	p := &localACL{
+		cancelFunc: cancel,
+		options:    options,
+	}
+
+	if err := p.reload(ctx); err != nil {
+		options.logger.Warnf("reload: %v", err)
+	}
+	if p.options.period > 0 {
+		go p.periodReload(ctx)
+	}
+
+	return p
+}
+
+func (p *localACL) periodReload(ctx context.Context) error {
+	period := p.options.period
+	if period < time.Second {
+		period = time.Second
+	}
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := p.reload(ctx); err != nil {
+				p.options.logger.Warnf("reload: %v", err)
+				// return err
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (p *localACL) reload(ctx context.Context) error {
+	v, err := p.load(ctx)
+	if err != nil {
+		return err
+	}
+	lines := append(slices.Clip(p.options.rules), v...)
+	p.options.logger.Debugf("load items %d", len(lines))
+
+	users := make(map[string][]*Rule)
+	groups := make(map[string][]*Rule)
+	var all []*Rule
+	for _, line := range lines {
+		r, err := ParseRule(line)
+		if err != nil {
+			p.options.logger.Warn(err)
+			continue
+		}
+		switch {
+		case strings.HasPrefix(r.Subject, SubjectUser):
+			id := strings.TrimPrefix(r.Subject, SubjectUser)
+			users[id] = append(users[id], r)
+		case strings.HasPrefix(r.Subject, SubjectGroup):
+			group := strings.TrimPrefix(r.Subject, SubjectGroup)
+			groups[group] = append(groups[group], r)
+		default:
+			all = append(all, r)
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.users = users
+	p.groups = groups
+	p.all = all
+
+	return nil
+}
+
+func (p *localACL) load(ctx context.Context) (lines []string, err error) {
+	if p.options.fileLoader != nil {
+		r, er := p.options.fileLoader.Load(ctx)
+		if er != nil {
+			p.options.logger.Warnf("file loader: %v", er)
+		}
+		if v, _ := p.parseLines(r); v != nil {
+			lines = append(lines, v...)
+		}
+	}
+	if p.options.redisLoader != nil {
+		if lister, ok := p.options.redisLoader.(loader.Lister); ok {
+			list, er := lister.List(ctx)
+			if er != nil {
+				p.options.logger.Warnf("redis loader: %v", er)
+			}
+			for _, s := range list {
+				if line := p.parseLine(s); line != "" {
+					lines = append(lines, line)
+				}
+			}
+		} else {
+			r, er := p.options.redisLoader.Load(ctx)
+			if er != nil {
+				p.options.logger.Warnf("redis loader: %v", er)
+			}
+			if v, _ := p.parseLines(r); v != nil {
+				lines = append(lines, v...)
+			}
+		}
+	}
+	if p.options.httpLoader != nil {
+		r, er := p.options.httpLoader.Load(ctx)
+		if er != nil {
+			p.options.logger.Warnf("http loader: %v", er)
+		}
+		if v, _ := p.parseLines(r); v != nil {
+			lines = append(lines, v...)
+		}
+	}
+
+	return
+}
+
+func (p *localACL) parseLines(r io.Reader) (lines []string, err error) {
+	if r == nil {
+		return
+	}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if line := p.parseLine(scanner.Text()); line != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	err = scanner.Err()
+	return
+}
+
+func (p *localACL) parseLine(s string) string {
+	if n := strings.IndexByte(s, '#'); n >= 0 {
+		s = s[:n]
+	}
+	return strings.TrimSpace(s)
+}
+
+func (p *localACL) Allowed(ctx context.Context, network, addr string, opts ...AllowOption) bool {
+	if p == nil || addr == "" {
+		return true
+	}
+
+	var options AllowOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	d := dest{
+		network: strings.TrimRight(network, "46"),
+	}
+	host, sp, _ := net.SplitHostPort(addr)
+	if host == "" {
+		host = addr
+	}
+	d.port, _ = strconv.Atoi(sp)
+	if net.ParseIP(host) != nil {
+		d.ip = host
+	} else {
+		d.domain = host
+	}
+
+	client := string(ctxvalue.ClientIDFromContext(ctx))
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	var rules [][]*Rule
+	if client != "" {
+		rules = append(rules, p.users[client])
+		for _, group := range xauth.GroupsFromContext(ctx) {
+			rules = append(rules, p.groups[group])
+		}
+	}
+	rules = append(rules, p.all)
+
+	// the domain is checked with each of the addresses it resolves to.
+	dests := []dest{d}
+	if d.domain != "" && len(options.IPs) > 0 {
+		dests = dests[:0]
+		for _, ip := range options.IPs {
+			d.ip = ip.String()
+			dests = append(dests, d)
+		}
+	}
+
+	for i := range dests {
+		if !allowed(rules, &dests[i]) {
+			p.options.logger.Debugf("acl: client %s denied: %s/%s", client, addr, network)
+			return false
+		}
+	}
+	return true
+}
+
+// allowed reports whether the destination d is allowed by the first matched rule of the rules.
+func allowed(rules [][]*Rule, d *dest) bool {
+	for _, list := range rules {
+		for _, r := range list {
+			if r.match(d) {
+				return r.Allow
+			}
+		}
+	}
+	return true
+}
+
+func (p *localACL) Close() error {
+	p.cancelFunc()
+	if p.options.fileLoader != nil {
+		p.options.fileLoader.Close()
+	}
+	if p.options.redisLoader != nil {
+		p.options.redisLoader.Close()
+	}
+	return nil
+}
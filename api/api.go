@@ -12,4 +12,6 @@ var (
 type Response struct {
 	Code int    `json:"code,omitempty"`
 	Msg  string `json:"msg,omitempty"`
+	// the ID of the config revision recorded by the change.
+	Revision int `json:"revision,omitempty"`
 }
@@ -0,0 +1,96 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-gost/core/hop"
+	"github.com/go-gost/core/selector"
+	"github.com/go-gost/x/registry"
+	xs "github.com/go-gost/x/selector"
+)
+
+// ChainStatus is the health state of a chain.
+type ChainStatus struct {
+	Name string `json:"name"`
+	// the number of consecutive failures.
+	FailCount int64 `json:"failCount"`
+	// the time of the latest failure.
+	FailTime *time.Time `json:"failTime,omitempty"`
+	// the number of the active connections through the chain.
+	Conns int64 `json:"conns"`
+	// the state of the circuit breaker: closed, open or half-open.
+	Breaker string      `json:"breaker"`
+	Hops    []HopStatus `json:"hops"`
+}
+
+// HopStatus is the health state of a hop.
+type HopStatus struct {
+	Name  string       `json:"name"`
+	Nodes []NodeStatus `json:"nodes"`
+}
+
+// swagger:parameters getChainStatusRequest
+type getChainStatusRequest struct {
+	// in: path
+	// required: true
+	Chain string `uri:"chain" json:"chain"`
+}
+
+// successful operation.
+// swagger:response getChainStatusResponse
+type getChainStatusResponse struct {
+	// in: body
+	Data ChainStatus
+}
+
+func getChainStatus(ctx *gin.Context) {
+	// swagger:route GET /chains/{chain}/status Chain getChainStatusRequest
+	//
+	// Get the health state of the chain and the nodes of its hops.
+	//
+	//     Security:
+	//       basicAuth: []
+	//
+	//     Responses:
+	//       200: getChainStatusResponse
+
+	var req getChainStatusRequest
+	ctx.ShouldBindUri(&req)
+
+	c := registry.ChainRegistry().GetAll()[req.Chain]
+	if c == nil {
+		writeError(ctx, ErrNotFound)
+		return
+	}
+
+	var resp getChainStatusResponse
+	resp.Data.Name = req.Chain
+	resp.Data.Hops = []HopStatus{}
+	resp.Data.Conns = xs.StateOf(c).Conns()
+	resp.Data.Breaker = xs.StateOf(c).BreakerState().String()
+	if m, ok := c.(selector.Markable); ok {
+		if marker := m.Marker(); marker != nil {
+			resp.Data.FailCount = marker.Count()
+			if t := marker.Time(); t.Unix() > 0 {
+				resp.Data.FailTime = &t
+			}
+		}
+	}
+
+	if v, ok := c.(interface{ Hops() []hop.Hop }); ok {
+		for _, h := range v.Hops() {
+			var name string
+			if n, ok := h.(interface{ Name() string }); ok {
+				name = n.Name()
+			}
+			resp.Data.Hops = append(resp.Data.Hops, HopStatus{
+				Name:  name,
+				Nodes: nodeStatusList(h),
+			})
+		}
+	}
+
+	ctx.JSON(http.StatusOK, resp.Data)
+}
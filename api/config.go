@@ -8,6 +8,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-gost/x/config"
+	"github.com/go-gost/x/config/loader"
 )
 
 // swagger:parameters getConfigRequest
@@ -26,7 +27,8 @@ type getConfigResponse struct {
 func getConfig(ctx *gin.Context) {
 	// swagger:route GET /config Config getConfigRequest
 	//
-	// Get current config.
+	// Get current config, the plaintext passwords and tokens are redacted,
+	// the secret references (env:NAME or file:PATH) are kept as is.
 	//
 	//     Security:
 	//       basicAuth: []
@@ -38,7 +40,7 @@ func getConfig(ctx *gin.Context) {
 	ctx.ShouldBindQuery(&req)
 
 	var resp getConfigResponse
-	resp.Config = config.Global()
+	resp.Config = config.Global().Redacted()
 
 	buf := &bytes.Buffer{}
 	switch req.Format {
@@ -123,3 +125,250 @@ func saveConfig(ctx *gin.Context) {
 		Msg: "OK",
 	})
 }
+
+// swagger:parameters replaceConfigRequest
+type replaceConfigRequest struct {
+	// in: body
+	Data config.Config `json:"data"`
+}
+
+// successful operation.
+// swagger:response replaceConfigResponse
+type replaceConfigResponse struct {
+	Data Response
+}
+
+func replaceConfig(ctx *gin.Context) {
+	// swagger:route PUT /config Config replaceConfigRequest
+	//
+	// Replace the current config as a whole.
+	// Only the objects that changed are rebuilt, all the changes are rolled back if any object fails.
+	//
+	//     Security:
+	//       basicAuth: []
+	//
+	//     Responses:
+	//       200: replaceConfigResponse
+
+	var req replaceConfigRequest
+	if err := ctx.ShouldBindJSON(&req.Data); err != nil {
+		writeError(ctx, ErrInvalid)
+		return
+	}
+
+	rev, err := applyConfig(ctx, func(cfg *config.Config) {
+		*cfg = req.Data
+	})
+	if err != nil {
+		writeError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, Response{
+		Msg:      "OK",
+		Revision: rev,
+	})
+}
+
+// BatchConfig is a set of changes to the current config.
+type BatchConfig struct {
+	// objects to be created, the existing objects with the same names are replaced.
+	Put *config.Config `json:"put,omitempty"`
+	// names of the objects to be deleted.
+	Delete *BatchDeleteConfig `json:"delete,omitempty"`
+}
+
+type BatchDeleteConfig struct {
+	Services   []string `json:"services,omitempty"`
+	Chains     []string `json:"chains,omitempty"`
+	Hops       []string `json:"hops,omitempty"`
+	Authers    []string `json:"authers,omitempty"`
+	Admissions []string `json:"admissions,omitempty"`
+	Bypasses   []string `json:"bypasses,omitempty"`
+	Resolvers  []string `json:"resolvers,omitempty"`
+	Hosts      []string `json:"hosts,omitempty"`
+	Ingresses  []string `json:"ingresses,omitempty"`
+	Routers    []string `json:"routers,omitempty"`
+	Rules      []string `json:"rules,omitempty"`
+	ACLs       []string `json:"acls,omitempty"`
+	Quotas     []string `json:"quotas,omitempty"`
+	SDs        []string `json:"sds,omitempty"`
+	Recorders  []string `json:"recorders,omitempty"`
+	Limiters   []string `json:"limiters,omitempty"`
+	CLimiters  []string `json:"climiters,omitempty"`
+	RLimiters  []string `json:"rlimiters,omitempty"`
+	Loggers    []string `json:"loggers,omitempty"`
+}
+
+// swagger:parameters batchConfigRequest
+type batchConfigRequest struct {
+	// in: body
+	Data BatchConfig `json:"data"`
+}
+
+// successful operation.
+// swagger:response batchConfigResponse
+type batchConfigResponse struct {
+	Data Response
+}
+
+func batchConfig(ctx *gin.Context) {
+	// swagger:route POST /config/batch Config batchConfigRequest
+	//
+	// Apply a set of changes to the current config,
+	// the changes are all or nothing, they are rolled back if any object fails.
+	//
+	//     Security:
+	//       basicAuth: []
+	//
+	//     Responses:
+	//       200: batchConfigResponse
+
+	var req batchConfigRequest
+	if err := ctx.ShouldBindJSON(&req.Data); err != nil {
+		writeError(ctx, ErrInvalid)
+		return
+	}
+
+	rev, err := applyConfig(ctx, func(cfg *config.Config) {
+		if d := req.Data.Delete; d != nil {
+			cfg.Services = remove(cfg.Services, d.Services, func(c *config.ServiceConfig) string { return c.Name })
+			cfg.Chains = remove(cfg.Chains, d.Chains, func(c *config.ChainConfig) string { return c.Name })
+			cfg.Hops = remove(cfg.Hops, d.Hops, func(c *config.HopConfig) string { return c.Name })
+			cfg.Authers = remove(cfg.Authers, d.Authers, func(c *config.AutherConfig) string { return c.Name })
+			cfg.Admissions = remove(cfg.Admissions, d.Admissions, func(c *config.AdmissionConfig) string { return c.Name })
+			cfg.Bypasses = remove(cfg.Bypasses, d.Bypasses, func(c *config.BypassConfig) string { return c.Name })
+			cfg.Resolvers = remove(cfg.Resolvers, d.Resolvers, func(c *config.ResolverConfig) string { return c.Name })
+			cfg.Hosts = remove(cfg.Hosts, d.Hosts, func(c *config.HostsConfig) string { return c.Name })
+			cfg.Ingresses = remove(cfg.Ingresses, d.Ingresses, func(c *config.IngressConfig) string { return c.Name })
+			cfg.Routers = remove(cfg.Routers, d.Routers, func(c *config.RouterConfig) string { return c.Name })
+			cfg.Rules = remove(cfg.Rules, d.Rules, func(c *config.RulesConfig) string { return c.Name })
+			cfg.ACLs = remove(cfg.ACLs, d.ACLs, func(c *config.ACLConfig) string { return c.Name })
+			cfg.Quotas = remove(cfg.Quotas, d.Quotas, func(c *config.QuotaConfig) string { return c.Name })
+			cfg.SDs = remove(cfg.SDs, d.SDs, func(c *config.SDConfig) string { return c.Name })
+			cfg.Recorders = remove(cfg.Recorders, d.Recorders, func(c *config.RecorderConfig) string { return c.Name })
+			cfg.Limiters = remove(cfg.Limiters, d.Limiters, func(c *config.LimiterConfig) string { return c.Name })
+			cfg.CLimiters = remove(cfg.CLimiters, d.CLimiters, func(c *config.LimiterConfig) string { return c.Name })
+			cfg.RLimiters = remove(cfg.RLimiters, d.RLimiters, func(c *config.LimiterConfig) string { return c.Name })
+			cfg.Loggers = remove(cfg.Loggers, d.Loggers, func(c *config.LoggerConfig) string { return c.Name })
+		}
+		if p := req.Data.Put; p != nil {
+			cfg.Services = put(cfg.Services, p.Services, func(c *config.ServiceConfig) string { return c.Name })
+			cfg.Chains = put(cfg.Chains, p.Chains, func(c *config.ChainConfig) string { return c.Name })
+			cfg.Hops = put(cfg.Hops, p.Hops, func(c *config.HopConfig) string { return c.Name })
+			cfg.Authers = put(cfg.Authers, p.Authers, func(c *config.AutherConfig) string { return c.Name })
+			cfg.Admissions = put(cfg.Admissions, p.Admissions, func(c *config.AdmissionConfig) string { return c.Name })
+			cfg.Bypasses = put(cfg.Bypasses, p.Bypasses, func(c *config.BypassConfig) string { return c.Name })
+			cfg.Resolvers = put(cfg.Resolvers, p.Resolvers, func(c *config.ResolverConfig) string { return c.Name })
+			cfg.Hosts = put(cfg.Hosts, p.Hosts, func(c *config.HostsConfig) string { return c.Name })
+			cfg.Ingresses = put(cfg.Ingresses, p.Ingresses, func(c *config.IngressConfig) string { return c.Name })
+			cfg.Routers = put(cfg.Routers, p.Routers, func(c *config.RouterConfig) string { return c.Name })
+			cfg.Rules = put(cfg.Rules, p.Rules, func(c *config.RulesConfig) string { return c.Name })
+			cfg.ACLs = put(cfg.ACLs, p.ACLs, func(c *config.ACLConfig) string { return c.Name })
+			cfg.Quotas = put(cfg.Quotas, p.Quotas, func(c *config.QuotaConfig) string { return c.Name })
+			cfg.SDs = put(cfg.SDs, p.SDs, func(c *config.SDConfig) string { return c.Name })
+			cfg.Recorders = put(cfg.Recorders, p.Recorders, func(c *config.RecorderConfig) string { return c.Name })
+			cfg.Limiters = put(cfg.Limiters, p.Limiters, func(c *config.LimiterConfig) string { return c.Name })
+			cfg.CLimiters = put(cfg.CLimiters, p.CLimiters, func(c *config.LimiterConfig) string { return c.Name })
+			cfg.RLimiters = put(cfg.RLimiters, p.RLimiters, func(c *config.LimiterConfig) string { return c.Name })
+			cfg.Loggers = put(cfg.Loggers, p.Loggers, func(c *config.LoggerConfig) string { return c.Name })
+			if p.TLS != nil {
+				cfg.TLS = p.TLS
+			}
+			if p.Log != nil {
+				cfg.Log = p.Log
+			}
+		}
+	})
+	if err != nil {
+		writeError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, Response{
+		Msg:      "OK",
+		Revision: rev,
+	})
+}
+
+// applyConfig makes the changes to a copy of the current config by build,
+// then validates the changes and applies the config atomically.
+// The config is built and applied under the lock of the loader, so the concurrent changes are not lost.
+// Only the changed objects and the objects they reference are validated,
+// so the existing problems of the other objects do not fail the request.
+// The redacted secrets returned by getConfig are replaced with the current secrets.
+// The revision of the change is recorded once it is applied, and its ID is returned.
+func applyConfig(ctx *gin.Context, build func(cfg *config.Config)) (rev int, err error) {
+	var invalid error
+	err = loader.Update(func(cfg *config.Config) error {
+		prev := config.Global()
+		build(cfg)
+		if err := cfg.Unredact(prev); err != nil {
+			invalid = err
+			return err
+		}
+		if err := loader.CheckChanges(prev, cfg); err != nil {
+			invalid = err
+			return err
+		}
+		return nil
+	}, func(cfg *config.Config) {
+		rev = recordRevision(ctx)
+	})
+	if invalid != nil {
+		return 0, &Error{
+			statusCode: http.StatusBadRequest,
+			Code:       ErrInvalid.Code,
+			Msg:        fmt.Sprintf("%s: %s", ErrInvalid.Msg, invalid.Error()),
+		}
+	}
+	if err != nil {
+		return 0, &Error{
+			statusCode: http.StatusConflict,
+			Code:       ErrCreate.Code,
+			Msg:        fmt.Sprintf("%s: %s", ErrCreate.Msg, err.Error()),
+		}
+	}
+	return rev, nil
+}
+
+// put adds the objects items to the list, replacing the ones with the same names.
+func put[T any](list []*T, items []*T, name func(*T) string) []*T {
+	list = append([]*T(nil), list...)
+	for _, item := range items {
+		if item == nil {
+			continue
+		}
+		replaced := false
+		for i := range list {
+			if list[i] != nil && name(list[i]) == name(item) {
+				list[i] = item
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// remove removes the objects with the names from the list.
+func remove[T any](list []*T, names []string, name func(*T) string) []*T {
+	if len(names) == 0 {
+		return list
+	}
+	m := make(map[string]bool)
+	for _, n := range names {
+		m[n] = true
+	}
+	var result []*T
+	for _, v := range list {
+		if v != nil && m[name(v)] {
+			continue
+		}
+		result = append(result, v)
+	}
+	return result
+}
@@ -0,0 +1,180 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-gost/x/config"
+	parser "github.com/go-gost/x/config/parsing/acl"
+	"github.com/go-gost/x/registry"
+)
+
+// swagger:parameters createACLRequest
+type createACLRequest struct {
+	// in: body
+	Data config.ACLConfig `json:"data"`
+}
+
+// successful operation.
+// swagger:response createACLResponse
+type createACLResponse struct {
+	Data Response
+}
+
+func createACL(ctx *gin.Context) {
+	// swagger:route POST /config/acls ACL createACLRequest
+	//
+	// Create a new ACL, the name of the ACL must be unique in ACLs.
+	//
+	//     Security:
+	//       basicAuth: []
+	//
+	//     Responses:
+	//       200: createACLResponse
+
+	var req createACLRequest
+	ctx.ShouldBindJSON(&req.Data)
+
+	if req.Data.Name == "" {
+		writeError(ctx, ErrInvalid)
+		return
+	}
+
+	v, err := parser.ParseACL(&req.Data)
+	if err != nil {
+		writeError(ctx, ErrCreate)
+		return
+	}
+
+	if err := registry.ACLRegistry().Register(req.Data.Name, v); err != nil {
+		writeError(ctx, ErrDup)
+		return
+	}
+
+	config.OnUpdate(func(c *config.Config) error {
+		c.ACLs = append(c.ACLs, &req.Data)
+		return nil
+	})
+
+	ctx.JSON(http.StatusOK, Response{
+		Msg:      "OK",
+		Revision: recordRevision(ctx),
+	})
+}
+
+// swagger:parameters updateACLRequest
+type updateACLRequest struct {
+	// in: path
+	// required: true
+	ACL string `uri:"acl" json:"acl"`
+	// in: body
+	Data config.ACLConfig `json:"data"`
+}
+
+// successful operation.
+// swagger:response updateACLResponse
+type updateACLResponse struct {
+	Data Response
+}
+
+func updateACL(ctx *gin.Context) {
+	// swagger:route PUT /config/acls/{acl} ACL updateACLRequest
+	//
+	// Update ACL by name, the ACL must already exist.
+	//
+	//     Security:
+	//       basicAuth: []
+	//
+	//     Responses:
+	//       200: updateACLResponse
+
+	var req updateACLRequest
+	ctx.ShouldBindUri(&req)
+	ctx.ShouldBindJSON(&req.Data)
+
+	if !registry.ACLRegistry().IsRegistered(req.ACL) {
+		writeError(ctx, ErrNotFound)
+		return
+	}
+
+	req.Data.Name = req.ACL
+
+	v, err := parser.ParseACL(&req.Data)
+	if err != nil {
+		writeError(ctx, ErrCreate)
+		return
+	}
+
+	registry.ACLRegistry().Unregister(req.ACL)
+
+	if err := registry.ACLRegistry().Register(req.ACL, v); err != nil {
+		writeError(ctx, ErrDup)
+		return
+	}
+
+	config.OnUpdate(func(c *config.Config) error {
+		for i := range c.ACLs {
+			if c.ACLs[i].Name == req.ACL {
+				c.ACLs[i] = &req.Data
+				break
+			}
+		}
+		return nil
+	})
+
+	ctx.JSON(http.StatusOK, Response{
+		Msg:      "OK",
+		Revision: recordRevision(ctx),
+	})
+}
+
+// swagger:parameters deleteACLRequest
+type deleteACLRequest struct {
+	// in: path
+	// required: true
+	ACL string `uri:"acl" json:"acl"`
+}
+
+// successful operation.
+// swagger:response deleteACLResponse
+type deleteACLResponse struct {
+	Data Response
+}
+
+func deleteACL(ctx *gin.Context) {
+	// swagger:route DELETE /config/acls/{acl} ACL deleteACLRequest
+	//
+	// Delete ACL by name.
+	//
+	//     Security:
+	//       basicAuth: []
+	//
+	//     Responses:
+	//       200: deleteACLResponse
+
+	var req deleteACLRequest
+	ctx.ShouldBindUri(&req)
+
+	if !registry.ACLRegistry().IsRegistered(req.ACL) {
+		writeError(ctx, ErrNotFound)
+		return
+	}
+	registry.ACLRegistry().Unregister(req.ACL)
+
+	config.OnUpdate(func(c *config.Config) error {
+		acls := c.ACLs
+		c.ACLs = nil
+		for _, s := range acls {
+			if s.Name == req.ACL {
+				continue
+			}
+			c.ACLs = append(c.ACLs, s)
+		}
+		return nil
+	})
+
+	ctx.JSON(http.StatusOK, Response{
+		Msg:      "OK",
+		Revision: recordRevision(ctx),
+	})
+}
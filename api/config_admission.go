@@ -53,7 +53,8 @@ func createAdmission(ctx *gin.Context) {
 	})
 
 	ctx.JSON(http.StatusOK, Response{
-		Msg: "OK",
+		Msg:      "OK",
+		Revision: recordRevision(ctx),
 	})
 }
 
@@ -114,7 +115,8 @@ func updateAdmission(ctx *gin.Context) {
 	})
 
 	ctx.JSON(http.StatusOK, Response{
-		Msg: "OK",
+		Msg:      "OK",
+		Revision: recordRevision(ctx),
 	})
 }
 
@@ -164,6 +166,7 @@ func deleteAdmission(ctx *gin.Context) {
 	})
 
 	ctx.JSON(http.StatusOK, Response{
-		Msg: "OK",
+		Msg:      "OK",
+		Revision: recordRevision(ctx),
 	})
 }
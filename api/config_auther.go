@@ -52,7 +52,8 @@ func createAuther(ctx *gin.Context) {
 	})
 
 	ctx.JSON(http.StatusOK, Response{
-		Msg: "OK",
+		Msg:      "OK",
+		Revision: recordRevision(ctx),
 	})
 }
 
@@ -112,7 +113,8 @@ func updateAuther(ctx *gin.Context) {
 	})
 
 	ctx.JSON(http.StatusOK, Response{
-		Msg: "OK",
+		Msg:      "OK",
+		Revision: recordRevision(ctx),
 	})
 }
 
@@ -162,6 +164,7 @@ func deleteAuther(ctx *gin.Context) {
 	})
 
 	ctx.JSON(http.StatusOK, Response{
-		Msg: "OK",
+		Msg:      "OK",
+		Revision: recordRevision(ctx),
 	})
 }
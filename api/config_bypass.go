@@ -53,7 +53,8 @@ func createBypass(ctx *gin.Context) {
 	})
 
 	ctx.JSON(http.StatusOK, Response{
-		Msg: "OK",
+		Msg:      "OK",
+		Revision: recordRevision(ctx),
 	})
 }
 
@@ -114,7 +115,8 @@ func updateBypass(ctx *gin.Context) {
 	})
 
 	ctx.JSON(http.StatusOK, Response{
-		Msg: "OK",
+		Msg:      "OK",
+		Revision: recordRevision(ctx),
 	})
 }
 
@@ -164,6 +166,7 @@ func deleteBypass(ctx *gin.Context) {
 	})
 
 	ctx.JSON(http.StatusOK, Response{
-		Msg: "OK",
+		Msg:      "OK",
+		Revision: recordRevision(ctx),
 	})
 }
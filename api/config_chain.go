@@ -57,7 +57,8 @@ func createChain(ctx *gin.Context) {
 	})
 
 	ctx.JSON(http.StatusOK, Response{
-		Msg: "OK",
+		Msg:      "OK",
+		Revision: recordRevision(ctx),
 	})
 }
 
@@ -123,7 +124,8 @@ func updateChain(ctx *gin.Context) {
 	})
 
 	ctx.JSON(http.StatusOK, Response{
-		Msg: "OK",
+		Msg:      "OK",
+		Revision: recordRevision(ctx),
 	})
 }
 
@@ -173,6 +175,7 @@ func deleteChain(ctx *gin.Context) {
 	})
 
 	ctx.JSON(http.StatusOK, Response{
-		Msg: "OK",
+		Msg:      "OK",
+		Revision: recordRevision(ctx),
 	})
 }
@@ -53,7 +53,8 @@ func createConnLimiter(ctx *gin.Context) {
 	})
 
 	ctx.JSON(http.StatusOK, Response{
-		Msg: "OK",
+		Msg:      "OK",
+		Revision: recordRevision(ctx),
 	})
 }
 
@@ -114,7 +115,8 @@ func updateConnLimiter(ctx *gin.Context) {
 	})
 
 	ctx.JSON(http.StatusOK, Response{
-		Msg: "OK",
+		Msg:      "OK",
+		Revision: recordRevision(ctx),
 	})
 }
 
@@ -164,6 +166,7 @@ func deleteConnLimiter(ctx *gin.Context) {
 	})
 
 	ctx.JSON(http.StatusOK, Response{
-		Msg: "OK",
+		Msg:      "OK",
+		Revision: recordRevision(ctx),
 	})
 }
@@ -0,0 +1,53 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-gost/x/config"
+)
+
+// swagger:parameters updateLogRequest
+type updateLogRequest struct {
+	// in: body
+	Data config.LogConfig `json:"data"`
+}
+
+// successful operation.
+// swagger:response updateLogResponse
+type updateLogResponse struct {
+	Data Response
+}
+
+func updateLog(ctx *gin.Context) {
+	// swagger:route PUT /config/log Log updateLogRequest
+	//
+	// Update the global log config of the default logger.
+	// If only the level is changed, it takes effect immediately in all the running services,
+	// otherwise only the objects created later use the new logger.
+	//
+	//     Security:
+	//       basicAuth: []
+	//
+	//     Responses:
+	//       200: updateLogResponse
+
+	var req updateLogRequest
+	if err := ctx.ShouldBindJSON(&req.Data); err != nil {
+		writeError(ctx, ErrInvalid)
+		return
+	}
+
+	rev, err := applyConfig(ctx, func(cfg *config.Config) {
+		cfg.Log = &req.Data
+	})
+	if err != nil {
+		writeError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, Response{
+		Msg:      "OK",
+		Revision: rev,
+	})
+}
@@ -0,0 +1,165 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-gost/x/config"
+	"github.com/go-gost/x/registry"
+)
+
+// The loggers are bound to the services when parsing,
+// so the changes of loggers are applied through the loader
+// to rebuild the services using them.
+
+// swagger:parameters createLoggerRequest
+type createLoggerRequest struct {
+	// in: body
+	Data config.LoggerConfig `json:"data"`
+}
+
+// successful operation.
+// swagger:response createLoggerResponse
+type createLoggerResponse struct {
+	Data Response
+}
+
+func createLogger(ctx *gin.Context) {
+	// swagger:route POST /config/loggers Logger createLoggerRequest
+	//
+	// Create a new logger, the name of the logger must be unique in logger list.
+	//
+	//     Security:
+	//       basicAuth: []
+	//
+	//     Responses:
+	//       200: createLoggerResponse
+
+	var req createLoggerRequest
+	ctx.ShouldBindJSON(&req.Data)
+
+	if req.Data.Name == "" || req.Data.Log == nil {
+		writeError(ctx, ErrInvalid)
+		return
+	}
+
+	if registry.LoggerRegistry().IsRegistered(req.Data.Name) {
+		writeError(ctx, ErrDup)
+		return
+	}
+
+	rev, err := applyConfig(ctx, func(cfg *config.Config) {
+		cfg.Loggers = put(cfg.Loggers, []*config.LoggerConfig{&req.Data}, func(c *config.LoggerConfig) string { return c.Name })
+	})
+	if err != nil {
+		writeError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, Response{
+		Msg:      "OK",
+		Revision: rev,
+	})
+}
+
+// swagger:parameters updateLoggerRequest
+type updateLoggerRequest struct {
+	// in: path
+	// required: true
+	Logger string `uri:"logger" json:"logger"`
+	// in: body
+	Data config.LoggerConfig `json:"data"`
+}
+
+// successful operation.
+// swagger:response updateLoggerResponse
+type updateLoggerResponse struct {
+	Data Response
+}
+
+func updateLogger(ctx *gin.Context) {
+	// swagger:route PUT /config/loggers/{logger} Logger updateLoggerRequest
+	//
+	// Update logger by name, the logger must already exist.
+	// The services using the logger are rebuilt.
+	//
+	//     Security:
+	//       basicAuth: []
+	//
+	//     Responses:
+	//       200: updateLoggerResponse
+
+	var req updateLoggerRequest
+	ctx.ShouldBindUri(&req)
+	ctx.ShouldBindJSON(&req.Data)
+
+	if !registry.LoggerRegistry().IsRegistered(req.Logger) {
+		writeError(ctx, ErrNotFound)
+		return
+	}
+	if req.Data.Log == nil {
+		writeError(ctx, ErrInvalid)
+		return
+	}
+
+	req.Data.Name = req.Logger
+
+	rev, err := applyConfig(ctx, func(cfg *config.Config) {
+		cfg.Loggers = put(cfg.Loggers, []*config.LoggerConfig{&req.Data}, func(c *config.LoggerConfig) string { return c.Name })
+	})
+	if err != nil {
+		writeError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, Response{
+		Msg:      "OK",
+		Revision: rev,
+	})
+}
+
+// swagger:parameters deleteLoggerRequest
+type deleteLoggerRequest struct {
+	// in: path
+	// required: true
+	Logger string `uri:"logger" json:"logger"`
+}
+
+// successful operation.
+// swagger:response deleteLoggerResponse
+type deleteLoggerResponse struct {
+	Data Response
+}
+
+func deleteLogger(ctx *gin.Context) {
+	// swagger:route DELETE /config/loggers/{logger} Logger deleteLoggerRequest
+	//
+	// Delete logger by name, the logger must not be used by any service.
+	//
+	//     Security:
+	//       basicAuth: []
+	//
+	//     Responses:
+	//       200: deleteLoggerResponse
+
+	var req deleteLoggerRequest
+	ctx.ShouldBindUri(&req)
+
+	if !registry.LoggerRegistry().IsRegistered(req.Logger) {
+		writeError(ctx, ErrNotFound)
+		return
+	}
+
+	rev, err := applyConfig(ctx, func(cfg *config.Config) {
+		cfg.Loggers = remove(cfg.Loggers, []string{req.Logger}, func(c *config.LoggerConfig) string { return c.Name })
+	})
+	if err != nil {
+		writeError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, Response{
+		Msg:      "OK",
+		Revision: rev,
+	})
+}
@@ -0,0 +1,174 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-gost/x/config"
+	"github.com/go-gost/x/config/revision"
+)
+
+// Revision is the summary of a config revision.
+type Revision struct {
+	ID   int       `json:"id"`
+	Time time.Time `json:"time"`
+	User string    `json:"user,omitempty"`
+	Op   string    `json:"op,omitempty"`
+}
+
+// swagger:parameters getRevisionsRequest
+type getRevisionsRequest struct {
+}
+
+// successful operation.
+// swagger:response getRevisionsResponse
+type getRevisionsResponse struct {
+	// in: body
+	Data revisionList
+}
+
+type revisionList struct {
+	Count int        `json:"count"`
+	List  []Revision `json:"list"`
+}
+
+func getRevisions(ctx *gin.Context) {
+	// swagger:route GET /config/revisions Revision getRevisionsRequest
+	//
+	// Get the config revisions, from the oldest to the latest.
+	//
+	//     Security:
+	//       basicAuth: []
+	//
+	//     Responses:
+	//       200: getRevisionsResponse
+
+	var resp getRevisionsResponse
+	for _, rev := range revision.List() {
+		resp.Data.List = append(resp.Data.List, Revision{
+			ID:   rev.ID,
+			Time: rev.Time,
+			User: rev.User,
+			Op:   rev.Op,
+		})
+	}
+	resp.Data.Count = len(resp.Data.List)
+
+	ctx.JSON(http.StatusOK, resp.Data)
+}
+
+// swagger:parameters getRevisionDiffRequest
+type getRevisionDiffRequest struct {
+	// in: path
+	// required: true
+	ID int `uri:"id" json:"id"`
+	// the revision to compare with, default is the previous revision.
+	// in: query
+	Base int `form:"base" json:"base"`
+}
+
+// successful operation.
+// swagger:response getRevisionDiffResponse
+type getRevisionDiffResponse struct {
+	// unified diff in YAML format.
+	Diff string
+}
+
+func getRevisionDiff(ctx *gin.Context) {
+	// swagger:route GET /config/revisions/{id}/diff Revision getRevisionDiffRequest
+	//
+	// Get the changes made by the revision.
+	//
+	//     Security:
+	//       basicAuth: []
+	//
+	//     Responses:
+	//       200: getRevisionDiffResponse
+
+	var req getRevisionDiffRequest
+	ctx.ShouldBindUri(&req)
+	ctx.ShouldBindQuery(&req)
+
+	rev := revision.Get(req.ID)
+	if rev == nil {
+		writeError(ctx, ErrNotFound)
+		return
+	}
+
+	base := revision.Previous(rev.ID)
+	if req.Base > 0 {
+		if base = revision.Get(req.Base); base == nil {
+			writeError(ctx, ErrNotFound)
+			return
+		}
+	}
+
+	var resp getRevisionDiffResponse
+	diff, err := revision.Diff(base, rev)
+	if err != nil {
+		writeError(ctx, &Error{
+			statusCode: http.StatusInternalServerError,
+			Code:       ErrInvalid.Code,
+			Msg:        fmt.Sprintf("diff: %s", err.Error()),
+		})
+		return
+	}
+	resp.Diff = diff
+
+	ctx.Data(http.StatusOK, "text/plain; charset=utf-8", []byte(resp.Diff))
+}
+
+// swagger:parameters rollbackRevisionRequest
+type rollbackRevisionRequest struct {
+	// in: path
+	// required: true
+	ID int `uri:"id" json:"id"`
+}
+
+// successful operation.
+// swagger:response rollbackRevisionResponse
+type rollbackRevisionResponse struct {
+	Data Response
+}
+
+func rollbackRevision(ctx *gin.Context) {
+	// swagger:route POST /config/revisions/{id}/rollback Revision rollbackRevisionRequest
+	//
+	// Roll back the config to the revision, the rollback itself is recorded as a new revision.
+	//
+	//     Security:
+	//       basicAuth: []
+	//
+	//     Responses:
+	//       200: rollbackRevisionResponse
+
+	var req rollbackRevisionRequest
+	ctx.ShouldBindUri(&req)
+
+	rev := revision.Get(req.ID)
+	if rev == nil {
+		writeError(ctx, ErrNotFound)
+		return
+	}
+
+	cfg, err := rev.Config()
+	if err != nil {
+		writeError(ctx, ErrInvalid)
+		return
+	}
+
+	id, err := applyConfig(ctx, func(c *config.Config) {
+		*c = *cfg
+	})
+	if err != nil {
+		writeError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, Response{
+		Msg:      "OK",
+		Revision: id,
+	})
+}
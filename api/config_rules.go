@@ -0,0 +1,180 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-gost/x/config"
+	parser "github.com/go-gost/x/config/parsing/rule"
+	"github.com/go-gost/x/registry"
+)
+
+// swagger:parameters createRulesRequest
+type createRulesRequest struct {
+	// in: body
+	Data config.RulesConfig `json:"data"`
+}
+
+// successful operation.
+// swagger:response createRulesResponse
+type createRulesResponse struct {
+	Data Response
+}
+
+func createRules(ctx *gin.Context) {
+	// swagger:route POST /config/rules Rules createRulesRequest
+	//
+	// Create a new rule list, the name of the rule list must be unique in rule lists.
+	//
+	//     Security:
+	//       basicAuth: []
+	//
+	//     Responses:
+	//       200: createRulesResponse
+
+	var req createRulesRequest
+	ctx.ShouldBindJSON(&req.Data)
+
+	if req.Data.Name == "" {
+		writeError(ctx, ErrInvalid)
+		return
+	}
+
+	v, err := parser.ParseRules(&req.Data)
+	if err != nil {
+		writeError(ctx, ErrCreate)
+		return
+	}
+
+	if err := registry.RulesRegistry().Register(req.Data.Name, v); err != nil {
+		writeError(ctx, ErrDup)
+		return
+	}
+
+	config.OnUpdate(func(c *config.Config) error {
+		c.Rules = append(c.Rules, &req.Data)
+		return nil
+	})
+
+	ctx.JSON(http.StatusOK, Response{
+		Msg:      "OK",
+		Revision: recordRevision(ctx),
+	})
+}
+
+// swagger:parameters updateRulesRequest
+type updateRulesRequest struct {
+	// in: path
+	// required: true
+	Rules string `uri:"rules" json:"rules"`
+	// in: body
+	Data config.RulesConfig `json:"data"`
+}
+
+// successful operation.
+// swagger:response updateRulesResponse
+type updateRulesResponse struct {
+	Data Response
+}
+
+func updateRules(ctx *gin.Context) {
+	// swagger:route PUT /config/rules/{rules} Rules updateRulesRequest
+	//
+	// Update rule list by name, the rule list must already exist.
+	//
+	//     Security:
+	//       basicAuth: []
+	//
+	//     Responses:
+	//       200: updateRulesResponse
+
+	var req updateRulesRequest
+	ctx.ShouldBindUri(&req)
+	ctx.ShouldBindJSON(&req.Data)
+
+	if !registry.RulesRegistry().IsRegistered(req.Rules) {
+		writeError(ctx, ErrNotFound)
+		return
+	}
+
+	req.Data.Name = req.Rules
+
+	v, err := parser.ParseRules(&req.Data)
+	if err != nil {
+		writeError(ctx, ErrCreate)
+		return
+	}
+
+	registry.RulesRegistry().Unregister(req.Rules)
+
+	if err := registry.RulesRegistry().Register(req.Rules, v); err != nil {
+		writeError(ctx, ErrDup)
+		return
+	}
+
+	config.OnUpdate(func(c *config.Config) error {
+		for i := range c.Rules {
+			if c.Rules[i].Name == req.Rules {
+				c.Rules[i] = &req.Data
+				break
+			}
+		}
+		return nil
+	})
+
+	ctx.JSON(http.StatusOK, Response{
+		Msg:      "OK",
+		Revision: recordRevision(ctx),
+	})
+}
+
+// swagger:parameters deleteRulesRequest
+type deleteRulesRequest struct {
+	// in: path
+	// required: true
+	Rules string `uri:"rules" json:"rules"`
+}
+
+// successful operation.
+// swagger:response deleteRulesResponse
+type deleteRulesResponse struct {
+	Data Response
+}
+
+func deleteRules(ctx *gin.Context) {
+	// swagger:route DELETE /config/rules/{rules} Rules deleteRulesRequest
+	//
+	// Delete rule list by name.
+	//
+	//     Security:
+	//       basicAuth: []
+	//
+	//     Responses:
+	//       200: deleteRulesResponse
+
+	var req deleteRulesRequest
+	ctx.ShouldBindUri(&req)
+
+	if !registry.RulesRegistry().IsRegistered(req.Rules) {
+		writeError(ctx, ErrNotFound)
+		return
+	}
+	registry.RulesRegistry().Unregister(req.Rules)
+
+	config.OnUpdate(func(c *config.Config) error {
+		rules := c.Rules
+		c.Rules = nil
+		for _, s := range rules {
+			if s.Name == req.Rules {
+				continue
+			}
+			c.Rules = append(c.Rules, s)
+		}
+		return nil
+	})
+
+	ctx.JSON(http.StatusOK, Response{
+		Msg:      "OK",
+		Revision: recordRevision(ctx),
+	})
+}
@@ -0,0 +1,52 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-gost/x/config"
+)
+
+// swagger:parameters updateTLSRequest
+type updateTLSRequest struct {
+	// in: body
+	Data config.TLSConfig `json:"data"`
+}
+
+// successful operation.
+// swagger:response updateTLSResponse
+type updateTLSResponse struct {
+	Data Response
+}
+
+func updateTLS(ctx *gin.Context) {
+	// swagger:route PUT /config/tls TLS updateTLSRequest
+	//
+	// Update the global TLS config,
+	// the services using the default TLS certificate are rebuilt.
+	//
+	//     Security:
+	//       basicAuth: []
+	//
+	//     Responses:
+	//       200: updateTLSResponse
+
+	var req updateTLSRequest
+	if err := ctx.ShouldBindJSON(&req.Data); err != nil {
+		writeError(ctx, ErrInvalid)
+		return
+	}
+
+	rev, err := applyConfig(ctx, func(cfg *config.Config) {
+		cfg.TLS = &req.Data
+	})
+	if err != nil {
+		writeError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, Response{
+		Msg:      "OK",
+		Revision: rev,
+	})
+}
@@ -0,0 +1,181 @@
+package api
+
+import (
+	"net"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-gost/x/internal/conntrack"
+)
+
+// Connection is an active client connection.
+type Connection = conntrack.Info
+
+// connectionFilter selects the connections, the empty fields match all.
+type connectionFilter struct {
+	// service name.
+	// in: query
+	Service string `form:"service" json:"service"`
+	// authenticated client ID (user).
+	// in: query
+	User string `form:"user" json:"user"`
+	// client IP or address.
+	// in: query
+	Client string `form:"client" json:"client"`
+	// destination host or address.
+	// in: query
+	Dst string `form:"dst" json:"dst"`
+	// chain name.
+	// in: query
+	Chain string `form:"chain" json:"chain"`
+}
+
+func (f *connectionFilter) empty() bool {
+	return *f == connectionFilter{}
+}
+
+func (f *connectionFilter) match(c *conntrack.Info) bool {
+	if f.Service != "" && f.Service != c.Service {
+		return false
+	}
+	if f.User != "" && f.User != c.ClientID {
+		return false
+	}
+	if f.Client != "" && !matchAddr(f.Client, c.ClientAddr) {
+		return false
+	}
+	if f.Dst != "" && !matchAddr(f.Dst, c.Dst) {
+		return false
+	}
+	if f.Chain != "" && f.Chain != c.Chain {
+		return false
+	}
+	return true
+}
+
+// matchAddr reports whether s is the address addr or the host of it.
+func matchAddr(s string, addr string) bool {
+	if s == addr {
+		return true
+	}
+	host, _, _ := net.SplitHostPort(addr)
+	return host != "" && s == host
+}
+
+// swagger:parameters getConnectionsRequest
+type getConnectionsRequest struct {
+	connectionFilter
+}
+
+// successful operation.
+// swagger:response getConnectionsResponse
+type getConnectionsResponse struct {
+	// in: body
+	Data connectionList
+}
+
+type connectionList struct {
+	Count int          `json:"count"`
+	List  []Connection `json:"list"`
+}
+
+func getConnections(ctx *gin.Context) {
+	// swagger:route GET /connections Connection getConnectionsRequest
+	//
+	// Get the active client connections, ordered by the start time.
+	//
+	//     Security:
+	//       basicAuth: []
+	//
+	//     Responses:
+	//       200: getConnectionsResponse
+
+	var req getConnectionsRequest
+	ctx.ShouldBindQuery(&req.connectionFilter)
+
+	var resp getConnectionsResponse
+	resp.Data.List = conntrack.List(req.match)
+	resp.Data.Count = len(resp.Data.List)
+
+	ctx.JSON(http.StatusOK, resp.Data)
+}
+
+// swagger:parameters deleteConnectionRequest
+type deleteConnectionRequest struct {
+	// in: path
+	// required: true
+	Sid string `uri:"sid" json:"sid"`
+}
+
+// successful operation.
+// swagger:response deleteConnectionResponse
+type deleteConnectionResponse struct {
+	Data Response
+}
+
+func deleteConnection(ctx *gin.Context) {
+	// swagger:route DELETE /connections/{sid} Connection deleteConnectionRequest
+	//
+	// Terminate the connection by session ID.
+	//
+	//     Security:
+	//       basicAuth: []
+	//
+	//     Responses:
+	//       200: deleteConnectionResponse
+
+	var req deleteConnectionRequest
+	ctx.ShouldBindUri(&req)
+
+	c := conntrack.Get(req.Sid)
+	if c == nil {
+		writeError(ctx, ErrNotFound)
+		return
+	}
+	c.Close()
+
+	ctx.JSON(http.StatusOK, Response{
+		Msg: "OK",
+	})
+}
+
+// swagger:parameters deleteConnectionsRequest
+type deleteConnectionsRequest struct {
+	connectionFilter
+}
+
+// successful operation.
+// swagger:response deleteConnectionsResponse
+type deleteConnectionsResponse struct {
+	// in: body
+	Data struct {
+		// the number of connections terminated.
+		Count int `json:"count"`
+	}
+}
+
+func deleteConnections(ctx *gin.Context) {
+	// swagger:route DELETE /connections Connection deleteConnectionsRequest
+	//
+	// Terminate the connections matching the filter, such as all the connections of a user or a service.
+	// At least one filter is required.
+	//
+	//     Security:
+	//       basicAuth: []
+	//
+	//     Responses:
+	//       200: deleteConnectionsResponse
+
+	var req deleteConnectionsRequest
+	ctx.ShouldBindQuery(&req.connectionFilter)
+
+	if req.empty() {
+		writeError(ctx, ErrInvalid)
+		return
+	}
+
+	var resp deleteConnectionsResponse
+	resp.Data.Count = conntrack.Kill(req.match)
+
+	ctx.JSON(http.StatusOK, resp.Data)
+}
@@ -0,0 +1,219 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-gost/core/chain"
+	"github.com/go-gost/core/hop"
+	"github.com/go-gost/core/selector"
+	"github.com/go-gost/x/registry"
+	xs "github.com/go-gost/x/selector"
+)
+
+// NodeStatus is the health state of a node.
+type NodeStatus struct {
+	Name string `json:"name"`
+	Addr string `json:"addr"`
+	// the number of consecutive failures.
+	FailCount int64 `json:"failCount"`
+	// the time of the latest failure.
+	FailTime *time.Time `json:"failTime,omitempty"`
+	// the node is filtered out by the selector, for failures or marked down.
+	Filtered bool `json:"filtered"`
+	// the node is marked down manually.
+	Down bool `json:"down"`
+	// the node failed the active health check and is not recovered yet.
+	Unhealthy bool `json:"unhealthy"`
+	Backup    bool `json:"backup"`
+	Weight    int  `json:"weight"`
+	// the latency in milliseconds of the latest successful connection.
+	Latency float64 `json:"latency"`
+	// the moving average of the latency in milliseconds.
+	LatencyAvg float64 `json:"latencyAvg"`
+	// the moving average of the connection error rate.
+	ErrorRate float64 `json:"errorRate"`
+	// the number of the active connections through the node.
+	Conns int64 `json:"conns"`
+	// the state of the circuit breaker: closed, open or half-open.
+	Breaker string `json:"breaker"`
+}
+
+func nodeStatus(node *chain.Node, sel selector.Selector[*chain.Node]) NodeStatus {
+	st := NodeStatus{
+		Name:       node.Name,
+		Addr:       node.Addr,
+		Filtered:   xs.Filtered(sel, node),
+		Down:       xs.StateOf(node).Down(),
+		Unhealthy:  xs.StateOf(node).Unhealthy(),
+		Backup:     xs.IsBackup(node),
+		Weight:     xs.Weight(node),
+		Latency:    float64(xs.StateOf(node).Latency()) / float64(time.Millisecond),
+		LatencyAvg: float64(xs.StateOf(node).LatencyAvg()) / float64(time.Millisecond),
+		ErrorRate:  xs.StateOf(node).ErrorRate(),
+		Conns:      xs.StateOf(node).Conns(),
+		Breaker:    xs.StateOf(node).BreakerState().String(),
+	}
+	if marker := node.Marker(); marker != nil {
+		st.FailCount = marker.Count()
+		if t := marker.Time(); t.Unix() > 0 {
+			st.FailTime = &t
+		}
+	}
+	return st
+}
+
+func nodeStatusList(h hop.Hop) []NodeStatus {
+	list := []NodeStatus{}
+	sel := hopSelector(h)
+	if nl, ok := h.(hop.NodeList); ok {
+		for _, node := range nl.Nodes() {
+			if node != nil {
+				list = append(list, nodeStatus(node, sel))
+			}
+		}
+	}
+	return list
+}
+
+// hopSelector returns the selector of the nodes of the hop h, which the nodes are filtered by.
+func hopSelector(h hop.Hop) selector.Selector[*chain.Node] {
+	if v, ok := h.(interface {
+		Selector() selector.Selector[*chain.Node]
+	}); ok {
+		return v.Selector()
+	}
+	return nil
+}
+
+// swagger:parameters getHopNodesRequest
+type getHopNodesRequest struct {
+	// in: path
+	// required: true
+	Hop string `uri:"hop" json:"hop"`
+}
+
+// successful operation.
+// swagger:response getHopNodesResponse
+type getHopNodesResponse struct {
+	// in: body
+	Data nodeStatusListData
+}
+
+type nodeStatusListData struct {
+	Count int          `json:"count"`
+	List  []NodeStatus `json:"list"`
+}
+
+func getHopNodes(ctx *gin.Context) {
+	// swagger:route GET /hops/{hop}/nodes Hop getHopNodesRequest
+	//
+	// Get the health state of the nodes in the hop.
+	//
+	//     Security:
+	//       basicAuth: []
+	//
+	//     Responses:
+	//       200: getHopNodesResponse
+
+	var req getHopNodesRequest
+	ctx.ShouldBindUri(&req)
+
+	h := registry.HopRegistry().GetAll()[req.Hop]
+	if h == nil {
+		writeError(ctx, ErrNotFound)
+		return
+	}
+
+	var resp getHopNodesResponse
+	resp.Data.List = nodeStatusList(h)
+	resp.Data.Count = len(resp.Data.List)
+
+	ctx.JSON(http.StatusOK, resp.Data)
+}
+
+// swagger:parameters markNodeRequest
+type markNodeRequest struct {
+	// in: path
+	// required: true
+	Hop string `uri:"hop" json:"hop"`
+	// in: path
+	// required: true
+	Node string `uri:"node" json:"node"`
+}
+
+// successful operation.
+// swagger:response markNodeResponse
+type markNodeResponse struct {
+	Data Response
+}
+
+func markNodeDown(ctx *gin.Context) {
+	// swagger:route POST /hops/{hop}/nodes/{node}/down Hop markNodeRequest
+	//
+	// Mark the node down manually, the node is not selected until it is marked up,
+	// the existing connections through the node are not affected.
+	//
+	//     Security:
+	//       basicAuth: []
+	//
+	//     Responses:
+	//       200: markNodeResponse
+
+	markNode(ctx, true)
+}
+
+func markNodeUp(ctx *gin.Context) {
+	// swagger:route POST /hops/{hop}/nodes/{node}/up Hop markNodeRequest
+	//
+	// Mark the node up, the failures of the node are cleared as well.
+	//
+	//     Security:
+	//       basicAuth: []
+	//
+	//     Responses:
+	//       200: markNodeResponse
+
+	markNode(ctx, false)
+}
+
+func markNode(ctx *gin.Context, down bool) {
+	var req markNodeRequest
+	ctx.ShouldBindUri(&req)
+
+	node := findNode(registry.HopRegistry().GetAll()[req.Hop], req.Node)
+	if node == nil {
+		writeError(ctx, ErrNotFound)
+		return
+	}
+
+	state := xs.StateOf(node)
+	if state == nil {
+		writeError(ctx, ErrInvalid)
+		return
+	}
+	state.SetDown(down)
+	if !down {
+		if marker := node.Marker(); marker != nil {
+			marker.Reset()
+		}
+	}
+
+	ctx.JSON(http.StatusOK, Response{
+		Msg: "OK",
+	})
+}
+
+func findNode(h hop.Hop, name string) *chain.Node {
+	nl, ok := h.(hop.NodeList)
+	if !ok {
+		return nil
+	}
+	for _, node := range nl.Nodes() {
+		if node != nil && node.Name == name {
+			return node
+		}
+	}
+	return nil
+}
@@ -1,12 +1,16 @@
 package api
 
 import (
+	"fmt"
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-gost/core/auth"
 	"github.com/go-gost/core/logger"
+	"github.com/go-gost/x/config"
+	"github.com/go-gost/x/config/loader"
+	"github.com/go-gost/x/config/revision"
 )
 
 func mwLogger() gin.HandlerFunc {
@@ -29,6 +33,11 @@ func mwLogger() gin.HandlerFunc {
 	}
 }
 
+const (
+	// the context key of the authenticated user.
+	userKey = "user"
+)
+
 func mwBasicAuth(auther auth.Authenticator) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		if auther == nil {
@@ -37,6 +46,23 @@ func mwBasicAuth(auther auth.Authenticator) gin.HandlerFunc {
 		u, p, _ := c.Request.BasicAuth()
 		if _, ok := auther.Authenticate(c, u, p); !ok {
 			c.AbortWithStatus(http.StatusUnauthorized)
+			return
 		}
+		c.Set(userKey, u)
 	}
 }
+
+// mwLock serializes the request with the changes applied by the config loader,
+// for the handlers changing the registries and the global config directly.
+func mwLock() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		loader.Do(c.Next)
+	}
+}
+
+// recordRevision records the current config as a revision made by the request, and returns its ID.
+// It is called in the same critical section as the change, so the revisions are recorded in order.
+func recordRevision(ctx *gin.Context) int {
+	return revision.Record(config.Global(), ctx.GetString(userKey),
+		fmt.Sprintf("%s %s", ctx.Request.Method, ctx.Request.URL.Path))
+}
@@ -0,0 +1,109 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-gost/x/quota"
+	"github.com/go-gost/x/registry"
+)
+
+// QuotaUsage is the usage of a client in the current period of the quota.
+type QuotaUsage = quota.Usage
+
+// swagger:parameters getQuotaUsageRequest
+type getQuotaUsageRequest struct {
+	// in: path
+	// required: true
+	Quota string `uri:"quota" json:"quota"`
+	// authenticated client ID, all the clients if it is empty.
+	// in: query
+	Client string `form:"client" json:"client"`
+}
+
+// successful operation.
+// swagger:response getQuotaUsageResponse
+type getQuotaUsageResponse struct {
+	// in: body
+	Data quotaUsageList
+}
+
+type quotaUsageList struct {
+	Count int          `json:"count"`
+	List  []QuotaUsage `json:"list"`
+}
+
+func getQuotaUsage(ctx *gin.Context) {
+	// swagger:route GET /quotas/{quota}/usage Quota getQuotaUsageRequest
+	//
+	// Get the usage of the clients in the current period of the quota, ordered by the client ID.
+	//
+	//     Security:
+	//       basicAuth: []
+	//
+	//     Responses:
+	//       200: getQuotaUsageResponse
+
+	var req getQuotaUsageRequest
+	ctx.ShouldBindUri(&req)
+	ctx.ShouldBindQuery(&req)
+
+	if !registry.QuotaRegistry().IsRegistered(req.Quota) {
+		writeError(ctx, ErrNotFound)
+		return
+	}
+
+	var resp getQuotaUsageResponse
+	resp.Data.List = []QuotaUsage{}
+	for _, u := range registry.QuotaRegistry().Get(req.Quota).Usage(ctx) {
+		if req.Client != "" && u.Client != req.Client {
+			continue
+		}
+		resp.Data.List = append(resp.Data.List, u)
+	}
+	resp.Data.Count = len(resp.Data.List)
+
+	ctx.JSON(http.StatusOK, resp.Data)
+}
+
+// swagger:parameters resetQuotaUsageRequest
+type resetQuotaUsageRequest struct {
+	// in: path
+	// required: true
+	Quota string `uri:"quota" json:"quota"`
+	// authenticated client ID, all the clients if it is empty.
+	// in: query
+	Client string `form:"client" json:"client"`
+}
+
+// successful operation.
+// swagger:response resetQuotaUsageResponse
+type resetQuotaUsageResponse struct {
+	Data Response
+}
+
+func resetQuotaUsage(ctx *gin.Context) {
+	// swagger:route DELETE /quotas/{quota}/usage Quota resetQuotaUsageRequest
+	//
+	// Reset the usage of the client, or of all the clients if no client is specified.
+	//
+	//     Security:
+	//       basicAuth: []
+	//
+	//     Responses:
+	//       200: resetQuotaUsageResponse
+
+	var req resetQuotaUsageRequest
+	ctx.ShouldBindUri(&req)
+	ctx.ShouldBindQuery(&req)
+
+	if !registry.QuotaRegistry().IsRegistered(req.Quota) {
+		writeError(ctx, ErrNotFound)
+		return
+	}
+	registry.QuotaRegistry().Get(req.Quota).Reset(ctx, req.Client)
+
+	ctx.JSON(http.StatusOK, Response{
+		Msg: "OK",
+	})
+}
@@ -79,6 +79,22 @@ func NewService(addr string, opts ...Option) (service.Service, error) {
 	config.Use(mwBasicAuth(options.auther))
 	registerConfig(config)
 
+	connections := router.Group("/connections")
+	connections.Use(mwBasicAuth(options.auther))
+	registerConnections(connections)
+
+	hops := router.Group("/hops")
+	hops.Use(mwBasicAuth(options.auther))
+	registerHops(hops)
+
+	chains := router.Group("/chains")
+	chains.Use(mwBasicAuth(options.auther))
+	registerChains(chains)
+
+	quotas := router.Group("/quotas")
+	quotas.Use(mwBasicAuth(options.auther))
+	registerQuotas(quotas)
+
 	return &server{
 		s: &http.Server{
 			Handler: r,
@@ -99,59 +115,117 @@ func (s *server) Close() error {
 	return s.s.Close()
 }
 
+func registerConnections(connections *gin.RouterGroup) {
+	connections.GET("", getConnections)
+	connections.DELETE("", deleteConnections)
+	connections.DELETE("/:sid", deleteConnection)
+}
+
+func registerHops(hops *gin.RouterGroup) {
+	hops.GET("/:hop/nodes", getHopNodes)
+	hops.POST("/:hop/nodes/:node/down", markNodeDown)
+	hops.POST("/:hop/nodes/:node/up", markNodeUp)
+}
+
+func registerChains(chains *gin.RouterGroup) {
+	chains.GET("/:chain/status", getChainStatus)
+}
+
+func registerQuotas(quotas *gin.RouterGroup) {
+	quotas.GET("/:quota/usage", getQuotaUsage)
+	quotas.DELETE("/:quota/usage", resetQuotaUsage)
+}
+
 func registerConfig(config *gin.RouterGroup) {
 	config.GET("", getConfig)
 	config.POST("", saveConfig)
+	config.PUT("", replaceConfig)
+	config.POST("/batch", batchConfig)
+
+	config.GET("/revisions", getRevisions)
+	config.GET("/revisions/:id/diff", getRevisionDiff)
+	config.POST("/revisions/:id/rollback", rollbackRevision)
+
+	// the handlers of the objects change the registries directly,
+	// they are serialized with the changes applied by the config loader.
+	objects := config.Group("", mwLock())
+
+	objects.POST("/services", createService)
+	objects.PUT("/services/:service", updateService)
+	objects.DELETE("/services/:service", deleteService)
+
+	objects.POST("/chains", createChain)
+	objects.PUT("/chains/:chain", updateChain)
+	objects.DELETE("/chains/:chain", deleteChain)
+
+	objects.POST("/hops", createHop)
+	objects.PUT("/hops/:hop", updateHop)
+	objects.DELETE("/hops/:hop", deleteHop)
+
+	objects.POST("/authers", createAuther)
+	objects.PUT("/authers/:auther", updateAuther)
+	objects.DELETE("/authers/:auther", deleteAuther)
+
+	objects.POST("/admissions", createAdmission)
+	objects.PUT("/admissions/:admission", updateAdmission)
+	objects.DELETE("/admissions/:admission", deleteAdmission)
+
+	objects.POST("/bypasses", createBypass)
+	objects.PUT("/bypasses/:bypass", updateBypass)
+	objects.DELETE("/bypasses/:bypass", deleteBypass)
+
+	objects.POST("/resolvers", createResolver)
+	objects.PUT("/resolvers/:resolver", updateResolver)
+	objects.DELETE("/resolvers/:resolver", deleteResolver)
 
-	config.POST("/services", createService)
-	config.PUT("/services/:service", updateService)
-	config.DELETE("/services/:service", deleteService)
+	objects.POST("/hosts", createHosts)
+	objects.PUT("/hosts/:hosts", updateHosts)
+	objects.DELETE("/hosts/:hosts", deleteHosts)
 
-	config.POST("/chains", createChain)
-	config.PUT("/chains/:chain", updateChain)
-	config.DELETE("/chains/:chain", deleteChain)
+	objects.POST("/ingresses", createIngress)
+	objects.PUT("/ingresses/:ingress", updateIngress)
+	objects.DELETE("/ingresses/:ingress", deleteIngress)
 
-	config.POST("/hops", createHop)
-	config.PUT("/hops/:hop", updateHop)
-	config.DELETE("/hops/:hop", deleteHop)
+	objects.POST("/routers", createRouter)
+	objects.PUT("/routers/:router", updateRouter)
+	objects.DELETE("/routers/:router", deleteRouter)
 
-	config.POST("/authers", createAuther)
-	config.PUT("/authers/:auther", updateAuther)
-	config.DELETE("/authers/:auther", deleteAuther)
+	objects.POST("/limiters", createLimiter)
+	objects.PUT("/limiters/:limiter", updateLimiter)
+	objects.DELETE("/limiters/:limiter", deleteLimiter)
 
-	config.POST("/admissions", createAdmission)
-	config.PUT("/admissions/:admission", updateAdmission)
-	config.DELETE("/admissions/:admission", deleteAdmission)
+	objects.POST("/climiters", createConnLimiter)
+	objects.PUT("/climiters/:limiter", updateConnLimiter)
+	objects.DELETE("/climiters/:limiter", deleteConnLimiter)
 
-	config.POST("/bypasses", createBypass)
-	config.PUT("/bypasses/:bypass", updateBypass)
-	config.DELETE("/bypasses/:bypass", deleteBypass)
+	objects.POST("/rlimiters", createRateLimiter)
+	objects.PUT("/rlimiters/:limiter", updateRateLimiter)
+	objects.DELETE("/rlimiters/:limiter", deleteRateLimiter)
 
-	config.POST("/resolvers", createResolver)
-	config.PUT("/resolvers/:resolver", updateResolver)
-	config.DELETE("/resolvers/:resolver", deleteResolver)
+	objects.POST("/rules", createRules)
+	objects.PUT("/rules/:rules", updateRules)
+	objects.DELETE("/rules/:rules", deleteRules)
 
-	config.POST("/hosts", createHosts)
-	config.PUT("/hosts/:hosts", updateHosts)
-	config.DELETE("/hosts/:hosts", deleteHosts)
+	objects.POST("/acls", createACL)
+	objects.PUT("/acls/:acl", updateACL)
+	objects.DELETE("/acls/:acl", deleteACL)
 
-	config.POST("/ingresses", createIngress)
-	config.PUT("/ingresses/:ingress", updateIngress)
-	config.DELETE("/ingresses/:ingress", deleteIngress)
+	objects.POST("/quotas", createQuota)
+	objects.PUT("/quotas/:quota", updateQuota)
+	objects.DELETE("/quotas/:quota", deleteQuota)
 
-	config.POST("/routers", createRouter)
-	config.PUT("/routers/:router", updateRouter)
-	config.DELETE("/routers/:router", deleteRouter)
+	config.POST("/sds", createSD)
+	config.PUT("/sds/:sd", updateSD)
+	config.DELETE("/sds/:sd", deleteSD)
 
-	config.POST("/limiters", createLimiter)
-	config.PUT("/limiters/:limiter", updateLimiter)
-	config.DELETE("/limiters/:limiter", deleteLimiter)
+	config.POST("/recorders", createRecorder)
+	config.PUT("/recorders/:recorder", updateRecorder)
+	config.DELETE("/recorders/:recorder", deleteRecorder)
 
-	config.POST("/climiters", createConnLimiter)
-	config.PUT("/climiters/:limiter", updateConnLimiter)
-	config.DELETE("/climiters/:limiter", deleteConnLimiter)
+	config.POST("/loggers", createLogger)
+	config.PUT("/loggers/:logger", updateLogger)
+	config.DELETE("/loggers/:logger", deleteLogger)
 
-	config.POST("/rlimiters", createRateLimiter)
-	config.PUT("/rlimiters/:limiter", updateRateLimiter)
-	config.DELETE("/rlimiters/:limiter", deleteRateLimiter)
+	config.PUT("/tls", updateTLS)
+	config.PUT("/log", updateLog)
 }
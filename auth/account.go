@@ -0,0 +1,62 @@
+package auth
+
+import (
+	"context"
+	"strings"
+
+	"github.com/go-gost/x/internal/conntrack"
+)
+
+// Account is the identity of a user authenticated by the built-in authenticator.
+type Account struct {
+	// ID is the client ID of the user, default is the username.
+	ID string
+	// Groups are the groups the user belongs to.
+	Groups []string
+}
+
+// parseCredential splits the value loaded for a user into the password and the account:
+//
+//	<password> [id=<id>] [groups=<group>,<group>...]
+//
+// The account is nil if the value has no account attributes.
+func parseCredential(v string) (string, *Account) {
+	var acc *Account
+
+	v = strings.TrimSpace(v)
+	for v != "" {
+		i := strings.LastIndexAny(v, " \t")
+		attr := v[i+1:]
+
+		if s, ok := strings.CutPrefix(attr, "id="); ok {
+			if acc == nil {
+				acc = &Account{}
+			}
+			acc.ID = s
+		} else if s, ok := strings.CutPrefix(attr, "groups="); ok {
+			if acc == nil {
+				acc = &Account{}
+			}
+			for _, g := range strings.Split(s, ",") {
+				if g = strings.TrimSpace(g); g != "" {
+					acc.Groups = append(acc.Groups, g)
+				}
+			}
+		} else {
+			break
+		}
+
+		if i < 0 {
+			v = ""
+		} else {
+			v = strings.TrimSpace(v[:i])
+		}
+	}
+	return v, acc
+}
+
+// GroupsFromContext returns the groups of the client authenticated
+// by the built-in authenticator for the connection in the context ctx.
+func GroupsFromContext(ctx context.Context) []string {
+	return conntrack.Groups(ctx)
+}
@@ -10,12 +10,14 @@ import (
 
 	"github.com/go-gost/core/auth"
 	"github.com/go-gost/core/logger"
+	"github.com/go-gost/x/internal/conntrack"
 	"github.com/go-gost/x/internal/loader"
 	xlogger "github.com/go-gost/x/logger"
 )
 
 type options struct {
 	auths       map[string]string
+	accounts    map[string]*Account
 	fileLoader  loader.Loader
 	redisLoader loader.Loader
 	httpLoader  loader.Loader
@@ -31,6 +33,13 @@ func AuthsOption(auths map[string]string) Option {
 	}
 }
 
+// AccountsOption sets the accounts of the users defined by AuthsOption.
+func AccountsOption(accounts map[string]*Account) Option {
+	return func(opts *options) {
+		opts.accounts = accounts
+	}
+}
+
 func ReloadPeriodOption(period time.Duration) Option {
 	return func(opts *options) {
 		opts.period = period
@@ -62,9 +71,14 @@ func LoggerOption(logger logger.Logger) Option {
 }
 
 // authenticator is an Authenticator that authenticates client by key-value pairs.
+// The values loaded from the file, redis and http loaders are in the format:
+//
+//	<password> [id=<id>] [groups=<group>,<group>...]
 type authenticator struct {
 	kvs        map[string]string
+	accounts   map[string]*Account
 	mu         sync.RWMutex
+	cache      *verifyCache
 	cancelFunc context.CancelFunc
 	options    options
 }
@@ -82,6 +96,7 @@ func NewAuthenticator(opts ...Option) auth.Authenticator {
 	ctx, cancel := context.WithCancel(context.TODO())
 	p := &authenticator{
 		kvs:        make(map[string]string),
+		cache:      newVerifyCache(defaultCacheSize, defaultCacheTTL),
 		cancelFunc: cancel,
 		options:    options,
 	}
@@ -96,21 +111,48 @@ func NewAuthenticator(opts ...Option) auth.Authenticator {
 	return p
 }
 
-// Authenticate checks the validity of the provided user-password pair.
+// Authenticate checks the validity of the provided user-password pair,
+// the client ID is the ID of the account of the user, or the username if the ID is not set.
+// The password of the user can be stored in plaintext or as a bcrypt, argon2id or SHA-crypt hash.
 func (p *authenticator) Authenticate(ctx context.Context, user, password string, opts ...auth.Option) (string, bool) {
 	if p == nil {
 		return "", true
 	}
 
 	p.mu.RLock()
-	defer p.mu.RUnlock()
+	v, ok := p.kvs[user]
+	acc := p.accounts[user]
+	p.mu.RUnlock()
 
-	if len(p.kvs) == 0 {
+	if !ok {
 		return "", false
 	}
 
-	v, ok := p.kvs[user]
-	return "", ok && (v == "" || password == v)
+	id := user
+	if acc != nil && acc.ID != "" {
+		id = acc.ID
+	}
+
+	switch {
+	case v == "":
+		ok = true
+	case isHashed(v):
+		ok = p.cache.verify(v, password)
+	default:
+		ok = verifyPassword(v, password)
+	}
+	if !ok {
+		return "", false
+	}
+
+	// the groups are kept with the connection, for the limiters and the ACLs of the client.
+	var groups []string
+	if acc != nil {
+		groups = acc.Groups
+	}
+	conntrack.SetGroups(ctx, groups)
+
+	return id, true
 }
 
 func (p *authenticator) periodReload(ctx context.Context) error {
@@ -136,13 +178,23 @@ func (p *authenticator) periodReload(ctx context.Context) error {
 
 func (p *authenticator) reload(ctx context.Context) (err error) {
 	kvs := make(map[string]string)
+	accounts := make(map[string]*Account)
 	for k, v := range p.options.auths {
 		kvs[k] = v
 	}
+	for k, v := range p.options.accounts {
+		accounts[k] = v
+	}
 
 	m, err := p.load(ctx)
 	for k, v := range m {
-		kvs[k] = v
+		password, acc := parseCredential(v)
+		kvs[k] = password
+		if acc != nil {
+			accounts[k] = acc
+		} else {
+			delete(accounts, k)
+		}
 	}
 
 	p.options.logger.Debugf("load items %d", len(m))
@@ -151,6 +203,7 @@ func (p *authenticator) reload(ctx context.Context) (err error) {
 	defer p.mu.Unlock()
 
 	p.kvs = kvs
+	p.accounts = accounts
 
 	return
 }
@@ -176,10 +229,10 @@ func (p *authenticator) load(ctx context.Context) (m map[string]string, err erro
 		}
 	}
 	if p.options.redisLoader != nil {
-		if mapper, ok := p.options.fileLoader.(loader.Mapper); ok {
+		if mapper, ok := p.options.redisLoader.(loader.Mapper); ok {
 			auths, er := mapper.Map(ctx)
 			if er != nil {
-				p.options.logger.Warnf("file loader: %v", er)
+				p.options.logger.Warnf("redis loader: %v", er)
 			}
 			for k, v := range auths {
 				m[k] = v
@@ -0,0 +1,47 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/go-gost/core/auth"
+	climiter "github.com/go-gost/core/limiter/conn"
+	"github.com/go-gost/core/logger"
+	"github.com/go-gost/x/internal/conntrack"
+	xconn "github.com/go-gost/x/limiter/conn"
+)
+
+// connLimitAuthenticator limits the concurrent connections of the authenticated clients.
+type connLimitAuthenticator struct {
+	auther  auth.Authenticator
+	limiter climiter.ConnLimiter
+	logger  logger.Logger
+}
+
+// ConnLimitAuthenticator creates an Authenticator which authenticates the clients by a,
+// then acquires a connection from the limiter of the client and its groups in the connection limiter lim.
+// The client is rejected if it exceeds its limit, the acquired connection is released when the connection is closed.
+func ConnLimitAuthenticator(a auth.Authenticator, lim climiter.ConnLimiter, log logger.Logger) auth.Authenticator {
+	if a == nil || lim == nil {
+		return a
+	}
+	return &connLimitAuthenticator{
+		auther:  a,
+		limiter: lim,
+		logger:  log,
+	}
+}
+
+func (p *connLimitAuthenticator) Authenticate(ctx context.Context, user, password string, opts ...auth.Option) (string, bool) {
+	id, ok := p.auther.Authenticate(ctx, user, password, opts...)
+	if !ok || id == "" {
+		return id, ok
+	}
+
+	if !conntrack.Acquire(ctx, p.limiter.Limiter(xconn.ClientLimitKey(id, GroupsFromContext(ctx)))) {
+		if p.logger != nil {
+			p.logger.Debugf("client %s exceeds the connection limit", id)
+		}
+		return "", false
+	}
+	return id, true
+}
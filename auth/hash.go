@@ -0,0 +1,304 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"hash"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	// the maximum number of the verified passwords kept in the cache.
+	defaultCacheSize = 1024
+	// how long a verified password is kept in the cache.
+	defaultCacheTTL = 10 * time.Minute
+)
+
+// isHashed reports whether the password v is stored as a hash.
+// The hash is detected by its prefix:
+// $2a$, $2b$ or $2y$ for bcrypt, $argon2id$ for argon2id, $5$ and $6$ for SHA-crypt.
+func isHashed(v string) bool {
+	for _, prefix := range []string{"$2a$", "$2b$", "$2y$", "$argon2id$", "$5$", "$6$"} {
+		if strings.HasPrefix(v, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// verifyPassword reports whether the password matches the stored value v,
+// which is either a hash or a plaintext password.
+func verifyPassword(v, password string) bool {
+	switch {
+	case strings.HasPrefix(v, "$2a$"), strings.HasPrefix(v, "$2b$"), strings.HasPrefix(v, "$2y$"):
+		return bcrypt.CompareHashAndPassword([]byte(v), []byte(password)) == nil
+	case strings.HasPrefix(v, "$argon2id$"):
+		ok, _ := verifyArgon2id(v, password)
+		return ok
+	case strings.HasPrefix(v, "$5$"):
+		ok, _ := verifySHACrypt(v, password, sha256.New, sha256Order)
+		return ok
+	case strings.HasPrefix(v, "$6$"):
+		ok, _ := verifySHACrypt(v, password, sha512.New, sha512Order)
+		return ok
+	default:
+		return subtle.ConstantTimeCompare([]byte(v), []byte(password)) == 1
+	}
+}
+
+// verifyArgon2id verifies the password against the argon2id hash in the PHC string format:
+// $argon2id$v=19$m=65536,t=3,p=4$<salt>$<hash>
+func verifyArgon2id(v, password string) (bool, error) {
+	parts := strings.Split(v, "$")
+	if len(parts) != 6 {
+		return false, fmt.Errorf("argon2id: invalid hash")
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return false, fmt.Errorf("argon2id: %w", err)
+	}
+	if version != argon2.Version {
+		return false, fmt.Errorf("argon2id: unsupported version %d", version)
+	}
+
+	var memory, time uint32
+	var threads uint8
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &time, &threads); err != nil {
+		return false, fmt.Errorf("argon2id: %w", err)
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return false, fmt.Errorf("argon2id: %w", err)
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return false, fmt.Errorf("argon2id: %w", err)
+	}
+
+	other := argon2.IDKey([]byte(password), salt, time, memory, threads, uint32(len(key)))
+	return subtle.ConstantTimeCompare(key, other) == 1, nil
+}
+
+const (
+	shaCryptRoundsDefault = 5000
+	shaCryptRoundsMin     = 1000
+	shaCryptRoundsMax     = 999999999
+	shaCryptSaltMax       = 16
+	shaCryptAlphabet      = "./0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+)
+
+// the byte order of the encoded SHA-crypt digests.
+var (
+	sha256Order = []int{
+		0, 10, 20, 21, 1, 11, 12, 22, 2, 3, 13, 23, 24, 4, 14,
+		15, 25, 5, 6, 16, 26, 27, 7, 17, 18, 28, 8, 9, 19, 29,
+		31, 30,
+	}
+	sha512Order = []int{
+		0, 21, 42, 22, 43, 1, 44, 2, 23, 3, 24, 45, 25, 46, 4,
+		47, 5, 26, 6, 27, 48, 28, 49, 7, 50, 8, 29, 9, 30, 51,
+		31, 52, 10, 53, 11, 32, 12, 33, 54, 34, 55, 13, 56, 14, 35,
+		15, 36, 57, 37, 58, 16, 59, 17, 38, 18, 39, 60, 40, 61, 19,
+		62, 20, 41,
+		63,
+	}
+)
+
+// verifySHACrypt verifies the password against the SHA-crypt hash:
+// $5$[rounds=<N>$]<salt>$<hash> for SHA-256 or $6$[rounds=<N>$]<salt>$<hash> for SHA-512.
+func verifySHACrypt(v, password string, newHash func() hash.Hash, order []int) (bool, error) {
+	parts := strings.Split(v, "$")
+	if len(parts) < 4 {
+		return false, fmt.Errorf("sha-crypt: invalid hash")
+	}
+	prefix := "$" + parts[1] + "$"
+	parts = parts[2:]
+
+	rounds, custom := shaCryptRoundsDefault, false
+	if s, ok := strings.CutPrefix(parts[0], "rounds="); ok {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return false, fmt.Errorf("sha-crypt: invalid rounds %s", s)
+		}
+		rounds, custom = min(max(n, shaCryptRoundsMin), shaCryptRoundsMax), true
+		parts = parts[1:]
+	}
+	if len(parts) != 2 {
+		return false, fmt.Errorf("sha-crypt: invalid hash")
+	}
+	salt := parts[0]
+	if len(salt) > shaCryptSaltMax {
+		salt = salt[:shaCryptSaltMax]
+	}
+
+	s := shaCrypt(newHash, []byte(password), []byte(salt), rounds, order)
+	if custom {
+		prefix += "rounds=" + strconv.Itoa(rounds) + "$"
+	}
+	other := prefix + salt + "$" + s
+	return subtle.ConstantTimeCompare([]byte(v), []byte(other)) == 1, nil
+}
+
+// shaCrypt computes the encoded SHA-crypt digest of the password,
+// as specified by https://www.akkadia.org/drepper/SHA-crypt.txt.
+func shaCrypt(newHash func() hash.Hash, password, salt []byte, rounds int, order []int) string {
+	h := newHash()
+	h.Write(password)
+	h.Write(salt)
+	h.Write(password)
+	b := h.Sum(nil)
+
+	h.Reset()
+	h.Write(password)
+	h.Write(salt)
+	h.Write(repeat(b, len(password)))
+	for i := len(password); i > 0; i >>= 1 {
+		if i&1 != 0 {
+			h.Write(b)
+		} else {
+			h.Write(password)
+		}
+	}
+	a := h.Sum(nil)
+
+	h.Reset()
+	for range password {
+		h.Write(password)
+	}
+	p := repeat(h.Sum(nil), len(password))
+
+	h.Reset()
+	for i := 0; i < 16+int(a[0]); i++ {
+		h.Write(salt)
+	}
+	s := repeat(h.Sum(nil), len(salt))
+
+	c := a
+	for i := 0; i < rounds; i++ {
+		h.Reset()
+		if i&1 != 0 {
+			h.Write(p)
+		} else {
+			h.Write(c)
+		}
+		if i%3 != 0 {
+			h.Write(s)
+		}
+		if i%7 != 0 {
+			h.Write(p)
+		}
+		if i&1 != 0 {
+			h.Write(c)
+		} else {
+			h.Write(p)
+		}
+		c = h.Sum(c[:0])
+	}
+
+	var sb strings.Builder
+	for i := 0; i+3 <= len(order); i += 3 {
+		encode24(&sb, c[order[i]], c[order[i+1]], c[order[i+2]], 4)
+	}
+	switch len(order) % 3 {
+	case 1:
+		encode24(&sb, 0, 0, c[order[len(order)-1]], 2)
+	case 2:
+		encode24(&sb, 0, c[order[len(order)-2]], c[order[len(order)-1]], 3)
+	}
+	return sb.String()
+}
+
+// repeat returns the bytes b repeated to the length n.
+func repeat(b []byte, n int) []byte {
+	r := make([]byte, 0, n)
+	for len(r) < n {
+		r = append(r, b[:min(len(b), n-len(r))]...)
+	}
+	return r
+}
+
+func encode24(sb *strings.Builder, b2, b1, b0 byte, n int) {
+	w := uint(b2)<<16 | uint(b1)<<8 | uint(b0)
+	for i := 0; i < n; i++ {
+		sb.WriteByte(shaCryptAlphabet[w&0x3f])
+		w >>= 6
+	}
+}
+
+// verifyCache keeps the recently verified passwords of the hashes,
+// so that the slow hashes are not computed on every authentication.
+// The passwords are not kept in plaintext, only the digests of the hash-password pairs.
+type verifyCache struct {
+	items map[[sha256.Size]byte]time.Time
+	size  int
+	ttl   time.Duration
+	mu    sync.Mutex
+}
+
+func newVerifyCache(size int, ttl time.Duration) *verifyCache {
+	return &verifyCache{
+		items: make(map[[sha256.Size]byte]time.Time),
+		size:  size,
+		ttl:   ttl,
+	}
+}
+
+func (c *verifyCache) key(v, password string) [sha256.Size]byte {
+	h := sha256.New()
+	h.Write([]byte(v))
+	h.Write([]byte{0})
+	h.Write([]byte(password))
+
+	var key [sha256.Size]byte
+	h.Sum(key[:0])
+	return key
+}
+
+// verify verifies the password against the hash v, consulting the cache first.
+// Only the successful verifications are cached.
+func (c *verifyCache) verify(v, password string) bool {
+	key := c.key(v, password)
+	now := time.Now()
+
+	c.mu.Lock()
+	t, ok := c.items[key]
+	c.mu.Unlock()
+	if ok && now.Before(t) {
+		return true
+	}
+
+	if !verifyPassword(v, password) {
+		return false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.items) >= c.size {
+		for k, t := range c.items {
+			if now.After(t) {
+				delete(c.items, k)
+			}
+		}
+		// evict an arbitrary item if all the items are alive.
+		for k := range c.items {
+			if len(c.items) < c.size {
+				break
+			}
+			delete(c.items, k)
+		}
+	}
+	c.items[key] = now.Add(c.ttl)
+	return true
+}
@@ -0,0 +1,51 @@
+package bypass
+
+import (
+	"context"
+	"net"
+
+	"github.com/go-gost/core/bypass"
+	"github.com/go-gost/core/hosts"
+	"github.com/go-gost/core/resolver"
+	"github.com/go-gost/x/acl"
+	xnet "github.com/go-gost/x/internal/net"
+)
+
+var (
+	_ bypass.Bypass = (*aclBypass)(nil)
+)
+
+// aclBypass bypasses the destinations the client is not allowed to access by the ACL.
+type aclBypass struct {
+	acl      acl.ACL
+	resolver resolver.Resolver
+	hosts    hosts.HostMapper
+}
+
+// NewACLBypass creates a bypass.Bypass which contains the destinations denied by the ACL a,
+// so the handlers reject them the same way as the bypassed destinations.
+// The domains are resolved by the host mapper and the resolver of the service, or the system resolver
+// if the service has no resolver, so the IP and CIDR rules are also matched by the resolved addresses.
+func NewACLBypass(a acl.ACL, r resolver.Resolver, hosts hosts.HostMapper) bypass.Bypass {
+	return &aclBypass{
+		acl:      a,
+		resolver: r,
+		hosts:    hosts,
+	}
+}
+
+func (p *aclBypass) Contains(ctx context.Context, network, addr string, opts ...bypass.Option) bool {
+	if p.acl == nil || addr == "" {
+		return false
+	}
+
+	host, _, _ := net.SplitHostPort(addr)
+	if host == "" {
+		host = addr
+	}
+	if net.ParseIP(host) != nil {
+		return !p.acl.Allowed(ctx, network, addr)
+	}
+	ips, _ := xnet.LookupIP(ctx, "ip", host, p.resolver, p.hosts)
+	return !p.acl.Allowed(ctx, network, addr, acl.IPsAllowOption(ips))
+}
@@ -0,0 +1,47 @@
+package bypass
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/go-gost/x/acl"
+	"github.com/go-gost/x/hosts"
+	ctxvalue "github.com/go-gost/x/internal/ctx"
+	xlogger "github.com/go-gost/x/logger"
+)
+
+func TestACLBypassResolvedHost(t *testing.T) {
+	log := xlogger.NewLogger()
+	a := acl.NewACL(
+		acl.RulesOption([]string{
+			"user:bob deny 10.0.0.0/8",
+			"user:bob allow",
+		}),
+		acl.LoggerOption(log),
+	)
+	mapper := hosts.NewHostMapper(
+		hosts.MappingsOption([]hosts.Mapping{
+			{Hostname: "internal.example.com", IP: net.ParseIP("10.0.0.1")},
+			{Hostname: "public.example.com", IP: net.ParseIP("192.0.2.1")},
+		}),
+		hosts.LoggerOption(log),
+	)
+	p := NewACLBypass(a, nil, mapper)
+
+	ctx := ctxvalue.ContextWithClientID(context.Background(), "bob")
+	tests := []struct {
+		addr     string
+		contains bool
+	}{
+		{"10.0.0.1:80", true},
+		{"internal.example.com:80", true},
+		{"public.example.com:80", false},
+		{"192.0.2.1:80", false},
+	}
+	for _, tt := range tests {
+		if v := p.Contains(ctx, "tcp", tt.addr); v != tt.contains {
+			t.Errorf("Contains(%q) = %v, want %v", tt.addr, v, tt.contains)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package bypass
+
+import (
+	"context"
+
+	"github.com/go-gost/core/bypass"
+	ctxvalue "github.com/go-gost/x/internal/ctx"
+	"github.com/go-gost/x/quota"
+)
+
+var (
+	_ bypass.Bypass = (*quotaBypass)(nil)
+)
+
+// quotaBypass bypasses all the destinations of the clients exhausted their quotas.
+type quotaBypass struct {
+	quota quota.Quota
+}
+
+// NewQuotaBypass creates a bypass.Bypass which contains all the destinations
+// of the clients exhausted their hard limits of the quota q,
+// so the handlers reject the new connections of them.
+func NewQuotaBypass(q quota.Quota) bypass.Bypass {
+	return &quotaBypass{quota: q}
+}
+
+func (p *quotaBypass) Contains(ctx context.Context, network, addr string, opts ...bypass.Option) bool {
+	if p.quota == nil {
+		return false
+	}
+	return !p.quota.Allow(ctx, string(ctxvalue.ClientIDFromContext(ctx)))
+}
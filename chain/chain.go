@@ -2,12 +2,21 @@ package chain
 
 import (
 	"context"
+	"io"
+	"slices"
+	"time"
 
 	"github.com/go-gost/core/chain"
 	"github.com/go-gost/core/hop"
 	"github.com/go-gost/core/logger"
 	"github.com/go-gost/core/metadata"
 	"github.com/go-gost/core/selector"
+	xselector "github.com/go-gost/x/selector"
+)
+
+const (
+	defaultFailoverAttempts = 3
+	defaultFailoverTimeout  = 30 * time.Second
 )
 
 var (
@@ -16,9 +25,18 @@ var (
 
 type ChainOptions struct {
 	Metadata metadata.Metadata
+	Failover *FailoverOptions
 	Logger   logger.Logger
 }
 
+// FailoverOptions is the failover of a chain dial.
+type FailoverOptions struct {
+	// the maximum number of the nodes tried for each hop.
+	Attempts int
+	// the total timeout of the failover, default is 30s.
+	Timeout time.Duration
+}
+
 type ChainOption func(*ChainOptions)
 
 func MetadataChainOption(md metadata.Metadata) ChainOption {
@@ -27,6 +45,12 @@ func MetadataChainOption(md metadata.Metadata) ChainOption {
 	}
 }
 
+func FailoverChainOption(failover *FailoverOptions) ChainOption {
+	return func(opts *ChainOptions) {
+		opts.Failover = failover
+	}
+}
+
 func LoggerChainOption(logger logger.Logger) ChainOption {
 	return func(opts *ChainOptions) {
 		opts.Logger = logger
@@ -42,6 +66,7 @@ type Chain struct {
 	hops     []hop.Hop
 	marker   selector.Marker
 	metadata metadata.Metadata
+	failover *FailoverOptions
 	logger   logger.Logger
 }
 
@@ -57,6 +82,7 @@ func NewChain(name string, opts ...ChainOption) *Chain {
 		name:     name,
 		metadata: options.Metadata,
 		marker:   selector.NewFailMarker(),
+		failover: options.Failover,
 		logger:   options.Logger,
 	}
 }
@@ -79,6 +105,22 @@ func (c *Chain) Name() string {
 	return c.name
 }
 
+// Hops returns the hops of the chain.
+func (c *Chain) Hops() []hop.Hop {
+	return c.hops
+}
+
+// Close implements io.Closer interface.
+// It closes the hops owned by the chain, the hops referenced from the registry are not closed.
+func (c *Chain) Close() error {
+	for _, hop := range c.hops {
+		if closer, ok := hop.(io.Closer); ok {
+			closer.Close()
+		}
+	}
+	return nil
+}
+
 func (c *Chain) Route(ctx context.Context, network, address string, opts ...chain.RouteOption) chain.Route {
 	if c == nil || len(c.hops) == 0 {
 		return nil
@@ -89,31 +131,133 @@ func (c *Chain) Route(ctx context.Context, network, address string, opts ...chai
 		opt(&options)
 	}
 
-	rt := NewRoute(ChainRouteOption(c))
+	selectOpts := []hop.SelectOption{
+		hop.NetworkSelectOption(network),
+		hop.AddrSelectOption(address),
+		hop.HostSelectOption(options.Host),
+	}
+
+	rt := c.newRoute()
 	for _, h := range c.hops {
-		node := h.Select(ctx,
-			hop.NetworkSelectOption(network),
-			hop.AddrSelectOption(address),
-			hop.HostSelectOption(options.Host),
-		)
+		node := h.Select(ctx, selectOpts...)
 		if node == nil {
 			// return rt
 			// 跳过不匹配的hop
 			continue
 		}
+		// the nodes before the multiplexed node are used by its transport to dial the sessions.
+		var inner *route
 		if node.Options().Transport.Multiplex() {
-			tr := node.Options().Transport.Copy()
-			tr.Options().Route = rt
-			node = node.Copy()
-			node.Options().Transport = tr
-			rt = NewRoute()
+			inner = rt
+			inner.transport = true
+			rt = c.newRoute()
 		}
 
-		rt.addNode(node)
+		cands := &candidates{
+			hop:   h,
+			inner: inner,
+			opts:  selectOpts,
+			tried: []any{node},
+		}
+		// only the first node of a route can be raced,
+		// as the other nodes are connected through the nodes before them.
+		if len(rt.nodes) == 0 {
+			rt.race, rt.raceDelay = cands.race(ctx)
+		}
+		rt.addNode(muxNode(node, inner), c.reselector(cands))
 	}
 	return rt
 }
 
+func (c *Chain) newRoute() *route {
+	opts := []RouteOption{ChainRouteOption(c)}
+	if c.failover != nil {
+		timeout := c.failover.Timeout
+		if timeout <= 0 {
+			timeout = defaultFailoverTimeout
+		}
+		opts = append(opts, FailoverTimeoutRouteOption(timeout))
+	}
+	return NewRoute(opts...)
+}
+
+// reselector returns the function to select another node to replace the failed node,
+// it returns nil if the failover is disabled.
+func (c *Chain) reselector(cands *candidates) reselectFunc {
+	if c.failover == nil {
+		return nil
+	}
+	attempts := c.failover.Attempts
+	if attempts <= 0 {
+		attempts = defaultFailoverAttempts
+	}
+
+	return func(ctx context.Context) *chain.Node {
+		if len(cands.tried) >= attempts {
+			return nil
+		}
+		return cands.next(ctx)
+	}
+}
+
+// candidates selects the nodes of a hop for a position in the route.
+type candidates struct {
+	hop hop.Hop
+	// the route of the multiplexed nodes.
+	inner *route
+	opts  []hop.SelectOption
+	// the nodes selected.
+	tried []any
+}
+
+// next selects another node which has not been selected,
+// it returns nil if there is no such node.
+func (p *candidates) next(ctx context.Context) *chain.Node {
+	n := p.hop.Select(xselector.ContextWithExcluded(ctx, p.tried...), p.opts...)
+	if n == nil || slices.Contains(p.tried, any(n)) {
+		return nil
+	}
+	p.tried = append(p.tried, n)
+
+	// the replacement must keep the structure of the route.
+	if n.Options().Transport.Multiplex() != (p.inner != nil) {
+		return nil
+	}
+	return muxNode(n, p.inner)
+}
+
+// race selects the nodes raced with the selected node if the hop races its candidates.
+func (p *candidates) race(ctx context.Context) ([]*chain.Node, time.Duration) {
+	r, ok := p.hop.(racer)
+	if !ok {
+		return nil, 0
+	}
+	count, delay := r.Race()
+
+	var nodes []*chain.Node
+	for i := 1; i < count; i++ {
+		n := p.next(ctx)
+		if n == nil {
+			break
+		}
+		nodes = append(nodes, n)
+	}
+	return nodes, delay
+}
+
+// muxNode returns a copy of the multiplexed node which dials its sessions through the route rt,
+// the node is returned as is if rt is nil.
+func muxNode(node *chain.Node, rt *route) *chain.Node {
+	if rt == nil {
+		return node
+	}
+	tr := node.Options().Transport.Copy()
+	tr.Options().Route = rt
+	node = node.Copy()
+	node.Options().Transport = tr
+	return node
+}
+
 type chainGroup struct {
 	chains   []chain.Chainer
 	selector selector.Selector[chain.Chainer]
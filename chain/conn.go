@@ -0,0 +1,84 @@
+package chain
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/go-gost/core/metadata"
+)
+
+var (
+	errUnsupport = errors.New("unsupported operation")
+)
+
+// trackedConn is a client side Conn which calls the release function once when it is closed,
+// it is used to count the active connections through the chains and nodes.
+type trackedConn struct {
+	net.Conn
+	release func()
+	once    sync.Once
+}
+
+func trackConn(c net.Conn, release func()) net.Conn {
+	tc := &trackedConn{
+		Conn:    c,
+		release: release,
+	}
+	if pc, ok := c.(net.PacketConn); ok {
+		return &trackedPacketConn{
+			trackedConn: tc,
+			PacketConn:  pc,
+		}
+	}
+	return tc
+}
+
+func (c *trackedConn) Close() error {
+	c.once.Do(c.release)
+	return c.Conn.Close()
+}
+
+func (c *trackedConn) SyscallConn() (rc syscall.RawConn, err error) {
+	if sc, ok := c.Conn.(syscall.Conn); ok {
+		rc, err = sc.SyscallConn()
+		return
+	}
+	err = errUnsupport
+	return
+}
+
+func (c *trackedConn) Metadata() metadata.Metadata {
+	if md, ok := c.Conn.(metadata.Metadatable); ok {
+		return md.Metadata()
+	}
+	return nil
+}
+
+// trackedPacketConn is a trackedConn which is also a PacketConn.
+type trackedPacketConn struct {
+	*trackedConn
+	net.PacketConn
+}
+
+func (c *trackedPacketConn) Close() error {
+	return c.trackedConn.Close()
+}
+
+func (c *trackedPacketConn) LocalAddr() net.Addr {
+	return c.trackedConn.LocalAddr()
+}
+
+func (c *trackedPacketConn) SetDeadline(t time.Time) error {
+	return c.trackedConn.SetDeadline(t)
+}
+
+func (c *trackedPacketConn) SetReadDeadline(t time.Time) error {
+	return c.trackedConn.SetReadDeadline(t)
+}
+
+func (c *trackedPacketConn) SetWriteDeadline(t time.Time) error {
+	return c.trackedConn.SetWriteDeadline(t)
+}
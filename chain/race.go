@@ -0,0 +1,246 @@
+package chain
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"github.com/go-gost/core/chain"
+	"github.com/go-gost/core/hosts"
+	"github.com/go-gost/core/resolver"
+	xnet "github.com/go-gost/x/internal/net"
+)
+
+const (
+	// the connection attempt delay recommended by RFC 8305.
+	defaultRaceDelay = 250 * time.Millisecond
+)
+
+// racer is implemented by the hops which dial multiple candidate nodes concurrently.
+type racer interface {
+	// Race returns the number of the candidate nodes and the delay between the attempts,
+	// the count is less than 2 if racing is disabled.
+	Race() (count int, delay time.Duration)
+}
+
+type raceResult struct {
+	conn  net.Conn
+	index int
+	err   error
+}
+
+// race runs n connection attempts concurrently in the style of RFC 8305 (happy eyeballs):
+// the attempt i+1 is started after the delay since the attempt i is started, or as soon as it fails.
+// The first successful connection and its index are returned,
+// the other attempts are canceled and their connections are closed.
+// The error of the first failed attempt is returned if all the attempts fail.
+func race(ctx context.Context, n int, delay time.Duration, attempt func(ctx context.Context, i int) (net.Conn, error)) (net.Conn, int, error) {
+	if delay <= 0 {
+		delay = defaultRaceDelay
+	}
+
+	results := make(chan raceResult, n)
+	cancels := make([]context.CancelFunc, n)
+
+	next, pending := 0, 0
+	start := func() {
+		i := next
+		// the context of the winner is not canceled, as the connection may be bound to it.
+		actx, cancel := context.WithCancel(ctx)
+		cancels[i] = cancel
+		go func() {
+			conn, err := attempt(actx, i)
+			results <- raceResult{conn: conn, index: i, err: err}
+		}()
+		next++
+		pending++
+	}
+
+	start()
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	var firstErr error
+	for {
+		select {
+		case <-timer.C:
+			if next < n {
+				start()
+				timer.Reset(delay)
+			}
+
+		case res := <-results:
+			pending--
+			if res.err == nil {
+				for i, cancel := range cancels {
+					if i != res.index && cancel != nil {
+						cancel()
+					}
+				}
+				// the losers which have connected are closed.
+				go func(pending int) {
+					for ; pending > 0; pending-- {
+						if res := <-results; res.conn != nil {
+							res.conn.Close()
+						}
+					}
+				}(pending)
+				return res.conn, res.index, nil
+			}
+
+			cancels[res.index]()
+			if firstErr == nil {
+				firstErr = res.err
+			}
+			if next < n {
+				start()
+				timer.Reset(delay)
+			} else if pending == 0 {
+				return nil, 0, firstErr
+			}
+
+		case <-ctx.Done():
+			for _, cancel := range cancels {
+				if cancel != nil {
+					cancel()
+				}
+			}
+			go func(pending int) {
+				for ; pending > 0; pending-- {
+					if res := <-results; res.conn != nil {
+						res.conn.Close()
+					}
+				}
+			}(pending)
+			return nil, 0, ctx.Err()
+		}
+	}
+}
+
+var (
+	_ chain.Chainer = (*raceChain)(nil)
+)
+
+// raceChain races the addresses of the destinations dialed directly,
+// the connections routed through the nodes of the chain are not affected.
+type raceChain struct {
+	chain    chain.Chainer
+	resolver resolver.Resolver
+	hosts    hosts.HostMapper
+	count    int
+	delay    time.Duration
+}
+
+// NewRaceChain creates a chain.Chainer which races at most count addresses of the destinations
+// routed directly by the chain c (or all the destinations if c is nil), with the delay between the attempts.
+// The destinations are resolved by the host mapper and the resolver of the service,
+// all the addresses are raced if count is not positive.
+func NewRaceChain(c chain.Chainer, r resolver.Resolver, hosts hosts.HostMapper, count int, delay time.Duration) chain.Chainer {
+	return &raceChain{
+		chain:    c,
+		resolver: r,
+		hosts:    hosts,
+		count:    count,
+		delay:    delay,
+	}
+}
+
+func (c *raceChain) Route(ctx context.Context, network, address string, opts ...chain.RouteOption) chain.Route {
+	var rt chain.Route
+	if c.chain != nil {
+		rt = c.chain.Route(ctx, network, address, opts...)
+	}
+
+	var options chain.RouteOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	// the host is only known for dialing.
+	if options.Host == "" {
+		return rt
+	}
+
+	switch v := rt.(type) {
+	case nil:
+		rt = chain.DefaultRoute
+	case *route:
+		if len(v.nodes) > 0 {
+			return rt
+		}
+	default:
+		if rt != chain.DefaultRoute {
+			return rt
+		}
+	}
+	return &raceRoute{
+		Route: rt,
+		host:  options.Host,
+		chain: c,
+	}
+}
+
+// raceRoute is the route dialing the destination directly by racing its addresses.
+type raceRoute struct {
+	chain.Route
+	// the destination before resolved by the router.
+	host  string
+	chain *raceChain
+}
+
+func (r *raceRoute) Dial(ctx context.Context, network, address string, opts ...chain.DialOption) (net.Conn, error) {
+	if addrs := r.addrs(ctx, network, address); len(addrs) > 1 {
+		conn, _, err := race(ctx, len(addrs), r.chain.delay, func(ctx context.Context, i int) (net.Conn, error) {
+			return r.Route.Dial(ctx, network, addrs[i], opts...)
+		})
+		return conn, err
+	}
+	return r.Route.Dial(ctx, network, address, opts...)
+}
+
+// addrs returns the addresses of the destination host to be raced,
+// the IPv6 and IPv4 addresses are interleaved as RFC 8305 suggests.
+// It returns nil if the destination is not a TCP address of a domain or can not be resolved.
+func (r *raceRoute) addrs(ctx context.Context, network, address string) []string {
+	switch network {
+	case "tcp", "tcp4", "tcp6":
+	default:
+		return nil
+	}
+
+	host, _, _ := net.SplitHostPort(r.host)
+	_, port, err := net.SplitHostPort(address)
+	if err != nil || host == "" || net.ParseIP(host) != nil {
+		return nil
+	}
+
+	ips, err := xnet.LookupIP(ctx, "ip", host, r.chain.resolver, r.chain.hosts)
+	if err != nil {
+		return nil
+	}
+
+	var v4, v6 []string
+	for _, ip := range ips {
+		addr := net.JoinHostPort(ip.String(), port)
+		if ip.To4() != nil {
+			if network != "tcp6" {
+				v4 = append(v4, addr)
+			}
+		} else if network != "tcp4" {
+			v6 = append(v6, addr)
+		}
+	}
+
+	var addrs []string
+	for i := 0; i < len(v4) || i < len(v6); i++ {
+		if i < len(v6) {
+			addrs = append(addrs, v6[i])
+		}
+		if i < len(v4) {
+			addrs = append(addrs, v4[i])
+		}
+	}
+	if n := r.chain.count; n > 0 && len(addrs) > n {
+		addrs = addrs[:n]
+	}
+	return addrs
+}
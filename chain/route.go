@@ -10,11 +10,41 @@ import (
 	"github.com/go-gost/core/logger"
 	"github.com/go-gost/core/metrics"
 	"github.com/go-gost/core/selector"
+	"github.com/go-gost/x/internal/conntrack"
 	xmetrics "github.com/go-gost/x/metrics"
+	xselector "github.com/go-gost/x/selector"
 )
 
+var (
+	// the route without nodes and tracking.
+	directRoute chain.Route
+)
+
+func init() {
+	directRoute = chain.DefaultRoute
+	chain.DefaultRoute = &defaultRoute{Route: directRoute}
+}
+
+// defaultRoute is the route without nodes,
+// it records the destination of the connections for the connection table.
+type defaultRoute struct {
+	chain.Route
+}
+
+func (r *defaultRoute) Dial(ctx context.Context, network, address string, opts ...chain.DialOption) (net.Conn, error) {
+	conntrack.SetRoute(ctx, network, address, "", nil)
+	return r.Route.Dial(ctx, network, address, opts...)
+}
+
+func (r *defaultRoute) Bind(ctx context.Context, network, address string, opts ...chain.BindOption) (net.Listener, error) {
+	conntrack.SetRoute(ctx, network, address, "", nil)
+	return r.Route.Bind(ctx, network, address, opts...)
+}
+
 type RouteOptions struct {
 	Chain chain.Chainer
+	// the total timeout of the failover.
+	FailoverTimeout time.Duration
 }
 
 type RouteOption func(*RouteOptions)
@@ -25,9 +55,27 @@ func ChainRouteOption(c chain.Chainer) RouteOption {
 	}
 }
 
+func FailoverTimeoutRouteOption(timeout time.Duration) RouteOption {
+	return func(o *RouteOptions) {
+		o.FailoverTimeout = timeout
+	}
+}
+
+// reselectFunc selects another node of the same hop to replace the failed node,
+// it returns nil if there is no more node to try.
+type reselectFunc func(ctx context.Context) *chain.Node
+
 type route struct {
-	nodes   []*chain.Node
-	options RouteOptions
+	nodes []*chain.Node
+	// the functions to replace the nodes on failure, the element is nil if the failover is disabled.
+	reselects []reselectFunc
+	// the candidate nodes raced with the first node.
+	race      []*chain.Node
+	raceDelay time.Duration
+	options   RouteOptions
+	// the route is used by the transport of a multiplexed node to dial its sessions,
+	// the sessions are not tracked as user connections.
+	transport bool
 }
 
 func NewRoute(opts ...RouteOption) *route {
@@ -43,12 +91,16 @@ func NewRoute(opts ...RouteOption) *route {
 	}
 }
 
-func (r *route) addNode(nodes ...*chain.Node) {
-	r.nodes = append(r.nodes, nodes...)
+func (r *route) addNode(node *chain.Node, reselect reselectFunc) {
+	r.nodes = append(r.nodes, node)
+	r.reselects = append(r.reselects, reselect)
 }
 
 func (r *route) Dial(ctx context.Context, network, address string, opts ...chain.DialOption) (net.Conn, error) {
 	if len(r.Nodes()) == 0 {
+		if r.transport {
+			return directRoute.Dial(ctx, network, address, opts...)
+		}
 		return chain.DefaultRoute.Dial(ctx, network, address, opts...)
 	}
 
@@ -58,7 +110,9 @@ func (r *route) Dial(ctx context.Context, network, address string, opts ...chain
 			opt(&options)
 		}
 	}
+	// the nodes may be replaced by the failover in connect.
 	conn, err := r.connect(ctx, options.Logger)
+	r.track(ctx, network, address)
 	if err != nil {
 		return nil, err
 	}
@@ -70,11 +124,17 @@ func (r *route) Dial(ctx context.Context, network, address string, opts ...chain
 		}
 		return nil, err
 	}
-	return cc, nil
+	if r.transport {
+		return cc, nil
+	}
+	return trackConn(cc, r.acquire()), nil
 }
 
 func (r *route) Bind(ctx context.Context, network, address string, opts ...chain.BindOption) (net.Listener, error) {
 	if len(r.Nodes()) == 0 {
+		if r.transport {
+			return directRoute.Bind(ctx, network, address, opts...)
+		}
 		return chain.DefaultRoute.Bind(ctx, network, address, opts...)
 	}
 
@@ -84,8 +144,9 @@ func (r *route) Bind(ctx context.Context, network, address string, opts ...chain
 			opt(&options)
 		}
 	}
-
+	// the nodes may be replaced by the failover in connect.
 	conn, err := r.connect(ctx, options.Logger)
+	r.track(ctx, network, address)
 	if err != nil {
 		return nil, err
 	}
@@ -107,8 +168,8 @@ func (r *route) Bind(ctx context.Context, network, address string, opts ...chain
 }
 
 func (r *route) connect(ctx context.Context, logger logger.Logger) (conn net.Conn, err error) {
-	network := "ip"
 	node := r.nodes[0]
+	connectStart := time.Now()
 
 	defer func() {
 		if r.options.Chain != nil {
@@ -125,6 +186,7 @@ func (r *route) connect(ctx context.Context, logger logger.Logger) (conn net.Con
 				if marker != nil {
 					marker.Mark()
 				}
+				xselector.StateOf(r.options.Chain).ObserveError()
 				if v := xmetrics.GetCounter(xmetrics.MetricChainErrorsCounter,
 					metrics.Labels{"chain": name, "node": node.Name}); v != nil {
 					v.Inc()
@@ -133,90 +195,201 @@ func (r *route) connect(ctx context.Context, logger logger.Logger) (conn net.Con
 				if marker != nil {
 					marker.Reset()
 				}
+				xselector.StateOf(r.options.Chain).ObserveLatency(time.Since(connectStart))
 			}
 		}
 	}()
 
-	addr, err := chain.Resolve(ctx, network, node.Addr, node.Options().Resolver, node.Options().HostMapper, logger)
-	marker := node.Marker()
-	if err != nil {
-		if marker != nil {
-			marker.Mark()
-		}
-		return
+	xselector.StateOf(r.options.Chain).Trial()
+
+	if to := r.options.FailoverTimeout; to > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, to)
+		defer cancel()
 	}
 
-	start := time.Now()
-	cc, err := node.Options().Transport.Dial(ctx, addr)
-	if err != nil {
-		if marker != nil {
-			marker.Mark()
+	for {
+		var failed int
+		if conn, failed, err = r.dial(ctx, logger); err == nil {
+			return
+		}
+		if ctx.Err() != nil {
+			return
 		}
-		return
-	}
 
-	cn, err := node.Options().Transport.Handshake(ctx, cc)
-	if err != nil {
-		cc.Close()
-		if marker != nil {
-			marker.Mark()
+		reselect := r.reselects[failed]
+		if reselect == nil {
+			return
 		}
-		return
-	}
-	if marker != nil {
-		marker.Reset()
+		next := reselect(ctx)
+		if next == nil {
+			return
+		}
+		if logger != nil {
+			logger.Debugf("failover: node %s(%s) -> %s(%s): %v",
+				r.nodes[failed].Name, r.nodes[failed].Addr, next.Name, next.Addr, err)
+		}
+		// the route is dialed again from the first node, as the connection
+		// through the nodes before the failed one is usually closed by the failure.
+		r.nodes[failed] = next
 	}
+}
 
-	if r.options.Chain != nil {
-		var name string
-		if cn, _ := r.options.Chain.(chainNamer); cn != nil {
-			name = cn.Name()
+// dial connects through the nodes of the route, it returns the index of the failed node on error.
+func (r *route) dial(ctx context.Context, logger logger.Logger) (conn net.Conn, failed int, err error) {
+	network := "ip"
+
+	var cn net.Conn
+	if len(r.race) > 0 {
+		nodes := append([]*chain.Node{r.nodes[0]}, r.race...)
+		var i int
+		cn, i, err = race(ctx, len(nodes), r.raceDelay, func(ctx context.Context, i int) (net.Conn, error) {
+			return r.dialNode(ctx, nodes[i], logger)
+		})
+		// the candidates are raced only once, the failover dials the winner or the replacement.
+		r.race = nil
+		if err != nil {
+			return
 		}
-		if v := xmetrics.GetObserver(xmetrics.MetricNodeConnectDurationObserver,
-			metrics.Labels{"chain": name, "node": node.Name}); v != nil {
-			v.Observe(time.Since(start).Seconds())
+		if i > 0 && logger != nil {
+			logger.Debugf("race: node %s(%s) wins", nodes[i].Name, nodes[i].Addr)
 		}
+		r.nodes[0] = nodes[i]
+	} else if cn, err = r.dialNode(ctx, r.nodes[0], logger); err != nil {
+		return
 	}
 
-	preNode := node
-	for _, node := range r.nodes[1:] {
+	for i := 1; i < len(r.nodes); i++ {
+		failed = i
+		preNode, node := r.nodes[i-1], r.nodes[i]
 		marker := node.Marker()
-		addr, err = chain.Resolve(ctx, network, node.Addr, node.Options().Resolver, node.Options().HostMapper, logger)
-		if err != nil {
-			cn.Close()
+		fail := func() {
 			if marker != nil {
 				marker.Mark()
 			}
+			xselector.StateOf(node).ObserveError()
+		}
+
+		var addr string
+		addr, err = chain.Resolve(ctx, network, node.Addr, node.Options().Resolver, node.Options().HostMapper, logger)
+		if err != nil {
+			cn.Close()
+			fail()
 			return
 		}
+		xselector.StateOf(node).Trial()
+		start := time.Now()
+		var cc net.Conn
 		cc, err = preNode.Options().Transport.Connect(ctx, cn, "tcp", addr)
 		if err != nil {
 			cn.Close()
-			if marker != nil {
-				marker.Mark()
-			}
+			fail()
 			return
 		}
 		cc, err = node.Options().Transport.Handshake(ctx, cc)
 		if err != nil {
 			cn.Close()
-			if marker != nil {
-				marker.Mark()
-			}
+			fail()
 			return
 		}
 		if marker != nil {
 			marker.Reset()
 		}
+		xselector.StateOf(node).ObserveLatency(time.Since(start))
 
 		cn = cc
-		preNode = node
 	}
 
 	conn = cn
 	return
 }
 
+// dialNode dials and handshakes with the first node of the route.
+func (r *route) dialNode(ctx context.Context, node *chain.Node, logger logger.Logger) (net.Conn, error) {
+	marker := node.Marker()
+	fail := func() {
+		// the canceled attempts of racing are not failures of the node.
+		if ctx.Err() != nil {
+			return
+		}
+		if marker != nil {
+			marker.Mark()
+		}
+		xselector.StateOf(node).ObserveError()
+	}
+
+	addr, err := chain.Resolve(ctx, "ip", node.Addr, node.Options().Resolver, node.Options().HostMapper, logger)
+	if err != nil {
+		fail()
+		return nil, err
+	}
+
+	// the trial of the breaker is taken only by the candidate actually dialed.
+	xselector.StateOf(node).Trial()
+	start := time.Now()
+	cc, err := node.Options().Transport.Dial(ctx, addr)
+	if err != nil {
+		fail()
+		return nil, err
+	}
+
+	cn, err := node.Options().Transport.Handshake(ctx, cc)
+	if err != nil {
+		cc.Close()
+		fail()
+		return nil, err
+	}
+	if marker != nil {
+		marker.Reset()
+	}
+	xselector.StateOf(node).ObserveLatency(time.Since(start))
+
+	if r.options.Chain != nil {
+		var name string
+		if cn, _ := r.options.Chain.(chainNamer); cn != nil {
+			name = cn.Name()
+		}
+		if v := xmetrics.GetObserver(xmetrics.MetricNodeConnectDurationObserver,
+			metrics.Labels{"chain": name, "node": node.Name}); v != nil {
+			v.Observe(time.Since(start).Seconds())
+		}
+	}
+	return cn, nil
+}
+
+// acquire counts a new active connection through the chain and the nodes of the route,
+// it returns the function to release the connection.
+func (r *route) acquire() func() {
+	states := []*xselector.State{xselector.StateOf(r.options.Chain)}
+	for _, node := range r.nodes {
+		states = append(states, xselector.StateOf(node))
+	}
+	for _, st := range states {
+		st.IncConns()
+	}
+	return func() {
+		for _, st := range states {
+			st.DecConns()
+		}
+	}
+}
+
+// track records the destination and the route of the connection for the connection table.
+func (r *route) track(ctx context.Context, network, address string) {
+	if r.transport {
+		return
+	}
+	var name string
+	if cn, _ := r.options.Chain.(chainNamer); cn != nil {
+		name = cn.Name()
+	}
+	var nodes []string
+	for _, node := range r.nodes {
+		nodes = append(nodes, node.Name)
+	}
+	conntrack.SetRoute(ctx, network, address, name, nodes)
+}
+
 func (r *route) getNode(index int) *chain.Node {
 	if r == nil || len(r.Nodes()) == 0 || index < 0 || index >= len(r.Nodes()) {
 		return nil
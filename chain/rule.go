@@ -0,0 +1,67 @@
+package chain
+
+import (
+	"context"
+	"net"
+
+	"github.com/go-gost/core/chain"
+	"github.com/go-gost/x/rule"
+)
+
+var (
+	_ chain.Chainer = (*ruleChain)(nil)
+)
+
+// ruleChain picks the chain for each connection by the rules,
+// the connections not matched by any rule are routed through the default chain.
+type ruleChain struct {
+	rules rule.Rules
+	chain chain.Chainer
+}
+
+// NewRuleChain creates a chain.Chainer which consults the rules before the default chain c.
+func NewRuleChain(rules rule.Rules, c chain.Chainer) chain.Chainer {
+	return &ruleChain{
+		rules: rules,
+		chain: c,
+	}
+}
+
+func (c *ruleChain) Route(ctx context.Context, network, address string, opts ...chain.RouteOption) chain.Route {
+	var options chain.RouteOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	t := c.rules.Match(ctx, network, address, rule.HostMatchOption(options.Host))
+	if t == nil {
+		if c.chain == nil {
+			return chain.DefaultRoute
+		}
+		return c.chain.Route(ctx, network, address, opts...)
+	}
+
+	if t.Reject {
+		return rejectRoute{}
+	}
+	// the connections are dialed directly.
+	if t.Chain == nil {
+		return chain.DefaultRoute
+	}
+	return t.Chain.Route(ctx, network, address, opts...)
+}
+
+// rejectRoute is the route of the connections rejected by the rules.
+type rejectRoute struct{}
+
+func (rejectRoute) Dial(ctx context.Context, network, address string, opts ...chain.DialOption) (net.Conn, error) {
+	return nil, rule.ErrRejected
+}
+
+func (rejectRoute) Bind(ctx context.Context, network, address string, opts ...chain.BindOption) (net.Listener, error) {
+	return nil, rule.ErrRejected
+}
+
+func (rejectRoute) Nodes() []*chain.Node {
+	return nil
+}
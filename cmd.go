@@ -524,6 +524,58 @@ func buildNodeConfig(url *url.URL) (*config.NodeConfig, error) {
 	return node, nil
 }
 
+// checkCmd reports the unknown types in the schemes of the services and nodes,
+// which are silently replaced by the default ones when building the config.
+func checkCmd(services, nodes stringList) error {
+	var errs []error
+	for _, s := range services {
+		url, err := normCmd(s)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("-L %s: %w", s, err))
+			continue
+		}
+		if err := checkScheme(url.Scheme,
+			registry.HandlerRegistry().IsRegistered,
+			registry.ListenerRegistry().IsRegistered); err != nil {
+			errs = append(errs, fmt.Errorf("-L %s: %w", s, err))
+		}
+	}
+	for _, s := range nodes {
+		url, err := normCmd(s)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("-F %s: %w", s, err))
+			continue
+		}
+		if err := checkScheme(url.Scheme,
+			registry.ConnectorRegistry().IsRegistered,
+			registry.DialerRegistry().IsRegistered); err != nil {
+			errs = append(errs, fmt.Errorf("-F %s: %w", s, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// checkScheme checks the scheme in the form of "<first>+<second>" or "<first|second>".
+func checkScheme(scheme string, first, second func(string) bool) error {
+	schemes := strings.Split(scheme, "+")
+	switch len(schemes) {
+	case 1:
+		if !first(schemes[0]) && !second(schemes[0]) {
+			return fmt.Errorf("unknown type %s", schemes[0])
+		}
+	case 2:
+		if !first(schemes[0]) {
+			return fmt.Errorf("unknown type %s", schemes[0])
+		}
+		if !second(schemes[1]) {
+			return fmt.Errorf("unknown type %s", schemes[1])
+		}
+	default:
+		return fmt.Errorf("%w: invalid scheme %s", ErrInvalidCmd, scheme)
+	}
+	return nil
+}
+
 func normCmd(s string) (*url.URL, error) {
 	s = strings.TrimSpace(s)
 	if s == "" {
@@ -5,6 +5,7 @@ import (
 	"github.com/go-gost/core/service"
 	"github.com/go-gost/x/api"
 	"github.com/go-gost/x/config"
+	acl_parser "github.com/go-gost/x/config/parsing/acl"
 	admission_parser "github.com/go-gost/x/config/parsing/admission"
 	auth_parser "github.com/go-gost/x/config/parsing/auth"
 	bypass_parser "github.com/go-gost/x/config/parsing/bypass"
@@ -14,9 +15,11 @@ import (
 	ingress_parser "github.com/go-gost/x/config/parsing/ingress"
 	limiter_parser "github.com/go-gost/x/config/parsing/limiter"
 	logger_parser "github.com/go-gost/x/config/parsing/logger"
+	quota_parser "github.com/go-gost/x/config/parsing/quota"
 	recorder_parser "github.com/go-gost/x/config/parsing/recorder"
 	resolver_parser "github.com/go-gost/x/config/parsing/resolver"
 	router_parser "github.com/go-gost/x/config/parsing/router"
+	rule_parser "github.com/go-gost/x/config/parsing/rule"
 	sd_parser "github.com/go-gost/x/config/parsing/sd"
 	service_parser "github.com/go-gost/x/config/parsing/service"
 	metrics "github.com/go-gost/x/metrics/service"
@@ -98,6 +101,42 @@ func buildService(cfg *config.Config) (services []service.Service) {
 		}
 	}
 
+	for _, rulesCfg := range cfg.Rules {
+		rules, err := rule_parser.ParseRules(rulesCfg)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if rules != nil {
+			if err := registry.RulesRegistry().Register(rulesCfg.Name, rules); err != nil {
+				log.Fatal(err)
+			}
+		}
+	}
+
+	for _, aclCfg := range cfg.ACLs {
+		acl, err := acl_parser.ParseACL(aclCfg)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if acl != nil {
+			if err := registry.ACLRegistry().Register(aclCfg.Name, acl); err != nil {
+				log.Fatal(err)
+			}
+		}
+	}
+
+	for _, quotaCfg := range cfg.Quotas {
+		q, err := quota_parser.ParseQuota(quotaCfg)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if q != nil {
+			if err := registry.QuotaRegistry().Register(quotaCfg.Name, q); err != nil {
+				log.Fatal(err)
+			}
+		}
+	}
+
 	for _, sdCfg := range cfg.SDs {
 		if h := sd_parser.ParseSD(sdCfg); h != nil {
 			if err := registry.SDRegistry().Register(sdCfg.Name, h); err != nil {
@@ -89,11 +89,19 @@ type ProfilingConfig struct {
 }
 
 type APIConfig struct {
-	Addr       string      `json:"addr"`
-	PathPrefix string      `yaml:"pathPrefix,omitempty" json:"pathPrefix,omitempty"`
-	AccessLog  bool        `yaml:"accesslog,omitempty" json:"accesslog,omitempty"`
-	Auth       *AuthConfig `yaml:",omitempty" json:"auth,omitempty"`
-	Auther     string      `yaml:",omitempty" json:"auther,omitempty"`
+	Addr       string          `json:"addr"`
+	PathPrefix string          `yaml:"pathPrefix,omitempty" json:"pathPrefix,omitempty"`
+	AccessLog  bool            `yaml:"accesslog,omitempty" json:"accesslog,omitempty"`
+	Auth       *AuthConfig     `yaml:",omitempty" json:"auth,omitempty"`
+	Auther     string          `yaml:",omitempty" json:"auther,omitempty"`
+	Revision   *RevisionConfig `yaml:",omitempty" json:"revision,omitempty"`
+}
+
+type RevisionConfig struct {
+	// maximum number of revisions to keep, default is 10.
+	Max int `yaml:",omitempty" json:"max,omitempty"`
+	// directory to persist the revisions.
+	Dir string `yaml:",omitempty" json:"dir,omitempty"`
 }
 
 type MetricsConfig struct {
@@ -144,12 +152,67 @@ type AutherConfig struct {
 type AuthConfig struct {
 	Username string `json:"username"`
 	Password string `yaml:",omitempty" json:"password,omitempty"`
+	// the client ID of the user, default is the username.
+	ID string `yaml:",omitempty" json:"id,omitempty"`
+	// the groups the user belongs to.
+	Groups []string `yaml:",omitempty" json:"groups,omitempty"`
 }
 
 type SelectorConfig struct {
 	Strategy    string        `json:"strategy"`
 	MaxFails    int           `yaml:"maxFails" json:"maxFails"`
 	FailTimeout time.Duration `yaml:"failTimeout" json:"failTimeout"`
+	// hash key of the hash strategies: ip, client, host, sni, header:<name> or cookie:<name>,
+	// default is the hash source set by the service and handlers.
+	HashKey string `yaml:"hashKey,omitempty" json:"hashKey,omitempty"`
+	// the circuit breaker replaces the fail count based filtering if it is set.
+	CircuitBreaker *CircuitBreakerConfig `yaml:"circuitBreaker,omitempty" json:"circuitBreaker,omitempty"`
+}
+
+// CircuitBreakerConfig is the circuit breaker of the nodes or chains of a selector.
+// The breaker of an object is opened when the failure rate of its connections in the window is too high,
+// the open object is filtered out for the open timeout,
+// then a few trial connections are let through to decide whether to close or reopen the breaker.
+type CircuitBreakerConfig struct {
+	// the sliding window of the connection outcomes, default is 60s.
+	Window time.Duration `yaml:",omitempty" json:"window,omitempty"`
+	// the minimum number of the connections in the window to open the breaker, default is 10.
+	MinRequests int `yaml:"minRequests,omitempty" json:"minRequests,omitempty"`
+	// the failure rate in the window to open the breaker, in the range (0, 1], default is 0.5.
+	FailureRate float64 `yaml:"failureRate,omitempty" json:"failureRate,omitempty"`
+	// how long the breaker stays open before the trial connections, default is 30s.
+	OpenTimeout time.Duration `yaml:"openTimeout,omitempty" json:"openTimeout,omitempty"`
+	// the number of the successful trial connections to close the breaker, default is 1.
+	HalfOpenTrials int `yaml:"halfOpenTrials,omitempty" json:"halfOpenTrials,omitempty"`
+}
+
+// HealthCheckConfig is the active health checking of the nodes in a hop or forwarder.
+type HealthCheckConfig struct {
+	// probe type: tcp (default), tls, http or dial.
+	Type string `json:"type"`
+	// interval between the probes, default is 10s.
+	Interval time.Duration `yaml:",omitempty" json:"interval,omitempty"`
+	// timeout of a probe, default is 5s.
+	Timeout time.Duration `yaml:",omitempty" json:"timeout,omitempty"`
+	// the number of consecutive successful probes required to re-admit a failed node, default is 1.
+	Rise int `yaml:",omitempty" json:"rise,omitempty"`
+	// URL path of the http probe, default is /.
+	Path string `yaml:",omitempty" json:"path,omitempty"`
+	// expected response status code of the http probe, default is any status below 400.
+	Status int `yaml:",omitempty" json:"status,omitempty"`
+	// target address connected through the node by the dial probe.
+	Target string `yaml:",omitempty" json:"target,omitempty"`
+}
+
+// RaceConfig is the racing of the candidate nodes in the first hop of a chain,
+// or the addresses of the destinations dialed directly by a service (happy eyeballs).
+// The candidates are dialed concurrently and the first one completing the handshake is used.
+type RaceConfig struct {
+	// the number of the candidate nodes, default is 2,
+	// or the number of the destination addresses, default is all.
+	Count int `yaml:",omitempty" json:"count,omitempty"`
+	// the delay between the connection attempts, default is 250ms.
+	Delay time.Duration `yaml:",omitempty" json:"delay,omitempty"`
 }
 
 type AdmissionConfig struct {
@@ -178,6 +241,44 @@ type BypassConfig struct {
 	Plugin    *PluginConfig `yaml:",omitempty" json:"plugin,omitempty"`
 }
 
+// ACLConfig is the access control list of the destinations of the clients.
+type ACLConfig struct {
+	Name string `json:"name"`
+	// the rules in the format: <subject> allow|deny [<matcher>...],
+	// the subject is user:<client ID>, group:<group> or * for all the clients,
+	// the matchers are hosts, IPs, CIDRs, ports (:443) or port ranges (:8000-9000), and networks (tcp or udp).
+	Rules  []string      `yaml:",omitempty" json:"rules,omitempty"`
+	Reload time.Duration `yaml:",omitempty" json:"reload,omitempty"`
+	File   *FileLoader   `yaml:",omitempty" json:"file,omitempty"`
+	Redis  *RedisLoader  `yaml:",omitempty" json:"redis,omitempty"`
+	HTTP   *HTTPLoader   `yaml:"http,omitempty" json:"http,omitempty"`
+}
+
+// QuotaConfig is the quota of the total bytes transferred by each client in a period.
+type QuotaConfig struct {
+	Name string `json:"name"`
+	// the period to reset the usage, daily or monthly, the usage is never reset if it is empty.
+	Period string `yaml:",omitempty" json:"period,omitempty"`
+	// the limits in the format: <subject> <bytes> [hard|soft],
+	// the subject is user:<client ID>, group:<group> or * for all the clients.
+	Limits []string `yaml:",omitempty" json:"limits,omitempty"`
+	// cut the existing connections of the clients exhausted their hard limits.
+	Cut    bool              `yaml:",omitempty" json:"cut,omitempty"`
+	Store  *QuotaStoreConfig `yaml:",omitempty" json:"store,omitempty"`
+	Reload time.Duration     `yaml:",omitempty" json:"reload,omitempty"`
+	File   *FileLoader       `yaml:",omitempty" json:"file,omitempty"`
+	Redis  *RedisLoader      `yaml:",omitempty" json:"redis,omitempty"`
+	HTTP   *HTTPLoader       `yaml:"http,omitempty" json:"http,omitempty"`
+}
+
+// QuotaStoreConfig is the store to persist the usage of the quota.
+type QuotaStoreConfig struct {
+	File  *FileLoader  `yaml:",omitempty" json:"file,omitempty"`
+	Redis *RedisLoader `yaml:",omitempty" json:"redis,omitempty"`
+	// the interval to save the usage, default is 10s.
+	Interval time.Duration `yaml:",omitempty" json:"interval,omitempty"`
+}
+
 type FileLoader struct {
 	Path string `json:"path"`
 }
@@ -249,6 +350,38 @@ type SDConfig struct {
 	Plugin *PluginConfig `yaml:",omitempty" json:"plugin,omitempty"`
 }
 
+// RulesConfig is an ordered list of rules to pick the chain for each connection,
+// the first matched rule is used.
+type RulesConfig struct {
+	Name  string        `json:"name"`
+	Rules []*RuleConfig `yaml:",omitempty" json:"rules,omitempty"`
+}
+
+// RuleConfig is a rule of the rule list.
+// The rule is matched if all the specified matchers are matched,
+// the rule without matchers matches all the connections.
+// The matched connections are routed through the chain or the chain group,
+// rejected if reject is true, or dialed directly if none of them is set.
+type RuleConfig struct {
+	Name      string   `yaml:",omitempty" json:"name,omitempty"`
+	Domains   []string `yaml:",omitempty" json:"domains,omitempty"`
+	Wildcards []string `yaml:",omitempty" json:"wildcards,omitempty"`
+	CIDRs     []string `yaml:"cidrs,omitempty" json:"cidrs,omitempty"`
+	Ports     []string `yaml:",omitempty" json:"ports,omitempty"`
+	Networks  []string `yaml:",omitempty" json:"networks,omitempty"`
+	// client IDs of the authenticated clients.
+	Clients []string `yaml:",omitempty" json:"clients,omitempty"`
+	// sniffed protocols, such as http, tls and ssh.
+	// The protocol is only known by the forward handlers with sniffing enabled, the sni handler,
+	// and the http handler for the plain HTTP requests, the rule with protocols
+	// never matches the other connections, such as the tunnels of socks, relay and HTTP CONNECT.
+	Protocols []string `yaml:",omitempty" json:"protocols,omitempty"`
+
+	Chain      string            `yaml:",omitempty" json:"chain,omitempty"`
+	ChainGroup *ChainGroupConfig `yaml:"chainGroup,omitempty" json:"chainGroup,omitempty"`
+	Reject     bool              `yaml:",omitempty" json:"reject,omitempty"`
+}
+
 type RouterRouteConfig struct {
 	Net     string `json:"net"`
 	Gateway string `json:"gateway"`
@@ -328,18 +461,24 @@ type HandlerConfig struct {
 	Retries    int               `yaml:",omitempty" json:"retries,omitempty"`
 	Chain      string            `yaml:",omitempty" json:"chain,omitempty"`
 	ChainGroup *ChainGroupConfig `yaml:"chainGroup,omitempty" json:"chainGroup,omitempty"`
+	Rules      string            `yaml:",omitempty" json:"rules,omitempty"`
 	Auther     string            `yaml:",omitempty" json:"auther,omitempty"`
 	Authers    []string          `yaml:",omitempty" json:"authers,omitempty"`
 	Auth       *AuthConfig       `yaml:",omitempty" json:"auth,omitempty"`
-	TLS        *TLSConfig        `yaml:",omitempty" json:"tls,omitempty"`
-	Limiter    string            `yaml:",omitempty" json:"limiter,omitempty"`
-	Metadata   map[string]any    `yaml:",omitempty" json:"metadata,omitempty"`
+	// the ACL of the destinations of the authenticated clients.
+	ACL string `yaml:"acl,omitempty" json:"acl,omitempty"`
+	// the quota of the traffic of the authenticated clients.
+	Quota    string         `yaml:",omitempty" json:"quota,omitempty"`
+	TLS      *TLSConfig     `yaml:",omitempty" json:"tls,omitempty"`
+	Limiter  string         `yaml:",omitempty" json:"limiter,omitempty"`
+	Metadata map[string]any `yaml:",omitempty" json:"metadata,omitempty"`
 }
 
 type ForwarderConfig struct {
-	Name     string               `yaml:",omitempty" json:"name,omitempty"`
-	Selector *SelectorConfig      `yaml:",omitempty" json:"selector,omitempty"`
-	Nodes    []*ForwardNodeConfig `json:"nodes"`
+	Name        string               `yaml:",omitempty" json:"name,omitempty"`
+	Selector    *SelectorConfig      `yaml:",omitempty" json:"selector,omitempty"`
+	HealthCheck *HealthCheckConfig   `yaml:"healthCheck,omitempty" json:"healthCheck,omitempty"`
+	Nodes       []*ForwardNodeConfig `json:"nodes"`
 }
 
 type ForwardNodeConfig struct {
@@ -407,12 +546,25 @@ type ServiceConfig struct {
 	Listener   *ListenerConfig   `yaml:",omitempty" json:"listener,omitempty"`
 	Forwarder  *ForwarderConfig  `yaml:",omitempty" json:"forwarder,omitempty"`
 	Metadata   map[string]any    `yaml:",omitempty" json:"metadata,omitempty"`
+	// the racing of the addresses of the destinations dialed directly,
+	// the addresses are resolved by the hosts and the resolver of the service.
+	Race *RaceConfig `yaml:",omitempty" json:"race,omitempty"`
 }
 
 type ChainConfig struct {
-	Name     string         `json:"name"`
-	Hops     []*HopConfig   `json:"hops"`
-	Metadata map[string]any `yaml:",omitempty" json:"metadata,omitempty"`
+	Name     string          `json:"name"`
+	Hops     []*HopConfig    `json:"hops"`
+	Failover *FailoverConfig `yaml:",omitempty" json:"failover,omitempty"`
+	Metadata map[string]any  `yaml:",omitempty" json:"metadata,omitempty"`
+}
+
+// FailoverConfig is the failover of a chain dial,
+// a failed node is replaced by another node of the same hop.
+type FailoverConfig struct {
+	// the maximum number of the nodes tried for each hop, default is 3.
+	Attempts int `yaml:",omitempty" json:"attempts,omitempty"`
+	// the total timeout of the dial including the failover, default is 30s.
+	Timeout time.Duration `yaml:",omitempty" json:"timeout,omitempty"`
 }
 
 type ChainGroupConfig struct {
@@ -421,20 +573,22 @@ type ChainGroupConfig struct {
 }
 
 type HopConfig struct {
-	Name      string          `json:"name"`
-	Interface string          `yaml:",omitempty" json:"interface,omitempty"`
-	SockOpts  *SockOptsConfig `yaml:"sockopts,omitempty" json:"sockopts,omitempty"`
-	Selector  *SelectorConfig `yaml:",omitempty" json:"selector,omitempty"`
-	Bypass    string          `yaml:",omitempty" json:"bypass,omitempty"`
-	Bypasses  []string        `yaml:",omitempty" json:"bypasses,omitempty"`
-	Resolver  string          `yaml:",omitempty" json:"resolver,omitempty"`
-	Hosts     string          `yaml:",omitempty" json:"hosts,omitempty"`
-	Nodes     []*NodeConfig   `yaml:",omitempty" json:"nodes,omitempty"`
-	Reload    time.Duration   `yaml:",omitempty" json:"reload,omitempty"`
-	File      *FileLoader     `yaml:",omitempty" json:"file,omitempty"`
-	Redis     *RedisLoader    `yaml:",omitempty" json:"redis,omitempty"`
-	HTTP      *HTTPLoader     `yaml:"http,omitempty" json:"http,omitempty"`
-	Plugin    *PluginConfig   `yaml:",omitempty" json:"plugin,omitempty"`
+	Name        string             `json:"name"`
+	Interface   string             `yaml:",omitempty" json:"interface,omitempty"`
+	SockOpts    *SockOptsConfig    `yaml:"sockopts,omitempty" json:"sockopts,omitempty"`
+	Selector    *SelectorConfig    `yaml:",omitempty" json:"selector,omitempty"`
+	HealthCheck *HealthCheckConfig `yaml:"healthCheck,omitempty" json:"healthCheck,omitempty"`
+	Race        *RaceConfig        `yaml:",omitempty" json:"race,omitempty"`
+	Bypass      string             `yaml:",omitempty" json:"bypass,omitempty"`
+	Bypasses    []string           `yaml:",omitempty" json:"bypasses,omitempty"`
+	Resolver    string             `yaml:",omitempty" json:"resolver,omitempty"`
+	Hosts       string             `yaml:",omitempty" json:"hosts,omitempty"`
+	Nodes       []*NodeConfig      `yaml:",omitempty" json:"nodes,omitempty"`
+	Reload      time.Duration      `yaml:",omitempty" json:"reload,omitempty"`
+	File        *FileLoader        `yaml:",omitempty" json:"file,omitempty"`
+	Redis       *RedisLoader       `yaml:",omitempty" json:"redis,omitempty"`
+	HTTP        *HTTPLoader        `yaml:"http,omitempty" json:"http,omitempty"`
+	Plugin      *PluginConfig      `yaml:",omitempty" json:"plugin,omitempty"`
 }
 
 type NodeConfig struct {
@@ -469,6 +623,9 @@ type Config struct {
 	Hosts      []*HostsConfig     `yaml:",omitempty" json:"hosts,omitempty"`
 	Ingresses  []*IngressConfig   `yaml:",omitempty" json:"ingresses,omitempty"`
 	Routers    []*RouterConfig    `yaml:",omitempty" json:"routers,omitempty"`
+	Rules      []*RulesConfig     `yaml:",omitempty" json:"rules,omitempty"`
+	ACLs       []*ACLConfig       `yaml:"acls,omitempty" json:"acls,omitempty"`
+	Quotas     []*QuotaConfig     `yaml:",omitempty" json:"quotas,omitempty"`
 	SDs        []*SDConfig        `yaml:"sds,omitempty" json:"sds,omitempty"`
 	Recorders  []*RecorderConfig  `yaml:",omitempty" json:"recorders,omitempty"`
 	Limiters   []*LimiterConfig   `yaml:",omitempty" json:"limiters,omitempty"`
@@ -480,6 +637,9 @@ type Config struct {
 	Profiling  *ProfilingConfig   `yaml:",omitempty" json:"profiling,omitempty"`
 	API        *APIConfig         `yaml:",omitempty" json:"api,omitempty"`
 	Metrics    *MetricsConfig     `yaml:",omitempty" json:"metrics,omitempty"`
+
+	// the config files and include patterns read by ReadFile.
+	sources []string
 }
 
 func (c *Config) Load() error {
@@ -487,7 +647,7 @@ func (c *Config) Load() error {
 		return err
 	}
 
-	return v.Unmarshal(c)
+	return c.ReadFile(v.ConfigFileUsed())
 }
 
 func (c *Config) Read(r io.Reader) error {
@@ -498,12 +658,33 @@ func (c *Config) Read(r io.Reader) error {
 	return v.Unmarshal(c)
 }
 
+// ReadFile reads the config from file, along with the files it includes.
+// The files listed (as glob patterns) in the include section are read in order,
+// with the paths relative to the directory of the including file.
+// The objects of the included files are appended to the including one,
+// the duplicate object names are treated as errors.
+// The tls, log, api, metrics and profiling sections are taken
+// from the first file which defines them, in the order of reading.
+// The environment variables in the form of ${NAME} or ${NAME:-default}
+// are expanded in all the files before parsing.
 func (c *Config) ReadFile(file string) error {
-	v.SetConfigFile(file)
-	if err := v.ReadInConfig(); err != nil {
+	r := &fileReader{
+		reading: make(map[string]bool),
+	}
+	cfg, err := r.read(file)
+	if err != nil {
 		return err
 	}
-	return v.Unmarshal(c)
+	cfg.sources = r.sources
+
+	*c = *cfg
+	return nil
+}
+
+// Sources returns the absolute paths of the config files read by ReadFile,
+// and the glob patterns of the included files.
+func (c *Config) Sources() []string {
+	return c.sources
 }
 
 func (c *Config) Write(w io.Writer, format string) error {
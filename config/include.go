@@ -0,0 +1,215 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	includeKey = "include"
+)
+
+var (
+	envPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+)
+
+type fileReader struct {
+	// the files being read, used to detect include cycles.
+	reading map[string]bool
+	sources []string
+}
+
+func (r *fileReader) read(file string) (*Config, error) {
+	file, err := filepath.Abs(file)
+	if err != nil {
+		return nil, err
+	}
+	if r.reading[file] {
+		return nil, fmt.Errorf("%s: include cycle", file)
+	}
+	r.reading[file] = true
+	defer delete(r.reading, file)
+
+	r.sources = append(r.sources, file)
+
+	cfg, includes, err := readFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, pattern := range includes {
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(filepath.Dir(file), pattern)
+		}
+		r.sources = append(r.sources, pattern)
+
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("%s: include %s: %w", file, pattern, err)
+		}
+		// a plain file path must exist.
+		if len(matches) == 0 && !hasMeta(pattern) {
+			matches = append(matches, pattern)
+		}
+
+		for _, match := range matches {
+			if fi, err := os.Stat(match); err == nil && fi.IsDir() {
+				continue
+			}
+			inc, err := r.read(match)
+			if err != nil {
+				return nil, err
+			}
+			if err := cfg.merge(inc); err != nil {
+				return nil, fmt.Errorf("%s: %w", match, err)
+			}
+		}
+	}
+
+	return cfg, nil
+}
+
+// readFile reads a single config file, it returns the config and the include patterns.
+func readFile(file string) (*Config, []string, error) {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return nil, nil, err
+	}
+	vp := viper.New()
+	typ := strings.TrimPrefix(filepath.Ext(file), ".")
+	if typ == "" {
+		typ = "yaml"
+	}
+	vp.SetConfigType(typ)
+	if err := vp.ReadConfig(bytes.NewReader(b)); err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", file, err)
+	}
+
+	// the variables are expanded in the parsed string values,
+	// so the values can not change the structure of the config.
+	var errs []error
+	settings := expandEnv(vp.AllSettings(), &errs).(map[string]any)
+	if err := errors.Join(errs...); err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", file, err)
+	}
+	vp = viper.New()
+	if err := vp.MergeConfigMap(settings); err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", file, err)
+	}
+
+	cfg := &Config{}
+	if err := vp.Unmarshal(cfg); err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", file, err)
+	}
+	return cfg, vp.GetStringSlice(includeKey), nil
+}
+
+// expandEnv replaces ${NAME} and ${NAME:-default} in the string values of v
+// with the value of the environment variable NAME, v is modified in place.
+// The default value is used if NAME is unset or empty, it is an error if NAME is unset and has no default value.
+func expandEnv(v any, errs *[]error) any {
+	switch v := v.(type) {
+	case string:
+		return envPattern.ReplaceAllStringFunc(v, func(s string) string {
+			m := envPattern.FindStringSubmatch(s)
+			name := m[1]
+			value, ok := os.LookupEnv(name)
+			if m[2] != "" {
+				if value == "" {
+					return m[3]
+				}
+				return value
+			}
+			if !ok {
+				*errs = append(*errs, fmt.Errorf("environment variable %s is not set", name))
+			}
+			return value
+		})
+	case map[string]any:
+		for k, e := range v {
+			v[k] = expandEnv(e, errs)
+		}
+	case map[any]any:
+		for k, e := range v {
+			v[k] = expandEnv(e, errs)
+		}
+	case []any:
+		for i, e := range v {
+			v[i] = expandEnv(e, errs)
+		}
+	}
+	return v
+}
+
+// merge appends the objects of the config o to c.
+func (c *Config) merge(o *Config) error {
+	var errs []error
+	c.Services, errs = mergeList("service", c.Services, o.Services, errs, func(c *ServiceConfig) string { return c.Name })
+	c.Chains, errs = mergeList("chain", c.Chains, o.Chains, errs, func(c *ChainConfig) string { return c.Name })
+	c.Hops, errs = mergeList("hop", c.Hops, o.Hops, errs, func(c *HopConfig) string { return c.Name })
+	c.Authers, errs = mergeList("auther", c.Authers, o.Authers, errs, func(c *AutherConfig) string { return c.Name })
+	c.Admissions, errs = mergeList("admission", c.Admissions, o.Admissions, errs, func(c *AdmissionConfig) string { return c.Name })
+	c.Bypasses, errs = mergeList("bypass", c.Bypasses, o.Bypasses, errs, func(c *BypassConfig) string { return c.Name })
+	c.Resolvers, errs = mergeList("resolver", c.Resolvers, o.Resolvers, errs, func(c *ResolverConfig) string { return c.Name })
+	c.Hosts, errs = mergeList("hosts", c.Hosts, o.Hosts, errs, func(c *HostsConfig) string { return c.Name })
+	c.Ingresses, errs = mergeList("ingress", c.Ingresses, o.Ingresses, errs, func(c *IngressConfig) string { return c.Name })
+	c.Routers, errs = mergeList("router", c.Routers, o.Routers, errs, func(c *RouterConfig) string { return c.Name })
+	c.Rules, errs = mergeList("rules", c.Rules, o.Rules, errs, func(c *RulesConfig) string { return c.Name })
+	c.ACLs, errs = mergeList("acl", c.ACLs, o.ACLs, errs, func(c *ACLConfig) string { return c.Name })
+	c.Quotas, errs = mergeList("quota", c.Quotas, o.Quotas, errs, func(c *QuotaConfig) string { return c.Name })
+	c.SDs, errs = mergeList("sd", c.SDs, o.SDs, errs, func(c *SDConfig) string { return c.Name })
+	c.Recorders, errs = mergeList("recorder", c.Recorders, o.Recorders, errs, func(c *RecorderConfig) string { return c.Name })
+	c.Limiters, errs = mergeList("limiter", c.Limiters, o.Limiters, errs, func(c *LimiterConfig) string { return c.Name })
+	c.CLimiters, errs = mergeList("climiter", c.CLimiters, o.CLimiters, errs, func(c *LimiterConfig) string { return c.Name })
+	c.RLimiters, errs = mergeList("rlimiter", c.RLimiters, o.RLimiters, errs, func(c *LimiterConfig) string { return c.Name })
+	c.Loggers, errs = mergeList("logger", c.Loggers, o.Loggers, errs, func(c *LoggerConfig) string { return c.Name })
+
+	if c.TLS == nil {
+		c.TLS = o.TLS
+	}
+	if c.Log == nil {
+		c.Log = o.Log
+	}
+	if c.Profiling == nil {
+		c.Profiling = o.Profiling
+	}
+	if c.API == nil {
+		c.API = o.API
+	}
+	if c.Metrics == nil {
+		c.Metrics = o.Metrics
+	}
+
+	return errors.Join(errs...)
+}
+
+func mergeList[T any](kind string, dst, src []*T, errs []error, name func(*T) string) ([]*T, []error) {
+	names := make(map[string]bool)
+	for _, v := range dst {
+		if v != nil {
+			names[name(v)] = true
+		}
+	}
+	for _, v := range src {
+		if v == nil {
+			continue
+		}
+		if n := name(v); n != "" && names[n] {
+			errs = append(errs, fmt.Errorf("duplicate %s name: %s", kind, n))
+			continue
+		}
+		dst = append(dst, v)
+	}
+	return dst, errs
+}
+
+func hasMeta(path string) bool {
+	return strings.ContainsAny(path, "*?[")
+}
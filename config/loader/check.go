@@ -0,0 +1,657 @@
+package loader
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	xacl "github.com/go-gost/x/acl"
+	"github.com/go-gost/x/config"
+	hop_parser "github.com/go-gost/x/config/parsing/hop"
+	node_parser "github.com/go-gost/x/config/parsing/node"
+	resolver_parser "github.com/go-gost/x/config/parsing/resolver"
+	rule_parser "github.com/go-gost/x/config/parsing/rule"
+	tls_util "github.com/go-gost/x/internal/util/tls"
+	xconn "github.com/go-gost/x/limiter/conn"
+	xrate "github.com/go-gost/x/limiter/rate"
+	xtraffic "github.com/go-gost/x/limiter/traffic"
+	xquota "github.com/go-gost/x/quota"
+	"github.com/go-gost/x/registry"
+	xs "github.com/go-gost/x/selector"
+)
+
+// Check validates the config cfg without applying it,
+// and reports all the problems found at once:
+// unregistered types, undefined or duplicate object names,
+// invalid limits, unresolvable secret references and unloadable TLS files.
+// The objects which can be parsed without side effects
+// (hops, chains and resolvers) are parsed and discarded,
+// services are checked statically, as parsing them binds the listening ports.
+// The config cfg is left untouched.
+func Check(cfg *config.Config) error {
+	if cfg == nil {
+		return nil
+	}
+
+	cfg, err := copyConfig(cfg)
+	if err != nil {
+		return err
+	}
+	return check(cfg, cfg)
+}
+
+// CheckChanges validates the objects of the config cfg changed from prev the same way as Check,
+// along with the objects they reference and the ones referencing the removed objects,
+// so the problems of the other objects do not fail the changes.
+func CheckChanges(prev, cfg *config.Config) error {
+	if cfg == nil {
+		return nil
+	}
+	if prev == nil {
+		return Check(cfg)
+	}
+
+	cfg, err := copyConfig(cfg)
+	if err != nil {
+		return err
+	}
+	return check(cfg, changedConfig(prev, cfg))
+}
+
+// copyConfig returns a deep copy of cfg, as the parsers fill in default values to the configs they parsed.
+func copyConfig(cfg *config.Config) (*config.Config, error) {
+	b, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, err
+	}
+	c := &config.Config{}
+	if err := json.Unmarshal(b, c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// check validates the objects in the config sub, the names are resolved in the config cfg.
+func check(cfg, sub *config.Config) error {
+	c := &checker{
+		names: make(map[string]map[string]bool),
+	}
+
+	c.define("logger", names(cfg.Loggers, func(c *config.LoggerConfig) string { return c.Name }))
+	c.define("auther", names(cfg.Authers, func(c *config.AutherConfig) string { return c.Name }))
+	c.define("admission", names(cfg.Admissions, func(c *config.AdmissionConfig) string { return c.Name }))
+	c.define("bypass", names(cfg.Bypasses, func(c *config.BypassConfig) string { return c.Name }))
+	c.define("resolver", names(cfg.Resolvers, func(c *config.ResolverConfig) string { return c.Name }))
+	c.define("hosts", names(cfg.Hosts, func(c *config.HostsConfig) string { return c.Name }))
+	c.define("ingress", names(cfg.Ingresses, func(c *config.IngressConfig) string { return c.Name }))
+	c.define("router", names(cfg.Routers, func(c *config.RouterConfig) string { return c.Name }))
+	c.define("rules", names(cfg.Rules, func(c *config.RulesConfig) string { return c.Name }))
+	c.define("acl", names(cfg.ACLs, func(c *config.ACLConfig) string { return c.Name }))
+	c.define("quota", names(cfg.Quotas, func(c *config.QuotaConfig) string { return c.Name }))
+	c.define("sd", names(cfg.SDs, func(c *config.SDConfig) string { return c.Name }))
+	c.define("recorder", names(cfg.Recorders, func(c *config.RecorderConfig) string { return c.Name }))
+	c.define("limiter", names(cfg.Limiters, func(c *config.LimiterConfig) string { return c.Name }))
+	c.define("climiter", names(cfg.CLimiters, func(c *config.LimiterConfig) string { return c.Name }))
+	c.define("rlimiter", names(cfg.RLimiters, func(c *config.LimiterConfig) string { return c.Name }))
+	c.define("hop", names(cfg.Hops, func(c *config.HopConfig) string { return c.Name }))
+	c.define("chain", names(cfg.Chains, func(c *config.ChainConfig) string { return c.Name }))
+	c.define("service", names(cfg.Services, func(c *config.ServiceConfig) string { return c.Name }))
+
+	for _, rc := range sub.Resolvers {
+		if rc == nil {
+			continue
+		}
+		for _, ns := range rc.Nameservers {
+			if ns != nil {
+				c.ref("resolver "+rc.Name, "chain", ns.Chain)
+			}
+		}
+		r, err := resolver_parser.ParseResolver(rc)
+		if err != nil {
+			c.errorf("resolver %s: %v", rc.Name, err)
+		}
+		discard(r)
+	}
+
+	for _, rc := range sub.Rules {
+		if rc == nil {
+			continue
+		}
+		for _, r := range rc.Rules {
+			if r == nil {
+				continue
+			}
+			owner := fmt.Sprintf("rules %s: rule %s", rc.Name, r.Name)
+			c.ref(owner, "chain", r.Chain)
+			if r.ChainGroup != nil {
+				c.ref(owner, "chain", r.ChainGroup.Chains...)
+				c.checkSelector(owner, r.ChainGroup.Selector)
+			}
+		}
+		if _, err := rule_parser.ParseRules(rc); err != nil {
+			c.errorf("rules %s: %v", rc.Name, err)
+		}
+	}
+
+	for _, ac := range sub.ACLs {
+		if ac == nil {
+			continue
+		}
+		for _, s := range ac.Rules {
+			if _, err := xacl.ParseRule(s); err != nil {
+				c.errorf("acl %s: %v", ac.Name, err)
+			}
+		}
+	}
+
+	for _, qc := range sub.Quotas {
+		if qc == nil {
+			continue
+		}
+		if !xquota.ValidPeriod(qc.Period) {
+			c.errorf("quota %s: invalid period: %s", qc.Name, qc.Period)
+		}
+		for _, s := range qc.Limits {
+			if err := xquota.ValidateLimit(s); err != nil {
+				c.errorf("quota %s: %v", qc.Name, err)
+			}
+		}
+	}
+
+	for _, lc := range sub.Limiters {
+		c.checkLimits("limiter", lc, xtraffic.ValidateLimit)
+	}
+	for _, lc := range sub.CLimiters {
+		c.checkLimits("climiter", lc, xconn.ValidateLimit)
+	}
+	for _, lc := range sub.RLimiters {
+		c.checkLimits("rlimiter", lc, xrate.ValidateLimit)
+	}
+
+	for _, hc := range sub.Hops {
+		if hc != nil {
+			c.checkHop("hop "+hc.Name, hc)
+		}
+	}
+
+	for _, cc := range sub.Chains {
+		if cc == nil {
+			continue
+		}
+		for i, hc := range cc.Hops {
+			if hc == nil {
+				continue
+			}
+			// only the nodes of the first hop are raced,
+			// as the other nodes are connected through the nodes before them.
+			if i > 0 && hopOf(cfg, hc).Race != nil {
+				c.errorf("chain %s: hop %s: race is only supported by the first hop", cc.Name, hc.Name)
+			}
+			if hc.Nodes == nil && hc.Plugin == nil {
+				c.ref("chain "+cc.Name, "hop", hc.Name)
+				continue
+			}
+			c.checkHop(fmt.Sprintf("chain %s: hop %s", cc.Name, hc.Name), hc)
+		}
+	}
+
+	for _, sc := range sub.Services {
+		if sc != nil {
+			c.checkService(sc)
+		}
+	}
+
+	if sub.TLS != nil && sub.TLS.CertFile != "" {
+		if _, err := tls_util.LoadDefaultConfig(sub.TLS.CertFile, sub.TLS.KeyFile, sub.TLS.CAFile); err != nil {
+			c.errorf("tls: %v", err)
+		}
+	}
+	if err := sub.CheckSecrets(); err != nil {
+		c.errs = append(c.errs, err)
+	}
+	if sub.API != nil {
+		c.ref("api", "auther", sub.API.Auther)
+	}
+	if sub.Metrics != nil {
+		c.ref("metrics", "auther", sub.Metrics.Auther)
+	}
+
+	return errors.Join(c.errs...)
+}
+
+// hopOf returns the hop defined in the config cfg if the hop hc of a chain refers to it by name.
+func hopOf(cfg *config.Config, hc *config.HopConfig) *config.HopConfig {
+	if hc.Nodes != nil || hc.Plugin != nil {
+		return hc
+	}
+	for _, v := range cfg.Hops {
+		if v != nil && v.Name == hc.Name {
+			return v
+		}
+	}
+	return hc
+}
+
+type checker struct {
+	// defined object names by kind.
+	names map[string]map[string]bool
+	errs  []error
+}
+
+func (c *checker) errorf(format string, args ...any) {
+	c.errs = append(c.errs, fmt.Errorf(format, args...))
+}
+
+func (c *checker) define(kind string, names []string) {
+	m := make(map[string]bool)
+	for _, name := range names {
+		if name == "" {
+			continue
+		}
+		if m[name] {
+			c.errorf("%s %s: duplicate name", kind, name)
+		}
+		m[name] = true
+	}
+	c.names[kind] = m
+}
+
+// ref checks that the object name of kind referenced by owner is defined.
+func (c *checker) ref(owner string, kind string, names ...string) {
+	for _, name := range names {
+		if name != "" && !c.names[kind][name] {
+			c.errorf("%s: %s %s is not defined", owner, kind, name)
+		}
+	}
+}
+
+func (c *checker) checkLimits(kind string, cfg *config.LimiterConfig, validate func(string) error) {
+	if cfg == nil {
+		return
+	}
+	for _, s := range cfg.Limits {
+		if err := validate(s); err != nil {
+			c.errorf("%s %s: %v", kind, cfg.Name, err)
+		}
+	}
+}
+
+func (c *checker) checkHop(owner string, cfg *config.HopConfig) {
+	c.ref(owner, "bypass", append([]string{cfg.Bypass}, cfg.Bypasses...)...)
+	c.ref(owner, "resolver", cfg.Resolver)
+	c.ref(owner, "hosts", cfg.Hosts)
+	c.checkSelector(owner, cfg.Selector)
+	c.checkHealthCheck(owner, cfg.HealthCheck)
+
+	if cfg.Plugin != nil {
+		h, err := hop_parser.ParseHop(cfg)
+		if err != nil {
+			c.errorf("%s: %v", owner, err)
+		}
+		discard(h)
+		return
+	}
+
+	// the nodes are parsed one by one to collect all the errors,
+	// as the hop parser stops at the first failed node.
+	for _, nc := range cfg.Nodes {
+		if nc == nil {
+			continue
+		}
+		nodeOwner := fmt.Sprintf("%s: node %s", owner, nc.Name)
+		c.ref(nodeOwner, "bypass", append([]string{nc.Bypass}, nc.Bypasses...)...)
+		c.ref(nodeOwner, "resolver", nc.Resolver)
+		c.ref(nodeOwner, "hosts", nc.Hosts)
+
+		node, err := node_parser.ParseNode(cfg.Name, nc)
+		if err != nil {
+			c.errorf("%s: %v", nodeOwner, err)
+		}
+		node_parser.Close(node)
+	}
+}
+
+func (c *checker) checkService(cfg *config.ServiceConfig) {
+	owner := "service " + cfg.Name
+
+	c.ref(owner, "admission", append([]string{cfg.Admission}, cfg.Admissions...)...)
+	c.ref(owner, "bypass", append([]string{cfg.Bypass}, cfg.Bypasses...)...)
+	c.ref(owner, "resolver", cfg.Resolver)
+	c.ref(owner, "hosts", cfg.Hosts)
+	c.ref(owner, "limiter", cfg.Limiter)
+	c.ref(owner, "climiter", cfg.CLimiter)
+	c.ref(owner, "rlimiter", cfg.RLimiter)
+	c.ref(owner, "logger", cfg.Logger)
+	for _, r := range cfg.Recorders {
+		if r != nil {
+			c.ref(owner, "recorder", r.Name)
+		}
+	}
+
+	if ln := cfg.Listener; ln != nil {
+		if !registry.ListenerRegistry().IsRegistered(ln.Type) {
+			c.errorf("%s: unregistered listener: %s", owner, ln.Type)
+		}
+		c.ref(owner, "chain", ln.Chain)
+		if ln.ChainGroup != nil {
+			c.ref(owner, "chain", ln.ChainGroup.Chains...)
+			c.checkSelector(owner+": listener", ln.ChainGroup.Selector)
+		}
+		c.ref(owner, "auther", append([]string{ln.Auther}, ln.Authers...)...)
+		if ln.TLS != nil {
+			if _, err := tls_util.LoadServerConfig(ln.TLS); err != nil {
+				c.errorf("%s: listener: %v", owner, err)
+			}
+		}
+	}
+
+	if h := cfg.Handler; h != nil {
+		if !registry.HandlerRegistry().IsRegistered(h.Type) {
+			c.errorf("%s: unregistered handler: %s", owner, h.Type)
+		}
+		c.ref(owner, "chain", h.Chain)
+		if h.ChainGroup != nil {
+			c.ref(owner, "chain", h.ChainGroup.Chains...)
+			c.checkSelector(owner+": handler", h.ChainGroup.Selector)
+		}
+		c.ref(owner, "rules", h.Rules)
+		c.ref(owner, "acl", h.ACL)
+		c.ref(owner, "quota", h.Quota)
+		c.ref(owner, "auther", append([]string{h.Auther}, h.Authers...)...)
+		c.ref(owner, "limiter", h.Limiter)
+		if h.TLS != nil {
+			if _, err := tls_util.LoadServerConfig(h.TLS); err != nil {
+				c.errorf("%s: handler: %v", owner, err)
+			}
+		}
+	}
+
+	if fw := cfg.Forwarder; fw != nil {
+		if len(fw.Nodes) == 0 {
+			c.ref(owner, "hop", fw.Name)
+		}
+		c.checkSelector(owner+": forwarder", fw.Selector)
+		c.checkHealthCheck(owner+": forwarder", fw.HealthCheck)
+		for _, node := range fw.Nodes {
+			if node != nil {
+				c.ref(fmt.Sprintf("%s: node %s", owner, node.Name), "bypass",
+					append([]string{node.Bypass}, node.Bypasses...)...)
+			}
+		}
+	}
+}
+
+func (c *checker) checkSelector(owner string, cfg *config.SelectorConfig) {
+	if cfg == nil {
+		return
+	}
+	if !xs.ValidHashKey(cfg.HashKey) {
+		c.errorf("%s: selector: invalid hash key: %s", owner, cfg.HashKey)
+	}
+	if cb := cfg.CircuitBreaker; cb != nil {
+		if cb.FailureRate < 0 || cb.FailureRate > 1 {
+			c.errorf("%s: selector: circuit breaker: failure rate %v out of range (0, 1]", owner, cb.FailureRate)
+		}
+		if cb.Window < 0 || cb.OpenTimeout < 0 || cb.MinRequests < 0 || cb.HalfOpenTrials < 0 {
+			c.errorf("%s: selector: circuit breaker: negative value", owner)
+		}
+	}
+}
+
+func (c *checker) checkHealthCheck(owner string, cfg *config.HealthCheckConfig) {
+	if cfg == nil {
+		return
+	}
+	switch cfg.Type {
+	case "", "tcp", "tls", "http":
+	case "dial":
+		if cfg.Target == "" {
+			c.errorf("%s: health check: target is required for dial probe", owner)
+		}
+	default:
+		c.errorf("%s: health check: unknown type: %s", owner, cfg.Type)
+	}
+	if cfg.Status < 0 || cfg.Status > 999 {
+		c.errorf("%s: health check: invalid status: %d", owner, cfg.Status)
+	}
+}
+
+func names[C any](list []*C, name func(*C) string) []string {
+	var ss []string
+	for _, c := range list {
+		if c != nil {
+			ss = append(ss, name(c))
+		}
+	}
+	return ss
+}
+
+// discard releases the object created only for checking.
+func discard(v any) {
+	if closer, ok := v.(io.Closer); ok {
+		closer.Close()
+	}
+}
+
+// objKey identifies an object of the config by its kind and name.
+type objKey struct {
+	kind string
+	name string
+}
+
+// objects returns the named objects of the config cfg.
+func objects(cfg *config.Config) map[objKey]any {
+	m := make(map[objKey]any)
+	add := func(kind string, name string, v any) {
+		m[objKey{kind: kind, name: name}] = v
+	}
+	each(cfg.Services, func(c *config.ServiceConfig) { add("service", c.Name, c) })
+	each(cfg.Chains, func(c *config.ChainConfig) { add("chain", c.Name, c) })
+	each(cfg.Hops, func(c *config.HopConfig) { add("hop", c.Name, c) })
+	each(cfg.Authers, func(c *config.AutherConfig) { add("auther", c.Name, c) })
+	each(cfg.Admissions, func(c *config.AdmissionConfig) { add("admission", c.Name, c) })
+	each(cfg.Bypasses, func(c *config.BypassConfig) { add("bypass", c.Name, c) })
+	each(cfg.Resolvers, func(c *config.ResolverConfig) { add("resolver", c.Name, c) })
+	each(cfg.Hosts, func(c *config.HostsConfig) { add("hosts", c.Name, c) })
+	each(cfg.Ingresses, func(c *config.IngressConfig) { add("ingress", c.Name, c) })
+	each(cfg.Routers, func(c *config.RouterConfig) { add("router", c.Name, c) })
+	each(cfg.Rules, func(c *config.RulesConfig) { add("rules", c.Name, c) })
+	each(cfg.ACLs, func(c *config.ACLConfig) { add("acl", c.Name, c) })
+	each(cfg.Quotas, func(c *config.QuotaConfig) { add("quota", c.Name, c) })
+	each(cfg.SDs, func(c *config.SDConfig) { add("sd", c.Name, c) })
+	each(cfg.Recorders, func(c *config.RecorderConfig) { add("recorder", c.Name, c) })
+	each(cfg.Limiters, func(c *config.LimiterConfig) { add("limiter", c.Name, c) })
+	each(cfg.CLimiters, func(c *config.LimiterConfig) { add("climiter", c.Name, c) })
+	each(cfg.RLimiters, func(c *config.LimiterConfig) { add("rlimiter", c.Name, c) })
+	each(cfg.Loggers, func(c *config.LoggerConfig) { add("logger", c.Name, c) })
+	return m
+}
+
+func each[C any](list []*C, f func(*C)) {
+	for _, c := range list {
+		if c != nil {
+			f(c)
+		}
+	}
+}
+
+// refsOf returns the objects referenced by the object v.
+func refsOf(v any) (refs []objKey) {
+	add := func(kind string, names ...string) {
+		for _, name := range names {
+			if name != "" {
+				refs = append(refs, objKey{kind: kind, name: name})
+			}
+		}
+	}
+	hop := func(c *config.HopConfig) {
+		add("bypass", append([]string{c.Bypass}, c.Bypasses...)...)
+		add("resolver", c.Resolver)
+		add("hosts", c.Hosts)
+		for _, nc := range c.Nodes {
+			if nc != nil {
+				add("bypass", append([]string{nc.Bypass}, nc.Bypasses...)...)
+				add("resolver", nc.Resolver)
+				add("hosts", nc.Hosts)
+			}
+		}
+	}
+
+	switch c := v.(type) {
+	case *config.ServiceConfig:
+		add("admission", append([]string{c.Admission}, c.Admissions...)...)
+		add("bypass", append([]string{c.Bypass}, c.Bypasses...)...)
+		add("resolver", c.Resolver)
+		add("hosts", c.Hosts)
+		add("limiter", c.Limiter)
+		add("climiter", c.CLimiter)
+		add("rlimiter", c.RLimiter)
+		add("logger", c.Logger)
+		for _, r := range c.Recorders {
+			if r != nil {
+				add("recorder", r.Name)
+			}
+		}
+		if ln := c.Listener; ln != nil {
+			add("chain", ln.Chain)
+			if ln.ChainGroup != nil {
+				add("chain", ln.ChainGroup.Chains...)
+			}
+			add("auther", append([]string{ln.Auther}, ln.Authers...)...)
+		}
+		if h := c.Handler; h != nil {
+			add("chain", h.Chain)
+			if h.ChainGroup != nil {
+				add("chain", h.ChainGroup.Chains...)
+			}
+			add("rules", h.Rules)
+			add("acl", h.ACL)
+			add("quota", h.Quota)
+			add("auther", append([]string{h.Auther}, h.Authers...)...)
+			add("limiter", h.Limiter)
+		}
+		if fw := c.Forwarder; fw != nil {
+			if len(fw.Nodes) == 0 {
+				add("hop", fw.Name)
+			}
+			for _, node := range fw.Nodes {
+				if node != nil {
+					add("bypass", append([]string{node.Bypass}, node.Bypasses...)...)
+				}
+			}
+		}
+	case *config.ChainConfig:
+		for _, hc := range c.Hops {
+			if hc == nil {
+				continue
+			}
+			if hc.Nodes == nil && hc.Plugin == nil {
+				add("hop", hc.Name)
+				continue
+			}
+			hop(hc)
+		}
+	case *config.HopConfig:
+		hop(c)
+	case *config.RulesConfig:
+		for _, r := range c.Rules {
+			if r == nil {
+				continue
+			}
+			add("chain", r.Chain)
+			if r.ChainGroup != nil {
+				add("chain", r.ChainGroup.Chains...)
+			}
+		}
+	case *config.ResolverConfig:
+		for _, ns := range c.Nameservers {
+			if ns != nil {
+				add("chain", ns.Chain)
+			}
+		}
+	}
+	return
+}
+
+// changedConfig returns the config of the objects of cfg which are new or changed from prev,
+// the objects they reference, and the objects referencing the objects removed from prev.
+// The global sections are included if they are changed.
+func changedConfig(prev, cfg *config.Config) *config.Config {
+	prevObjs := objects(prev)
+	objs := objects(cfg)
+
+	selected := make(map[objKey]bool)
+	var queue []objKey
+	for k, v := range objs {
+		if pv, ok := prevObjs[k]; !ok || !equal(pv, v) {
+			queue = append(queue, k)
+		}
+	}
+	for k, v := range objs {
+		for _, ref := range refsOf(v) {
+			if _, ok := prevObjs[ref]; !ok {
+				continue
+			}
+			if _, ok := objs[ref]; !ok {
+				queue = append(queue, k)
+				break
+			}
+		}
+	}
+	// the reference closure of the selected objects.
+	for len(queue) > 0 {
+		k := queue[0]
+		queue = queue[1:]
+		if selected[k] {
+			continue
+		}
+		selected[k] = true
+		queue = append(queue, refsOf(objs[k])...)
+	}
+
+	sub := &config.Config{}
+	sub.Services = filter(cfg.Services, "service", selected, func(c *config.ServiceConfig) string { return c.Name })
+	sub.Chains = filter(cfg.Chains, "chain", selected, func(c *config.ChainConfig) string { return c.Name })
+	sub.Hops = filter(cfg.Hops, "hop", selected, func(c *config.HopConfig) string { return c.Name })
+	sub.Authers = filter(cfg.Authers, "auther", selected, func(c *config.AutherConfig) string { return c.Name })
+	sub.Admissions = filter(cfg.Admissions, "admission", selected, func(c *config.AdmissionConfig) string { return c.Name })
+	sub.Bypasses = filter(cfg.Bypasses, "bypass", selected, func(c *config.BypassConfig) string { return c.Name })
+	sub.Resolvers = filter(cfg.Resolvers, "resolver", selected, func(c *config.ResolverConfig) string { return c.Name })
+	sub.Hosts = filter(cfg.Hosts, "hosts", selected, func(c *config.HostsConfig) string { return c.Name })
+	sub.Ingresses = filter(cfg.Ingresses, "ingress", selected, func(c *config.IngressConfig) string { return c.Name })
+	sub.Routers = filter(cfg.Routers, "router", selected, func(c *config.RouterConfig) string { return c.Name })
+	sub.Rules = filter(cfg.Rules, "rules", selected, func(c *config.RulesConfig) string { return c.Name })
+	sub.ACLs = filter(cfg.ACLs, "acl", selected, func(c *config.ACLConfig) string { return c.Name })
+	sub.Quotas = filter(cfg.Quotas, "quota", selected, func(c *config.QuotaConfig) string { return c.Name })
+	sub.SDs = filter(cfg.SDs, "sd", selected, func(c *config.SDConfig) string { return c.Name })
+	sub.Recorders = filter(cfg.Recorders, "recorder", selected, func(c *config.RecorderConfig) string { return c.Name })
+	sub.Limiters = filter(cfg.Limiters, "limiter", selected, func(c *config.LimiterConfig) string { return c.Name })
+	sub.CLimiters = filter(cfg.CLimiters, "climiter", selected, func(c *config.LimiterConfig) string { return c.Name })
+	sub.RLimiters = filter(cfg.RLimiters, "rlimiter", selected, func(c *config.LimiterConfig) string { return c.Name })
+	sub.Loggers = filter(cfg.Loggers, "logger", selected, func(c *config.LoggerConfig) string { return c.Name })
+
+	if !equal(prev.TLS, cfg.TLS) {
+		sub.TLS = cfg.TLS
+	}
+	if !equal(prev.Log, cfg.Log) {
+		sub.Log = cfg.Log
+	}
+	if !equal(prev.API, cfg.API) {
+		sub.API = cfg.API
+	}
+	if !equal(prev.Metrics, cfg.Metrics) {
+		sub.Metrics = cfg.Metrics
+	}
+	return sub
+}
+
+func filter[C any](list []*C, kind string, selected map[objKey]bool, name func(*C) string) []*C {
+	var result []*C
+	for _, c := range list {
+		if c != nil && selected[objKey{kind: kind, name: name(c)}] {
+			result = append(result, c)
+		}
+	}
+	return result
+}
@@ -0,0 +1,663 @@
+package loader
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+
+	"github.com/go-gost/core/logger"
+	reg "github.com/go-gost/core/registry"
+	"github.com/go-gost/x/config"
+	"github.com/go-gost/x/config/parsing"
+	acl_parser "github.com/go-gost/x/config/parsing/acl"
+	admission_parser "github.com/go-gost/x/config/parsing/admission"
+	auth_parser "github.com/go-gost/x/config/parsing/auth"
+	bypass_parser "github.com/go-gost/x/config/parsing/bypass"
+	chain_parser "github.com/go-gost/x/config/parsing/chain"
+	hop_parser "github.com/go-gost/x/config/parsing/hop"
+	hosts_parser "github.com/go-gost/x/config/parsing/hosts"
+	ingress_parser "github.com/go-gost/x/config/parsing/ingress"
+	limiter_parser "github.com/go-gost/x/config/parsing/limiter"
+	logger_parser "github.com/go-gost/x/config/parsing/logger"
+	quota_parser "github.com/go-gost/x/config/parsing/quota"
+	recorder_parser "github.com/go-gost/x/config/parsing/recorder"
+	resolver_parser "github.com/go-gost/x/config/parsing/resolver"
+	router_parser "github.com/go-gost/x/config/parsing/router"
+	rule_parser "github.com/go-gost/x/config/parsing/rule"
+	sd_parser "github.com/go-gost/x/config/parsing/sd"
+	service_parser "github.com/go-gost/x/config/parsing/service"
+	"github.com/go-gost/x/registry"
+)
+
+var (
+	mu sync.Mutex
+)
+
+// Apply applies the config cfg to the running instance.
+// It computes the difference between cfg and the current global config,
+// then creates, updates or deletes only the objects that actually changed.
+// Objects which fail to parse keep their previous version (if any),
+// and the global config is updated to reflect what is actually running.
+func Apply(cfg *config.Config) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	return apply(cfg, false)
+}
+
+// ApplyAtomic is the same as Apply, except that the changes are all or nothing:
+// if any object fails to parse or bind, all the changes made are rolled back,
+// and the global config is left unchanged.
+// The config should be validated by Check before applying.
+func ApplyAtomic(cfg *config.Config) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	return apply(cfg, true)
+}
+
+// Update calls build with a copy of the current global config to make the changes,
+// then applies the config built the same way as ApplyAtomic, and calls commit with it if it is applied.
+// They are all done under the same lock as Apply, so the concurrent changes are serialized and none is lost.
+// The error returned by build is returned as is.
+func Update(build func(cfg *config.Config) error, commit func(cfg *config.Config)) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	cfg := config.Global()
+	if err := build(cfg); err != nil {
+		return err
+	}
+	if err := apply(cfg, true); err != nil {
+		return err
+	}
+	if commit != nil {
+		commit(cfg)
+	}
+	return nil
+}
+
+// Do calls fn under the same lock as Apply,
+// for the changes made to the registries and the global config directly.
+func Do(fn func()) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	fn()
+}
+
+// tx records the changes made to the registries, so they can be rolled back.
+type tx struct {
+	log    logger.Logger
+	atomic bool
+	errs   []error
+	undo   []func()
+}
+
+func (t *tx) fail(err error) {
+	t.errs = append(t.errs, err)
+	t.log.Error(err)
+}
+
+// aborted reports whether the changes should be stopped and rolled back.
+func (t *tx) aborted() bool {
+	return t.atomic && len(t.errs) > 0
+}
+
+func (t *tx) rollback() {
+	for i := len(t.undo) - 1; i >= 0; i-- {
+		t.undo[i]()
+	}
+	t.undo = nil
+}
+
+// apply applies the config cfg, mu must be held.
+func apply(cfg *config.Config, atomic bool) error {
+	if cfg == nil {
+		return nil
+	}
+
+	t := &tx{
+		log: logger.Default().WithFields(map[string]any{
+			"kind": "reload",
+		}),
+		atomic: atomic,
+	}
+
+	prev := config.Global()
+
+	// loggers are bound to services when parsing,
+	// so the services using the changed loggers must be rebuilt.
+	changedLoggers := make(map[string]bool)
+	cfg.Loggers = applyObjects(t, "logger", registry.LoggerRegistry(),
+		prev.Loggers, cfg.Loggers,
+		func(c *config.LoggerConfig) string { return c.Name },
+		func(c *config.LoggerConfig) (logger.Logger, error) {
+			changedLoggers[c.Name] = true
+			return logger_parser.ParseLogger(c), nil
+		},
+	)
+	for _, c := range prev.Loggers {
+		if !contains(cfg.Loggers, c.Name, func(c *config.LoggerConfig) string { return c.Name }) {
+			changedLoggers[c.Name] = true
+		}
+	}
+
+	cfg.Authers = applyObjects(t, "auther", registry.AutherRegistry(),
+		prev.Authers, cfg.Authers,
+		func(c *config.AutherConfig) string { return c.Name },
+		wrap(auth_parser.ParseAuther),
+	)
+	cfg.Admissions = applyObjects(t, "admission", registry.AdmissionRegistry(),
+		prev.Admissions, cfg.Admissions,
+		func(c *config.AdmissionConfig) string { return c.Name },
+		wrap(admission_parser.ParseAdmission),
+	)
+	cfg.Bypasses = applyObjects(t, "bypass", registry.BypassRegistry(),
+		prev.Bypasses, cfg.Bypasses,
+		func(c *config.BypassConfig) string { return c.Name },
+		wrap(bypass_parser.ParseBypass),
+	)
+	cfg.Resolvers = applyObjects(t, "resolver", registry.ResolverRegistry(),
+		prev.Resolvers, cfg.Resolvers,
+		func(c *config.ResolverConfig) string { return c.Name },
+		resolver_parser.ParseResolver,
+	)
+	cfg.Hosts = applyObjects(t, "hosts", registry.HostsRegistry(),
+		prev.Hosts, cfg.Hosts,
+		func(c *config.HostsConfig) string { return c.Name },
+		wrap(hosts_parser.ParseHostMapper),
+	)
+	cfg.Ingresses = applyObjects(t, "ingress", registry.IngressRegistry(),
+		prev.Ingresses, cfg.Ingresses,
+		func(c *config.IngressConfig) string { return c.Name },
+		wrap(ingress_parser.ParseIngress),
+	)
+	cfg.Routers = applyObjects(t, "router", registry.RouterRegistry(),
+		prev.Routers, cfg.Routers,
+		func(c *config.RouterConfig) string { return c.Name },
+		wrap(router_parser.ParseRouter),
+	)
+	cfg.Rules = applyObjects(t, "rules", registry.RulesRegistry(),
+		prev.Rules, cfg.Rules,
+		func(c *config.RulesConfig) string { return c.Name },
+		rule_parser.ParseRules,
+	)
+	cfg.ACLs = applyObjects(t, "acl", registry.ACLRegistry(),
+		prev.ACLs, cfg.ACLs,
+		func(c *config.ACLConfig) string { return c.Name },
+		acl_parser.ParseACL,
+	)
+	cfg.Quotas = applyObjects(t, "quota", registry.QuotaRegistry(),
+		prev.Quotas, cfg.Quotas,
+		func(c *config.QuotaConfig) string { return c.Name },
+		quota_parser.ParseQuota,
+	)
+	cfg.SDs = applyObjects(t, "sd", registry.SDRegistry(),
+		prev.SDs, cfg.SDs,
+		func(c *config.SDConfig) string { return c.Name },
+		wrap(sd_parser.ParseSD),
+	)
+	cfg.Recorders = applyObjects(t, "recorder", registry.RecorderRegistry(),
+		prev.Recorders, cfg.Recorders,
+		func(c *config.RecorderConfig) string { return c.Name },
+		wrap(recorder_parser.ParseRecorder),
+	)
+	cfg.Limiters = applyObjects(t, "limiter", registry.TrafficLimiterRegistry(),
+		prev.Limiters, cfg.Limiters,
+		func(c *config.LimiterConfig) string { return c.Name },
+		wrap(limiter_parser.ParseTrafficLimiter),
+	)
+	cfg.CLimiters = applyObjects(t, "climiter", registry.ConnLimiterRegistry(),
+		prev.CLimiters, cfg.CLimiters,
+		func(c *config.LimiterConfig) string { return c.Name },
+		wrap(limiter_parser.ParseConnLimiter),
+	)
+	cfg.RLimiters = applyObjects(t, "rlimiter", registry.RateLimiterRegistry(),
+		prev.RLimiters, cfg.RLimiters,
+		func(c *config.LimiterConfig) string { return c.Name },
+		wrap(limiter_parser.ParseRateLimiter),
+	)
+	cfg.Hops = applyObjects(t, "hop", registry.HopRegistry(),
+		prev.Hops, cfg.Hops,
+		func(c *config.HopConfig) string { return c.Name },
+		hop_parser.ParseHop,
+	)
+	cfg.Chains = applyObjects(t, "chain", registry.ChainRegistry(),
+		prev.Chains, cfg.Chains,
+		func(c *config.ChainConfig) string { return c.Name },
+		chain_parser.ParseChain,
+	)
+
+	// the default TLS config is bound to services when parsing.
+	tlsChanged := !equal(prev.TLS, cfg.TLS)
+	logChanged := !equal(prev.Log, cfg.Log)
+	if t.aborted() {
+		tlsChanged, logChanged = false, false
+	}
+	if tlsChanged {
+		parsing.BuildDefaultTLSConfig(cfg.TLS)
+	}
+	if logChanged {
+		updateDefaultLogger(prev.Log, cfg.Log)
+	}
+
+	cfg.Services = applyServices(t, prev.Services, cfg.Services,
+		func(c *config.ServiceConfig) bool {
+			if changedLoggers[c.Logger] {
+				return true
+			}
+			return tlsChanged && usesDefaultTLS(c)
+		},
+	)
+
+	if t.aborted() {
+		t.log.Warn("rolling back all the changes")
+		// the global TLS and log configs must be restored before the services.
+		if tlsChanged {
+			parsing.BuildDefaultTLSConfig(prev.TLS)
+		}
+		if logChanged {
+			updateDefaultLogger(cfg.Log, prev.Log)
+		}
+		t.rollback()
+		return errors.Join(t.errs...)
+	}
+
+	if !equal(prev.API, cfg.API) {
+		t.log.Warn("api config changed, restart is required to take effect")
+	}
+	if !equal(prev.Metrics, cfg.Metrics) {
+		t.log.Warn("metrics config changed, restart is required to take effect")
+	}
+	if !equal(prev.Profiling, cfg.Profiling) {
+		t.log.Warn("profiling config changed, restart is required to take effect")
+	}
+
+	config.Set(cfg)
+
+	return errors.Join(t.errs...)
+}
+
+// applyObjects applies the changes between the object lists olds and news to the registry r.
+// It returns the list of object configs which reflects the running objects.
+func applyObjects[C any, T any](t *tx, kind string, r reg.Registry[T],
+	olds, news []*C,
+	name func(*C) string, parse func(*C) (T, error)) []*C {
+
+	if t.aborted() {
+		return news
+	}
+
+	oldm := make(map[string]*C)
+	for _, c := range olds {
+		if c != nil {
+			oldm[name(c)] = c
+		}
+	}
+
+	// restore brings the previous version of the object back.
+	restore := func(n string, old *C) {
+		r.Unregister(n)
+		if old == nil {
+			return
+		}
+		if v, err := parse(old); err == nil && any(v) != nil {
+			r.Register(n, v)
+		}
+	}
+
+	var result []*C
+	seen := make(map[string]bool)
+	for _, c := range news {
+		if c == nil {
+			continue
+		}
+		n := name(c)
+		if n == "" || seen[n] {
+			continue
+		}
+		seen[n] = true
+
+		old := oldm[n]
+		if old != nil && equal(old, c) {
+			result = append(result, old)
+			continue
+		}
+
+		v, err := parse(c)
+		if err != nil {
+			t.fail(fmt.Errorf("%s %s: %w", kind, n, err))
+			if t.atomic {
+				return news
+			}
+			if old != nil {
+				result = append(result, old)
+			}
+			continue
+		}
+
+		if old != nil {
+			r.Unregister(n)
+		}
+		if any(v) != nil {
+			if err := r.Register(n, v); err != nil {
+				t.fail(fmt.Errorf("%s %s: %w", kind, n, err))
+				if t.atomic {
+					restore(n, old)
+					return news
+				}
+				continue
+			}
+		}
+		t.undo = append(t.undo, func() { restore(n, old) })
+		result = append(result, c)
+
+		if old != nil {
+			t.log.Infof("%s %s updated", kind, n)
+		} else {
+			t.log.Infof("%s %s created", kind, n)
+		}
+	}
+
+	for n, old := range oldm {
+		if !seen[n] {
+			n, old := n, old
+			r.Unregister(n)
+			t.undo = append(t.undo, func() { restore(n, old) })
+			t.log.Infof("%s %s deleted", kind, n)
+		}
+	}
+
+	return result
+}
+
+// applyServices is the same as applyObjects for services,
+// the old service must be closed before the new one is created,
+// as they may listen on the same address.
+func applyServices(t *tx, olds, news []*config.ServiceConfig,
+	rebuild func(c *config.ServiceConfig) bool) []*config.ServiceConfig {
+
+	if t.aborted() {
+		return news
+	}
+
+	r := registry.ServiceRegistry()
+
+	oldm := make(map[string]*config.ServiceConfig)
+	for _, c := range olds {
+		if c != nil {
+			oldm[c.Name] = c
+		}
+	}
+
+	// restore closes the current service and brings the previous version back.
+	restore := func(n string, old *config.ServiceConfig) bool {
+		if svc := r.Get(n); svc != nil {
+			r.Unregister(n)
+			svc.Close()
+		}
+		if old == nil {
+			return false
+		}
+		svc, err := service_parser.ParseService(old)
+		if err != nil {
+			t.log.Errorf("service %s: restore: %v", n, err)
+			return false
+		}
+		if err := r.Register(n, svc); err != nil {
+			svc.Close()
+			return false
+		}
+		go svc.Serve()
+		return true
+	}
+
+	seen := make(map[string]bool)
+	for _, c := range news {
+		if c != nil && c.Name != "" {
+			seen[c.Name] = true
+		}
+	}
+	for n, old := range oldm {
+		if !seen[n] {
+			n, old := n, old
+			if svc := r.Get(n); svc != nil {
+				r.Unregister(n)
+				svc.Close()
+			}
+			t.undo = append(t.undo, func() { restore(n, old) })
+			t.log.Infof("service %s deleted", n)
+		}
+	}
+
+	var result []*config.ServiceConfig
+	seen = make(map[string]bool)
+	for _, c := range news {
+		if c == nil || c.Name == "" || seen[c.Name] {
+			continue
+		}
+		seen[c.Name] = true
+
+		old := oldm[c.Name]
+		if old != nil && equal(old, c) && !rebuild(old) {
+			result = append(result, old)
+			continue
+		}
+
+		if old != nil {
+			if svc := r.Get(c.Name); svc != nil {
+				r.Unregister(c.Name)
+				svc.Close()
+			}
+		}
+
+		svc, err := service_parser.ParseService(c)
+		if err == nil {
+			if err = r.Register(c.Name, svc); err != nil {
+				svc.Close()
+			}
+		}
+		if err != nil {
+			t.fail(fmt.Errorf("service %s: %w", c.Name, err))
+
+			// try to bring the previous version back.
+			if restore(c.Name, old) && !t.atomic {
+				result = append(result, old)
+			}
+			if t.atomic {
+				return news
+			}
+			continue
+		}
+
+		go svc.Serve()
+		n, old := c.Name, old
+		t.undo = append(t.undo, func() { restore(n, old) })
+		result = append(result, c)
+
+		if old != nil {
+			t.log.Infof("service %s updated", c.Name)
+		} else {
+			t.log.Infof("service %s created", c.Name)
+		}
+	}
+
+	return result
+}
+
+func setDefaultLogger(cfg *config.LogConfig) {
+	if cfg == nil {
+		cfg = &config.LogConfig{}
+	}
+	logger.SetDefault(logger_parser.ParseLogger(&config.LoggerConfig{Log: cfg}))
+}
+
+// updateDefaultLogger changes the default logger from the log config old to cfg.
+// If only the level is changed, the level of the default logger is changed in place,
+// so that it takes effect in the loggers derived from it, such as the ones used by the running services.
+func updateDefaultLogger(old, cfg *config.LogConfig) {
+	if old == nil {
+		old = &config.LogConfig{}
+	}
+	if cfg == nil {
+		cfg = &config.LogConfig{}
+	}
+	if l, ok := logger.Default().(interface{ SetLevel(logger.LogLevel) }); ok {
+		o, c := *old, *cfg
+		o.Level, c.Level = "", ""
+		if equal(&o, &c) {
+			l.SetLevel(logger.LogLevel(cfg.Level))
+			return
+		}
+	}
+	setDefaultLogger(cfg)
+}
+
+func wrap[C any, T any](parse func(*C) T) func(*C) (T, error) {
+	return func(c *C) (T, error) {
+		return parse(c), nil
+	}
+}
+
+func contains[C any](list []*C, n string, name func(*C) string) bool {
+	for _, c := range list {
+		if c != nil && name(c) == n {
+			return true
+		}
+	}
+	return false
+}
+
+// the types of the listeners and handlers which load a TLS config,
+// they fall back to the global TLS config if no certificate is specified.
+var (
+	tlsListeners = map[string]bool{
+		"dns": true, "dtls": true, "grpc": true, "h2": true, "h3": true,
+		"http2": true, "http3": true, "icmp": true, "mtls": true, "mwss": true,
+		"phts": true, "quic": true, "ssh": true, "sshd": true, "tls": true,
+		"wss": true, "wt": true,
+	}
+	tlsHandlers = map[string]bool{
+		"auto": true, "socks": true, "socks5": true,
+	}
+)
+
+// usesDefaultTLS reports whether the service uses the global TLS config,
+// so it must be rebuilt when the global TLS config changes.
+func usesDefaultTLS(c *config.ServiceConfig) bool {
+	if c.Listener != nil && tlsListeners[c.Listener.Type] &&
+		(c.Listener.TLS == nil || c.Listener.TLS.CertFile == "") {
+		return true
+	}
+	return c.Handler != nil && tlsHandlers[c.Handler.Type] &&
+		(c.Handler.TLS == nil || c.Handler.TLS.CertFile == "")
+}
+
+// equal reports whether the two object configs are equivalent.
+// The parsers fill in default values to the configs they parsed,
+// so both sides are normalized before comparing.
+func equal(a, b any) bool {
+	va, err := normalize(a)
+	if err != nil {
+		return false
+	}
+	vb, err := normalize(b)
+	if err != nil {
+		return false
+	}
+	return string(va) == string(vb)
+}
+
+func normalize(v any) ([]byte, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	switch v.(type) {
+	case *config.ServiceConfig:
+		c := &config.ServiceConfig{}
+		if err := json.Unmarshal(b, c); err != nil {
+			return nil, err
+		}
+		normalizeService(c)
+		return json.Marshal(c)
+	case *config.HopConfig:
+		c := &config.HopConfig{}
+		if err := json.Unmarshal(b, c); err != nil {
+			return nil, err
+		}
+		normalizeHop(c)
+		return json.Marshal(c)
+	case *config.ChainConfig:
+		c := &config.ChainConfig{}
+		if err := json.Unmarshal(b, c); err != nil {
+			return nil, err
+		}
+		for _, hop := range c.Hops {
+			if hop != nil && (hop.Nodes != nil || hop.Plugin != nil) {
+				normalizeHop(hop)
+			}
+		}
+		return json.Marshal(c)
+	}
+
+	return b, nil
+}
+
+func normalizeService(c *config.ServiceConfig) {
+	if c.Listener == nil {
+		c.Listener = &config.ListenerConfig{
+			Type: "tcp",
+		}
+	}
+	if c.Handler == nil {
+		c.Handler = &config.HandlerConfig{
+			Type: "auto",
+		}
+	}
+}
+
+func normalizeHop(c *config.HopConfig) {
+	if c.Plugin != nil {
+		return
+	}
+	for _, node := range c.Nodes {
+		if node == nil {
+			continue
+		}
+		if node.Resolver == "" {
+			node.Resolver = c.Resolver
+		}
+		if node.Hosts == "" {
+			node.Hosts = c.Hosts
+		}
+		if node.Interface == "" {
+			node.Interface = c.Interface
+		}
+		if node.SockOpts == nil {
+			node.SockOpts = c.SockOpts
+		}
+		if node.Connector == nil {
+			node.Connector = &config.ConnectorConfig{
+				Type: "http",
+			}
+		}
+		if node.Dialer == nil {
+			node.Dialer = &config.DialerConfig{
+				Type: "tcp",
+			}
+		}
+
+		serverName, _, _ := net.SplitHostPort(node.Addr)
+		if tls := node.Connector.TLS; tls != nil && tls.ServerName == "" {
+			tls.ServerName = serverName
+		}
+		if tls := node.Dialer.TLS; tls != nil && tls.ServerName == "" {
+			tls.ServerName = serverName
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package acl
+
+import (
+	"github.com/go-gost/core/logger"
+	"github.com/go-gost/x/acl"
+	"github.com/go-gost/x/config"
+	"github.com/go-gost/x/config/parsing"
+	"github.com/go-gost/x/internal/loader"
+)
+
+func ParseACL(cfg *config.ACLConfig) (acl.ACL, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+
+	for _, s := range cfg.Rules {
+		if _, err := acl.ParseRule(s); err != nil {
+			return nil, err
+		}
+	}
+
+	opts := []acl.Option{
+		acl.RulesOption(cfg.Rules),
+		acl.ReloadPeriodOption(cfg.Reload),
+		acl.LoggerOption(logger.Default().WithFields(map[string]any{
+			"kind": "acl",
+			"acl":  cfg.Name,
+		})),
+	}
+	if cfg.File != nil && cfg.File.Path != "" {
+		opts = append(opts, acl.FileLoaderOption(loader.FileLoader(cfg.File.Path)))
+	}
+	if cfg.Redis != nil && cfg.Redis.Addr != "" {
+		// the rules are ordered, so they are kept in a redis list.
+		opts = append(opts, acl.RedisLoaderOption(loader.RedisListLoader(
+			cfg.Redis.Addr,
+			loader.DBRedisLoaderOption(cfg.Redis.DB),
+			loader.PasswordRedisLoaderOption(parsing.Secret(cfg.Redis.Password)),
+			loader.KeyRedisLoaderOption(cfg.Redis.Key),
+		)))
+	}
+	if cfg.HTTP != nil && cfg.HTTP.URL != "" {
+		opts = append(opts, acl.HTTPLoaderOption(loader.HTTPLoader(
+			cfg.HTTP.URL,
+			loader.TimeoutHTTPLoaderOption(cfg.HTTP.Timeout),
+		)))
+	}
+
+	return acl.NewACL(opts...), nil
+}
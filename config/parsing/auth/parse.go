@@ -8,6 +8,7 @@ import (
 	"github.com/go-gost/core/logger"
 	xauth "github.com/go-gost/x/auth"
 	"github.com/go-gost/x/config"
+	"github.com/go-gost/x/config/parsing"
 	"github.com/go-gost/x/internal/loader"
 	"github.com/go-gost/x/internal/plugin"
 	"github.com/go-gost/x/registry"
@@ -36,23 +37,31 @@ func ParseAuther(cfg *config.AutherConfig) auth.Authenticator {
 		default:
 			return xauth.NewGRPCPlugin(
 				cfg.Name, cfg.Plugin.Addr,
-				plugin.TokenOption(cfg.Plugin.Token),
+				plugin.TokenOption(parsing.Secret(cfg.Plugin.Token)),
 				plugin.TLSConfigOption(tlsCfg),
 			)
 		}
 	}
 
 	m := make(map[string]string)
+	accounts := make(map[string]*xauth.Account)
 
 	for _, user := range cfg.Auths {
 		if user.Username == "" {
 			continue
 		}
-		m[user.Username] = user.Password
+		m[user.Username] = parsing.Secret(user.Password)
+		if user.ID != "" || len(user.Groups) > 0 {
+			accounts[user.Username] = &xauth.Account{
+				ID:     user.ID,
+				Groups: user.Groups,
+			}
+		}
 	}
 
 	opts := []xauth.Option{
 		xauth.AuthsOption(m),
+		xauth.AccountsOption(accounts),
 		xauth.ReloadPeriodOption(cfg.Reload),
 		xauth.LoggerOption(logger.Default().WithFields(map[string]any{
 			"kind":   "auther",
@@ -66,7 +75,7 @@ func ParseAuther(cfg *config.AutherConfig) auth.Authenticator {
 		opts = append(opts, xauth.RedisLoaderOption(loader.RedisHashLoader(
 			cfg.Redis.Addr,
 			loader.DBRedisLoaderOption(cfg.Redis.DB),
-			loader.PasswordRedisLoaderOption(cfg.Redis.Password),
+			loader.PasswordRedisLoaderOption(parsing.Secret(cfg.Redis.Password)),
 			loader.KeyRedisLoaderOption(cfg.Redis.Key),
 		)))
 	}
@@ -83,12 +92,22 @@ func ParseAutherFromAuth(au *config.AuthConfig) auth.Authenticator {
 	if au == nil || au.Username == "" {
 		return nil
 	}
+	var accounts map[string]*xauth.Account
+	if au.ID != "" || len(au.Groups) > 0 {
+		accounts = map[string]*xauth.Account{
+			au.Username: {
+				ID:     au.ID,
+				Groups: au.Groups,
+			},
+		}
+	}
 	return xauth.NewAuthenticator(
 		xauth.AuthsOption(
 			map[string]string{
-				au.Username: au.Password,
+				au.Username: parsing.Secret(au.Password),
 			},
 		),
+		xauth.AccountsOption(accounts),
 		xauth.LoggerOption(logger.Default().WithFields(map[string]any{
 			"kind": "auther",
 		})),
@@ -100,10 +119,11 @@ func Info(cfg *config.AuthConfig) *url.Userinfo {
 		return nil
 	}
 
-	if cfg.Password == "" {
+	password := parsing.Secret(cfg.Password)
+	if password == "" {
 		return url.User(cfg.Username)
 	}
-	return url.UserPassword(cfg.Username, cfg.Password)
+	return url.UserPassword(cfg.Username, password)
 }
 
 func List(name string, names ...string) []auth.Authenticator {
@@ -8,6 +8,7 @@ import (
 	"github.com/go-gost/core/logger"
 	xbypass "github.com/go-gost/x/bypass"
 	"github.com/go-gost/x/config"
+	"github.com/go-gost/x/config/parsing"
 	"github.com/go-gost/x/internal/loader"
 	"github.com/go-gost/x/internal/plugin"
 	"github.com/go-gost/x/registry"
@@ -36,7 +37,7 @@ func ParseBypass(cfg *config.BypassConfig) bypass.Bypass {
 		default:
 			return xbypass.NewGRPCPlugin(
 				cfg.Name, cfg.Plugin.Addr,
-				plugin.TokenOption(cfg.Plugin.Token),
+				plugin.TokenOption(parsing.Secret(cfg.Plugin.Token)),
 				plugin.TLSConfigOption(tlsCfg),
 			)
 		}
@@ -58,7 +59,7 @@ func ParseBypass(cfg *config.BypassConfig) bypass.Bypass {
 		opts = append(opts, xbypass.RedisLoaderOption(loader.RedisSetLoader(
 			cfg.Redis.Addr,
 			loader.DBRedisLoaderOption(cfg.Redis.DB),
-			loader.PasswordRedisLoaderOption(cfg.Redis.Password),
+			loader.PasswordRedisLoaderOption(parsing.Secret(cfg.Redis.Password)),
 			loader.KeyRedisLoaderOption(cfg.Redis.Key),
 		)))
 	}
@@ -4,12 +4,14 @@ import (
 	"github.com/go-gost/core/chain"
 	"github.com/go-gost/core/hop"
 	"github.com/go-gost/core/logger"
-	"github.com/go-gost/core/metadata"
+	"github.com/go-gost/core/selector"
 	xchain "github.com/go-gost/x/chain"
 	"github.com/go-gost/x/config"
 	hop_parser "github.com/go-gost/x/config/parsing/hop"
+	selector_parser "github.com/go-gost/x/config/parsing/selector"
 	mdx "github.com/go-gost/x/metadata"
 	"github.com/go-gost/x/registry"
+	xs "github.com/go-gost/x/selector"
 )
 
 func ParseChain(cfg *config.ChainConfig) (chain.Chainer, error) {
@@ -22,13 +24,21 @@ func ParseChain(cfg *config.ChainConfig) (chain.Chainer, error) {
 		"chain": cfg.Name,
 	})
 
-	var md metadata.Metadata
-	if cfg.Metadata != nil {
-		md = mdx.NewMetadata(cfg.Metadata)
+	// the chain metadata also carries the runtime state of the chain.
+	md := mdx.NewMetadata(cfg.Metadata)
+	xs.InitState(md)
+
+	var failover *xchain.FailoverOptions
+	if cfg.Failover != nil {
+		failover = &xchain.FailoverOptions{
+			Attempts: cfg.Failover.Attempts,
+			Timeout:  cfg.Failover.Timeout,
+		}
 	}
 
 	c := xchain.NewChain(cfg.Name,
 		xchain.MetadataChainOption(md),
+		xchain.FailoverChainOption(failover),
 		xchain.LoggerChainOption(chainLogger),
 	)
 
@@ -50,3 +60,32 @@ func ParseChain(cfg *config.ChainConfig) (chain.Chainer, error) {
 
 	return c, nil
 }
+
+// ParseChainGroup creates a chain.Chainer from the chain name and the chain group,
+// it returns nil if no chain is specified.
+func ParseChainGroup(name string, group *config.ChainGroupConfig) chain.Chainer {
+	var chains []chain.Chainer
+	var sel selector.Selector[chain.Chainer]
+
+	if c := registry.ChainRegistry().Get(name); c != nil {
+		chains = append(chains, c)
+	}
+	if group != nil {
+		for _, s := range group.Chains {
+			if c := registry.ChainRegistry().Get(s); c != nil {
+				chains = append(chains, c)
+			}
+		}
+		sel = selector_parser.ParseChainSelector(group.Selector)
+	}
+	if len(chains) == 0 {
+		return nil
+	}
+
+	if sel == nil {
+		sel = selector_parser.DefaultChainSelector()
+	}
+
+	return xchain.NewChainGroup(chains...).
+		WithSelector(sel)
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/go-gost/core/hop"
 	"github.com/go-gost/core/logger"
 	"github.com/go-gost/x/config"
+	"github.com/go-gost/x/config/parsing"
 	bypass_parser "github.com/go-gost/x/config/parsing/bypass"
 	node_parser "github.com/go-gost/x/config/parsing/node"
 	selector_parser "github.com/go-gost/x/config/parsing/selector"
@@ -17,6 +18,10 @@ import (
 	"github.com/go-gost/x/internal/plugin"
 )
 
+const (
+	defaultRaceCount = 2
+)
+
 func ParseHop(cfg *config.HopConfig) (hop.Hop, error) {
 	if cfg == nil {
 		return nil, nil
@@ -40,7 +45,7 @@ func ParseHop(cfg *config.HopConfig) (hop.Hop, error) {
 		default:
 			return xhop.NewGRPCPlugin(
 				cfg.Name, cfg.Plugin.Addr,
-				plugin.TokenOption(cfg.Plugin.Token),
+				plugin.TokenOption(parsing.Secret(cfg.Plugin.Token)),
 				plugin.TLSConfigOption(tlsCfg),
 			), nil
 		}
@@ -110,7 +115,7 @@ func ParseHop(cfg *config.HopConfig) (hop.Hop, error) {
 		opts = append(opts, xhop.RedisLoaderOption(loader.RedisStringLoader(
 			cfg.Redis.Addr,
 			loader.DBRedisLoaderOption(cfg.Redis.DB),
-			loader.PasswordRedisLoaderOption(cfg.Redis.Password),
+			loader.PasswordRedisLoaderOption(parsing.Secret(cfg.Redis.Password)),
 			loader.KeyRedisLoaderOption(cfg.Redis.Key),
 		)))
 	}
@@ -120,5 +125,23 @@ func ParseHop(cfg *config.HopConfig) (hop.Hop, error) {
 			loader.TimeoutHTTPLoaderOption(cfg.HTTP.Timeout),
 		)))
 	}
+	if hc := cfg.HealthCheck; hc != nil {
+		opts = append(opts, xhop.HealthCheckOption(&xhop.HealthCheckOptions{
+			Type:     hc.Type,
+			Interval: hc.Interval,
+			Timeout:  hc.Timeout,
+			Rise:     hc.Rise,
+			Path:     hc.Path,
+			Status:   hc.Status,
+			Target:   hc.Target,
+		}))
+	}
+	if rc := cfg.Race; rc != nil {
+		count := rc.Count
+		if count <= 0 {
+			count = defaultRaceCount
+		}
+		opts = append(opts, xhop.RaceOption(count, rc.Delay))
+	}
 	return xhop.NewHop(opts...), nil
 }
@@ -8,6 +8,7 @@ import (
 	"github.com/go-gost/core/hosts"
 	"github.com/go-gost/core/logger"
 	"github.com/go-gost/x/config"
+	"github.com/go-gost/x/config/parsing"
 	xhosts "github.com/go-gost/x/hosts"
 	"github.com/go-gost/x/internal/loader"
 	"github.com/go-gost/x/internal/plugin"
@@ -36,7 +37,7 @@ func ParseHostMapper(cfg *config.HostsConfig) hosts.HostMapper {
 		default:
 			return xhosts.NewGRPCPlugin(
 				cfg.Name, cfg.Plugin.Addr,
-				plugin.TokenOption(cfg.Plugin.Token),
+				plugin.TokenOption(parsing.Secret(cfg.Plugin.Token)),
 				plugin.TLSConfigOption(tlsCfg),
 			)
 		}
@@ -74,14 +75,14 @@ func ParseHostMapper(cfg *config.HostsConfig) hosts.HostMapper {
 			opts = append(opts, xhosts.RedisLoaderOption(loader.RedisListLoader(
 				cfg.Redis.Addr,
 				loader.DBRedisLoaderOption(cfg.Redis.DB),
-				loader.PasswordRedisLoaderOption(cfg.Redis.Password),
+				loader.PasswordRedisLoaderOption(parsing.Secret(cfg.Redis.Password)),
 				loader.KeyRedisLoaderOption(cfg.Redis.Key),
 			)))
 		default: // redis set
 			opts = append(opts, xhosts.RedisLoaderOption(loader.RedisSetLoader(
 				cfg.Redis.Addr,
 				loader.DBRedisLoaderOption(cfg.Redis.DB),
-				loader.PasswordRedisLoaderOption(cfg.Redis.Password),
+				loader.PasswordRedisLoaderOption(parsing.Secret(cfg.Redis.Password)),
 				loader.KeyRedisLoaderOption(cfg.Redis.Key),
 			)))
 		}
@@ -7,6 +7,7 @@ import (
 	"github.com/go-gost/core/ingress"
 	"github.com/go-gost/core/logger"
 	"github.com/go-gost/x/config"
+	"github.com/go-gost/x/config/parsing"
 	xingress "github.com/go-gost/x/ingress"
 	"github.com/go-gost/x/internal/loader"
 	"github.com/go-gost/x/internal/plugin"
@@ -35,7 +36,7 @@ func ParseIngress(cfg *config.IngressConfig) ingress.Ingress {
 		default:
 			return xingress.NewGRPCPlugin(
 				cfg.Name, cfg.Plugin.Addr,
-				plugin.TokenOption(cfg.Plugin.Token),
+				plugin.TokenOption(parsing.Secret(cfg.Plugin.Token)),
 				plugin.TLSConfigOption(tlsCfg),
 			)
 		}
@@ -69,14 +70,14 @@ func ParseIngress(cfg *config.IngressConfig) ingress.Ingress {
 			opts = append(opts, xingress.RedisLoaderOption(loader.RedisSetLoader(
 				cfg.Redis.Addr,
 				loader.DBRedisLoaderOption(cfg.Redis.DB),
-				loader.PasswordRedisLoaderOption(cfg.Redis.Password),
+				loader.PasswordRedisLoaderOption(parsing.Secret(cfg.Redis.Password)),
 				loader.KeyRedisLoaderOption(cfg.Redis.Key),
 			)))
 		default: // redis hash
 			opts = append(opts, xingress.RedisLoaderOption(loader.RedisHashLoader(
 				cfg.Redis.Addr,
 				loader.DBRedisLoaderOption(cfg.Redis.DB),
-				loader.PasswordRedisLoaderOption(cfg.Redis.Password),
+				loader.PasswordRedisLoaderOption(parsing.Secret(cfg.Redis.Password)),
 				loader.KeyRedisLoaderOption(cfg.Redis.Key),
 			)))
 		}
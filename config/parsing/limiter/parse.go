@@ -9,6 +9,7 @@ import (
 	"github.com/go-gost/core/limiter/traffic"
 	"github.com/go-gost/core/logger"
 	"github.com/go-gost/x/config"
+	"github.com/go-gost/x/config/parsing"
 	"github.com/go-gost/x/internal/loader"
 	"github.com/go-gost/x/internal/plugin"
 	xconn "github.com/go-gost/x/limiter/conn"
@@ -39,7 +40,7 @@ func ParseTrafficLimiter(cfg *config.LimiterConfig) (lim traffic.TrafficLimiter)
 		default:
 			return xtraffic.NewGRPCPlugin(
 				cfg.Name, cfg.Plugin.Addr,
-				plugin.TokenOption(cfg.Plugin.Token),
+				plugin.TokenOption(parsing.Secret(cfg.Plugin.Token)),
 				plugin.TLSConfigOption(tlsCfg),
 			)
 		}
@@ -56,14 +57,14 @@ func ParseTrafficLimiter(cfg *config.LimiterConfig) (lim traffic.TrafficLimiter)
 			opts = append(opts, xtraffic.RedisLoaderOption(loader.RedisListLoader(
 				cfg.Redis.Addr,
 				loader.DBRedisLoaderOption(cfg.Redis.DB),
-				loader.PasswordRedisLoaderOption(cfg.Redis.Password),
+				loader.PasswordRedisLoaderOption(parsing.Secret(cfg.Redis.Password)),
 				loader.KeyRedisLoaderOption(cfg.Redis.Key),
 			)))
 		default: // redis set
 			opts = append(opts, xtraffic.RedisLoaderOption(loader.RedisSetLoader(
 				cfg.Redis.Addr,
 				loader.DBRedisLoaderOption(cfg.Redis.DB),
-				loader.PasswordRedisLoaderOption(cfg.Redis.Password),
+				loader.PasswordRedisLoaderOption(parsing.Secret(cfg.Redis.Password)),
 				loader.KeyRedisLoaderOption(cfg.Redis.Key),
 			)))
 		}
@@ -102,14 +103,14 @@ func ParseConnLimiter(cfg *config.LimiterConfig) (lim conn.ConnLimiter) {
 			opts = append(opts, xconn.RedisLoaderOption(loader.RedisListLoader(
 				cfg.Redis.Addr,
 				loader.DBRedisLoaderOption(cfg.Redis.DB),
-				loader.PasswordRedisLoaderOption(cfg.Redis.Password),
+				loader.PasswordRedisLoaderOption(parsing.Secret(cfg.Redis.Password)),
 				loader.KeyRedisLoaderOption(cfg.Redis.Key),
 			)))
 		default: // redis set
 			opts = append(opts, xconn.RedisLoaderOption(loader.RedisSetLoader(
 				cfg.Redis.Addr,
 				loader.DBRedisLoaderOption(cfg.Redis.DB),
-				loader.PasswordRedisLoaderOption(cfg.Redis.Password),
+				loader.PasswordRedisLoaderOption(parsing.Secret(cfg.Redis.Password)),
 				loader.KeyRedisLoaderOption(cfg.Redis.Key),
 			)))
 		}
@@ -148,14 +149,14 @@ func ParseRateLimiter(cfg *config.LimiterConfig) (lim rate.RateLimiter) {
 			opts = append(opts, xrate.RedisLoaderOption(loader.RedisListLoader(
 				cfg.Redis.Addr,
 				loader.DBRedisLoaderOption(cfg.Redis.DB),
-				loader.PasswordRedisLoaderOption(cfg.Redis.Password),
+				loader.PasswordRedisLoaderOption(parsing.Secret(cfg.Redis.Password)),
 				loader.KeyRedisLoaderOption(cfg.Redis.Key),
 			)))
 		default: // redis set
 			opts = append(opts, xrate.RedisLoaderOption(loader.RedisSetLoader(
 				cfg.Redis.Addr,
 				loader.DBRedisLoaderOption(cfg.Redis.DB),
-				loader.PasswordRedisLoaderOption(cfg.Redis.Password),
+				loader.PasswordRedisLoaderOption(parsing.Secret(cfg.Redis.Password)),
 				loader.KeyRedisLoaderOption(cfg.Redis.Key),
 			)))
 		}
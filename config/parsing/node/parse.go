@@ -2,6 +2,7 @@ package node
 
 import (
 	"fmt"
+	"io"
 	"net"
 	"strings"
 	"time"
@@ -21,8 +22,38 @@ import (
 	tls_util "github.com/go-gost/x/internal/util/tls"
 	mdx "github.com/go-gost/x/metadata"
 	"github.com/go-gost/x/registry"
+	xs "github.com/go-gost/x/selector"
 )
 
+const (
+	labelCloser = "_closer"
+)
+
+// closers closes the parts of a node which hold resources, such as the dialer and the connector.
+type closers []any
+
+func (c closers) Close() error {
+	for _, v := range c {
+		if closer, ok := v.(io.Closer); ok {
+			closer.Close()
+		}
+	}
+	return nil
+}
+
+// Close releases the resources of the node created by ParseNode.
+func Close(node *chain.Node) error {
+	if node == nil {
+		return nil
+	}
+	if md := node.Metadata(); md != nil {
+		if closer, ok := md.Get(labelCloser).(io.Closer); ok {
+			return closer.Close()
+		}
+	}
+	return nil
+}
+
 func ParseNode(hop string, cfg *config.NodeConfig) (*chain.Node, error) {
 	if cfg == nil {
 		return nil, nil
@@ -156,6 +187,13 @@ func ParseNode(hop string, cfg *config.NodeConfig) (*chain.Node, error) {
 		}
 	}
 
+	// the node metadata also carries the runtime state of the node.
+	if nm == nil {
+		nm = mdx.NewMetadata(nil)
+	}
+	xs.InitState(nm)
+	nm.Set(labelCloser, closers{d, cr})
+
 	opts := []chain.NodeOption{
 		chain.TransportNodeOption(tr),
 		chain.BypassNodeOption(bypass.BypassGroup(bypass_parser.List(cfg.Bypass, cfg.Bypasses...)...)),
@@ -188,7 +226,7 @@ func ParseNode(hop string, cfg *config.NodeConfig) (*chain.Node, error) {
 	if cfg.Auth != nil {
 		opts = append(opts, chain.AutherNodeOption(
 			xauth.NewAuthenticator(
-				xauth.AuthsOption(map[string]string{cfg.Auth.Username: cfg.Auth.Password}),
+				xauth.AuthsOption(map[string]string{cfg.Auth.Username: parsing.Secret(cfg.Auth.Password)}),
 				xauth.LoggerOption(logger.Default().WithFields(map[string]any{
 					"kind":     "node",
 					"node":     cfg.Name,
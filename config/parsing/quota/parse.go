@@ -0,0 +1,87 @@
+package quota
+
+import (
+	"fmt"
+
+	"github.com/go-gost/core/logger"
+	"github.com/go-gost/x/config"
+	"github.com/go-gost/x/config/parsing"
+	"github.com/go-gost/x/internal/loader"
+	"github.com/go-gost/x/quota"
+)
+
+func ParseQuota(cfg *config.QuotaConfig) (quota.Quota, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+
+	if !quota.ValidPeriod(cfg.Period) {
+		return nil, fmt.Errorf("invalid period: %s", cfg.Period)
+	}
+	for _, s := range cfg.Limits {
+		if err := quota.ValidateLimit(s); err != nil {
+			return nil, err
+		}
+	}
+
+	opts := []quota.Option{
+		quota.PeriodOption(cfg.Period),
+		quota.LimitsOption(cfg.Limits...),
+		quota.CutOption(cfg.Cut),
+		quota.ReloadPeriodOption(cfg.Reload),
+		quota.LoggerOption(logger.Default().WithFields(map[string]any{
+			"kind":  "quota",
+			"quota": cfg.Name,
+		})),
+	}
+	if store := parseStore(cfg.Store); store != nil {
+		opts = append(opts, quota.StoreOption(store, cfg.Store.Interval))
+	}
+	if cfg.File != nil && cfg.File.Path != "" {
+		opts = append(opts, quota.FileLoaderOption(loader.FileLoader(cfg.File.Path)))
+	}
+	if cfg.Redis != nil && cfg.Redis.Addr != "" {
+		switch cfg.Redis.Type {
+		case "list": // redis list
+			opts = append(opts, quota.RedisLoaderOption(loader.RedisListLoader(
+				cfg.Redis.Addr,
+				loader.DBRedisLoaderOption(cfg.Redis.DB),
+				loader.PasswordRedisLoaderOption(parsing.Secret(cfg.Redis.Password)),
+				loader.KeyRedisLoaderOption(cfg.Redis.Key),
+			)))
+		default: // redis set
+			opts = append(opts, quota.RedisLoaderOption(loader.RedisSetLoader(
+				cfg.Redis.Addr,
+				loader.DBRedisLoaderOption(cfg.Redis.DB),
+				loader.PasswordRedisLoaderOption(parsing.Secret(cfg.Redis.Password)),
+				loader.KeyRedisLoaderOption(cfg.Redis.Key),
+			)))
+		}
+	}
+	if cfg.HTTP != nil && cfg.HTTP.URL != "" {
+		opts = append(opts, quota.HTTPLoaderOption(loader.HTTPLoader(
+			cfg.HTTP.URL,
+			loader.TimeoutHTTPLoaderOption(cfg.HTTP.Timeout),
+		)))
+	}
+
+	return quota.NewQuota(opts...), nil
+}
+
+func parseStore(cfg *config.QuotaStoreConfig) quota.Store {
+	if cfg == nil {
+		return nil
+	}
+	if cfg.File != nil && cfg.File.Path != "" {
+		return quota.FileStore(cfg.File.Path)
+	}
+	if cfg.Redis != nil && cfg.Redis.Addr != "" {
+		return quota.RedisStore(
+			cfg.Redis.Addr,
+			quota.DBRedisStoreOption(cfg.Redis.DB),
+			quota.PasswordRedisStoreOption(parsing.Secret(cfg.Redis.Password)),
+			quota.KeyRedisStoreOption(cfg.Redis.Key),
+		)
+	}
+	return nil
+}
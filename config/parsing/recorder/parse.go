@@ -6,6 +6,7 @@ import (
 
 	"github.com/go-gost/core/recorder"
 	"github.com/go-gost/x/config"
+	"github.com/go-gost/x/config/parsing"
 	"github.com/go-gost/x/internal/plugin"
 	xrecorder "github.com/go-gost/x/recorder"
 )
@@ -33,7 +34,7 @@ func ParseRecorder(cfg *config.RecorderConfig) (r recorder.Recorder) {
 		default:
 			return xrecorder.NewGRPCPlugin(
 				cfg.Name, cfg.Plugin.Addr,
-				plugin.TokenOption(cfg.Plugin.Token),
+				plugin.TokenOption(parsing.Secret(cfg.Plugin.Token)),
 				plugin.TLSConfigOption(tlsCfg),
 			)
 		}
@@ -61,19 +62,19 @@ func ParseRecorder(cfg *config.RecorderConfig) (r recorder.Recorder) {
 			return xrecorder.RedisListRecorder(cfg.Redis.Addr,
 				xrecorder.DBRedisRecorderOption(cfg.Redis.DB),
 				xrecorder.KeyRedisRecorderOption(cfg.Redis.Key),
-				xrecorder.PasswordRedisRecorderOption(cfg.Redis.Password),
+				xrecorder.PasswordRedisRecorderOption(parsing.Secret(cfg.Redis.Password)),
 			)
 		case "sset": // sorted set
 			return xrecorder.RedisSortedSetRecorder(cfg.Redis.Addr,
 				xrecorder.DBRedisRecorderOption(cfg.Redis.DB),
 				xrecorder.KeyRedisRecorderOption(cfg.Redis.Key),
-				xrecorder.PasswordRedisRecorderOption(cfg.Redis.Password),
+				xrecorder.PasswordRedisRecorderOption(parsing.Secret(cfg.Redis.Password)),
 			)
 		default: // redis set
 			return xrecorder.RedisSetRecorder(cfg.Redis.Addr,
 				xrecorder.DBRedisRecorderOption(cfg.Redis.DB),
 				xrecorder.KeyRedisRecorderOption(cfg.Redis.Key),
-				xrecorder.PasswordRedisRecorderOption(cfg.Redis.Password),
+				xrecorder.PasswordRedisRecorderOption(parsing.Secret(cfg.Redis.Password)),
 			)
 		}
 	}
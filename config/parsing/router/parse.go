@@ -8,6 +8,7 @@ import (
 	"github.com/go-gost/core/logger"
 	"github.com/go-gost/core/router"
 	"github.com/go-gost/x/config"
+	"github.com/go-gost/x/config/parsing"
 	"github.com/go-gost/x/internal/loader"
 	"github.com/go-gost/x/internal/plugin"
 	xrouter "github.com/go-gost/x/router"
@@ -36,7 +37,7 @@ func ParseRouter(cfg *config.RouterConfig) router.Router {
 		default:
 			return xrouter.NewGRPCPlugin(
 				cfg.Name, cfg.Plugin.Addr,
-				plugin.TokenOption(cfg.Plugin.Token),
+				plugin.TokenOption(parsing.Secret(cfg.Plugin.Token)),
 				plugin.TLSConfigOption(tlsCfg),
 			)
 		}
@@ -75,21 +76,21 @@ func ParseRouter(cfg *config.RouterConfig) router.Router {
 			opts = append(opts, xrouter.RedisLoaderOption(loader.RedisListLoader(
 				cfg.Redis.Addr,
 				loader.DBRedisLoaderOption(cfg.Redis.DB),
-				loader.PasswordRedisLoaderOption(cfg.Redis.Password),
+				loader.PasswordRedisLoaderOption(parsing.Secret(cfg.Redis.Password)),
 				loader.KeyRedisLoaderOption(cfg.Redis.Key),
 			)))
 		case "set": // redis set
 			opts = append(opts, xrouter.RedisLoaderOption(loader.RedisSetLoader(
 				cfg.Redis.Addr,
 				loader.DBRedisLoaderOption(cfg.Redis.DB),
-				loader.PasswordRedisLoaderOption(cfg.Redis.Password),
+				loader.PasswordRedisLoaderOption(parsing.Secret(cfg.Redis.Password)),
 				loader.KeyRedisLoaderOption(cfg.Redis.Key),
 			)))
 		default: // redis hash
 			opts = append(opts, xrouter.RedisLoaderOption(loader.RedisHashLoader(
 				cfg.Redis.Addr,
 				loader.DBRedisLoaderOption(cfg.Redis.DB),
-				loader.PasswordRedisLoaderOption(cfg.Redis.Password),
+				loader.PasswordRedisLoaderOption(parsing.Secret(cfg.Redis.Password)),
 				loader.KeyRedisLoaderOption(cfg.Redis.Key),
 			)))
 		}
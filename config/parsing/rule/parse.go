@@ -0,0 +1,84 @@
+package rule
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+
+	"github.com/go-gost/core/logger"
+	"github.com/go-gost/x/config"
+	chain_parser "github.com/go-gost/x/config/parsing/chain"
+	xnet "github.com/go-gost/x/internal/net"
+	"github.com/go-gost/x/rule"
+)
+
+func ParseRules(cfg *config.RulesConfig) (rule.Rules, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+
+	var rules []*rule.Rule
+	for i, rc := range cfg.Rules {
+		if rc == nil {
+			continue
+		}
+		name := rc.Name
+		if name == "" {
+			name = strconv.Itoa(i)
+		}
+		if err := validate(rc); err != nil {
+			return nil, fmt.Errorf("rule %s: %w", name, err)
+		}
+
+		rules = append(rules, &rule.Rule{
+			Name:      name,
+			Domains:   rc.Domains,
+			Wildcards: rc.Wildcards,
+			CIDRs:     rc.CIDRs,
+			Ports:     rc.Ports,
+			Networks:  rc.Networks,
+			Clients:   rc.Clients,
+			Protocols: rc.Protocols,
+			Target: rule.Target{
+				Chain:  chain_parser.ParseChainGroup(rc.Chain, rc.ChainGroup),
+				Reject: rc.Reject,
+			},
+		})
+	}
+
+	return rule.NewRules(
+		rule.RulesOption(rules),
+		rule.LoggerOption(logger.Default().WithFields(map[string]any{
+			"kind":  "rules",
+			"rules": cfg.Name,
+		})),
+	), nil
+}
+
+func validate(cfg *config.RuleConfig) error {
+	if cfg.Reject && (cfg.Chain != "" || cfg.ChainGroup != nil) {
+		return fmt.Errorf("reject can not be used with chain")
+	}
+	for _, s := range cfg.CIDRs {
+		if net.ParseIP(s) != nil {
+			continue
+		}
+		if _, _, err := net.ParseCIDR(s); err != nil {
+			return fmt.Errorf("invalid CIDR %s", s)
+		}
+	}
+	for _, s := range cfg.Ports {
+		pr := &xnet.PortRange{}
+		if err := pr.Parse(s); err != nil {
+			return fmt.Errorf("invalid port %s", s)
+		}
+	}
+	for _, s := range cfg.Networks {
+		switch s {
+		case "tcp", "udp":
+		default:
+			return fmt.Errorf("invalid network %s", s)
+		}
+	}
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/go-gost/core/sd"
 	"github.com/go-gost/x/config"
+	"github.com/go-gost/x/config/parsing"
 	"github.com/go-gost/x/internal/plugin"
 	xsd "github.com/go-gost/x/sd"
 )
@@ -32,7 +33,7 @@ func ParseSD(cfg *config.SDConfig) sd.SD {
 	default:
 		return xsd.NewGRPCPlugin(
 			cfg.Name, cfg.Plugin.Addr,
-			plugin.TokenOption(cfg.Plugin.Token),
+			plugin.TokenOption(parsing.Secret(cfg.Plugin.Token)),
 			plugin.TLSConfigOption(tlsCfg),
 		)
 	}
@@ -0,0 +1,17 @@
+package parsing
+
+import (
+	"github.com/go-gost/core/logger"
+	"github.com/go-gost/x/config"
+)
+
+// Secret resolves the secret reference s (env:NAME or file:PATH),
+// s is returned as is if it is not a reference,
+// and an empty string is returned if the reference can not be resolved.
+func Secret(s string) string {
+	v, err := config.ResolveSecret(s)
+	if err != nil {
+		logger.Default().Error(err)
+	}
+	return v
+}
@@ -21,13 +21,19 @@ func ParseChainSelector(cfg *config.SelectorConfig) selector.Selector[chain.Chai
 	case "fifo", "ha":
 		strategy = xs.FIFOStrategy[chain.Chainer]()
 	case "hash":
-		strategy = xs.HashStrategy[chain.Chainer]()
+		strategy = xs.HashKeyStrategy[chain.Chainer](cfg.HashKey)
+	case "chash", "consistent":
+		strategy = xs.ConsistentHashStrategy[chain.Chainer](cfg.HashKey)
+	case "least", "leastconn":
+		strategy = xs.LeastConnStrategy[chain.Chainer]()
+	case "latency":
+		strategy = xs.LatencyStrategy[chain.Chainer]()
 	default:
 		strategy = xs.RoundRobinStrategy[chain.Chainer]()
 	}
 	return xs.NewSelector(
 		strategy,
-		xs.FailFilter[chain.Chainer](cfg.MaxFails, cfg.FailTimeout),
+		failFilter[chain.Chainer](cfg),
 		xs.BackupFilter[chain.Chainer](),
 	)
 }
@@ -46,14 +52,20 @@ func ParseNodeSelector(cfg *config.SelectorConfig) selector.Selector[*chain.Node
 	case "fifo", "ha":
 		strategy = xs.FIFOStrategy[*chain.Node]()
 	case "hash":
-		strategy = xs.HashStrategy[*chain.Node]()
+		strategy = xs.HashKeyStrategy[*chain.Node](cfg.HashKey)
+	case "chash", "consistent":
+		strategy = xs.ConsistentHashStrategy[*chain.Node](cfg.HashKey)
+	case "least", "leastconn":
+		strategy = xs.LeastConnStrategy[*chain.Node]()
+	case "latency":
+		strategy = xs.LatencyStrategy[*chain.Node]()
 	default:
 		strategy = xs.RoundRobinStrategy[*chain.Node]()
 	}
 
 	return xs.NewSelector(
 		strategy,
-		xs.FailFilter[*chain.Node](cfg.MaxFails, cfg.FailTimeout),
+		failFilter[*chain.Node](cfg),
 		xs.BackupFilter[*chain.Node](),
 	)
 }
@@ -73,3 +85,19 @@ func DefaultChainSelector() selector.Selector[chain.Chainer] {
 		xs.BackupFilter[chain.Chainer](),
 	)
 }
+
+// failFilter returns the circuit breaker filter if it is configured,
+// otherwise the fail count based filter.
+func failFilter[T any](cfg *config.SelectorConfig) selector.Filter[T] {
+	cb := cfg.CircuitBreaker
+	if cb == nil {
+		return xs.FailFilter[T](cfg.MaxFails, cfg.FailTimeout)
+	}
+	return xs.CircuitBreakerFilter[T](xs.BreakerOptions{
+		Window:         cb.Window,
+		MinRequests:    cb.MinRequests,
+		FailureRate:    cb.FailureRate,
+		OpenTimeout:    cb.OpenTimeout,
+		HalfOpenTrials: cb.HalfOpenTrials,
+	})
+}
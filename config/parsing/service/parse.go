@@ -2,6 +2,7 @@ package service
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/go-gost/core/admission"
 	"github.com/go-gost/core/auth"
@@ -13,19 +14,21 @@ import (
 	"github.com/go-gost/core/logger"
 	mdutil "github.com/go-gost/core/metadata/util"
 	"github.com/go-gost/core/recorder"
-	"github.com/go-gost/core/selector"
 	"github.com/go-gost/core/service"
+	xauth "github.com/go-gost/x/auth"
+	xbypass "github.com/go-gost/x/bypass"
 	xchain "github.com/go-gost/x/chain"
 	"github.com/go-gost/x/config"
 	"github.com/go-gost/x/config/parsing"
 	admission_parser "github.com/go-gost/x/config/parsing/admission"
 	auth_parser "github.com/go-gost/x/config/parsing/auth"
 	bypass_parser "github.com/go-gost/x/config/parsing/bypass"
+	chain_parser "github.com/go-gost/x/config/parsing/chain"
 	hop_parser "github.com/go-gost/x/config/parsing/hop"
-	selector_parser "github.com/go-gost/x/config/parsing/selector"
 	xnet "github.com/go-gost/x/internal/net"
 	tls_util "github.com/go-gost/x/internal/util/tls"
 	"github.com/go-gost/x/metadata"
+	xquota "github.com/go-gost/x/quota"
 	"github.com/go-gost/x/registry"
 	xservice "github.com/go-gost/x/service"
 )
@@ -94,6 +97,7 @@ func ParseService(cfg *config.ServiceConfig) (service.Service, error) {
 	ifce := cfg.Interface
 	var preUp, preDown, postUp, postDown []string
 	var ignoreChain bool
+	var gracePeriod time.Duration
 	if cfg.Metadata != nil {
 		md := metadata.NewMetadata(cfg.Metadata)
 		ppv = mdutil.GetInt(md, parsing.MDKeyProxyProtocol)
@@ -110,6 +114,7 @@ func ParseService(cfg *config.ServiceConfig) (service.Service, error) {
 		postUp = mdutil.GetStrings(md, parsing.MDKeyPostUp)
 		postDown = mdutil.GetStrings(md, parsing.MDKeyPostDown)
 		ignoreChain = mdutil.GetBool(md, parsing.MDKeyIgnoreChain)
+		gracePeriod = mdutil.GetDuration(md, parsing.MDKeyGracePeriod)
 	}
 
 	listenOpts := []listener.Option{
@@ -126,7 +131,7 @@ func ParseService(cfg *config.ServiceConfig) (service.Service, error) {
 	}
 	if !ignoreChain {
 		listenOpts = append(listenOpts,
-			listener.ChainOption(chainGroup(cfg.Listener.Chain, cfg.Listener.ChainGroup)),
+			listener.ChainOption(chain_parser.ParseChainGroup(cfg.Listener.Chain, cfg.Listener.ChainGroup)),
 		)
 	}
 
@@ -174,6 +179,11 @@ func ParseService(cfg *config.ServiceConfig) (service.Service, error) {
 	if len(authers) > 0 {
 		auther = auth.AuthenticatorGroup(authers...)
 	}
+	// the connections of the authenticated clients are limited by the user and group limits
+	// of the connection limiter, which are acquired once the clients are authenticated.
+	if cfg.CLimiter != "" {
+		auther = xauth.ConnLimitAuthenticator(auther, registry.ConnLimiterRegistry().Get(cfg.CLimiter), handlerLogger)
+	}
 
 	var recorders []recorder.RecorderObject
 	for _, r := range cfg.Recorders {
@@ -200,22 +210,52 @@ func ParseService(cfg *config.ServiceConfig) (service.Service, error) {
 		chain.LoggerRouterOption(handlerLogger),
 	}
 	if !ignoreChain {
+		chainer := chain_parser.ParseChainGroup(cfg.Handler.Chain, cfg.Handler.ChainGroup)
+		if cfg.Handler.Rules != "" {
+			chainer = xchain.NewRuleChain(registry.RulesRegistry().Get(cfg.Handler.Rules), chainer)
+		}
+		if rc := cfg.Race; rc != nil {
+			chainer = xchain.NewRaceChain(chainer,
+				registry.ResolverRegistry().Get(cfg.Resolver),
+				registry.HostsRegistry().Get(cfg.Hosts),
+				rc.Count, rc.Delay,
+			)
+		}
 		routerOpts = append(routerOpts,
-			chain.ChainRouterOption(chainGroup(cfg.Handler.Chain, cfg.Handler.ChainGroup)),
+			chain.ChainRouterOption(chainer),
 		)
 	}
 	router := chain.NewRouter(routerOpts...)
 
+	// the destinations denied by the ACL are rejected by the handlers as the bypassed ones.
+	bypasses := bypass_parser.List(cfg.Bypass, cfg.Bypasses...)
+	if cfg.Handler.ACL != "" {
+		bypasses = append(bypasses, xbypass.NewACLBypass(
+			registry.ACLRegistry().Get(cfg.Handler.ACL),
+			registry.ResolverRegistry().Get(cfg.Resolver),
+			registry.HostsRegistry().Get(cfg.Hosts),
+		))
+	}
+
+	// the traffic of the clients is counted by the traffic limiter of the handler,
+	// and the clients exhausted their quotas are rejected as the bypassed ones.
+	trafficLimiter := registry.TrafficLimiterRegistry().Get(cfg.Handler.Limiter)
+	if cfg.Handler.Quota != "" {
+		q := registry.QuotaRegistry().Get(cfg.Handler.Quota)
+		trafficLimiter = xquota.NewTrafficLimiter(q, cfg.Name, trafficLimiter)
+		bypasses = append(bypasses, xbypass.NewQuotaBypass(q))
+	}
+
 	var h handler.Handler
 	if rf := registry.HandlerRegistry().Get(cfg.Handler.Type); rf != nil {
 		h = rf(
 			handler.RouterOption(router),
 			handler.AutherOption(auther),
 			handler.AuthOption(auth_parser.Info(cfg.Handler.Auth)),
-			handler.BypassOption(bypass.BypassGroup(bypass_parser.List(cfg.Bypass, cfg.Bypasses...)...)),
+			handler.BypassOption(bypass.BypassGroup(bypasses...)),
 			handler.TLSConfigOption(tlsConfig),
 			handler.RateLimiterOption(registry.RateLimiterRegistry().Get(cfg.RLimiter)),
-			handler.TrafficLimiterOption(registry.TrafficLimiterRegistry().Get(cfg.Handler.Limiter)),
+			handler.TrafficLimiterOption(trafficLimiter),
 			handler.LoggerOption(handlerLogger),
 			handler.ServiceOption(cfg.Name),
 		)
@@ -247,6 +287,7 @@ func ParseService(cfg *config.ServiceConfig) (service.Service, error) {
 		xservice.PostUpOption(postUp),
 		xservice.PostDownOption(postDown),
 		xservice.RecordersOption(recorders...),
+		xservice.GracePeriodOption(gracePeriod),
 		xservice.LoggerOption(serviceLogger),
 	)
 
@@ -260,8 +301,9 @@ func parseForwarder(cfg *config.ForwarderConfig) (hop.Hop, error) {
 	}
 
 	hc := config.HopConfig{
-		Name:     cfg.Name,
-		Selector: cfg.Selector,
+		Name:        cfg.Name,
+		Selector:    cfg.Selector,
+		HealthCheck: cfg.HealthCheck,
 	}
 	for _, node := range cfg.Nodes {
 		if node != nil {
@@ -295,30 +337,3 @@ func parseForwarder(cfg *config.ForwarderConfig) (hop.Hop, error) {
 	}
 	return registry.HopRegistry().Get(hc.Name), nil
 }
-
-func chainGroup(name string, group *config.ChainGroupConfig) chain.Chainer {
-	var chains []chain.Chainer
-	var sel selector.Selector[chain.Chainer]
-
-	if c := registry.ChainRegistry().Get(name); c != nil {
-		chains = append(chains, c)
-	}
-	if group != nil {
-		for _, s := range group.Chains {
-			if c := registry.ChainRegistry().Get(s); c != nil {
-				chains = append(chains, c)
-			}
-		}
-		sel = selector_parser.ParseChainSelector(group.Selector)
-	}
-	if len(chains) == 0 {
-		return nil
-	}
-
-	if sel == nil {
-		sel = selector_parser.DefaultChainSelector()
-	}
-
-	return xchain.NewChainGroup(chains...).
-		WithSelector(sel)
-}
@@ -0,0 +1,283 @@
+package revision
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-gost/core/logger"
+	"github.com/go-gost/x/config"
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+const (
+	DefaultMaxRevisions = 10
+)
+
+var (
+	ErrNotFound = errors.New("revision not found")
+)
+
+// Revision is a snapshot of the config.
+type Revision struct {
+	ID   int       `json:"id"`
+	Time time.Time `json:"time"`
+	// User is the user who made the change.
+	User string `json:"user,omitempty"`
+	// Op describes the change, such as the API request or reload.
+	Op string `json:"op,omitempty"`
+	// the config in JSON.
+	Data json.RawMessage `json:"data,omitempty"`
+}
+
+// Config returns the config of the revision.
+func (r *Revision) Config() (*config.Config, error) {
+	cfg := &config.Config{}
+	if err := json.Unmarshal(r.Data, cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+type options struct {
+	max int
+	dir string
+}
+
+type Option func(opts *options)
+
+// MaxOption sets the maximum number of revisions to keep.
+func MaxOption(max int) Option {
+	return func(opts *options) {
+		opts.max = max
+	}
+}
+
+// DirOption sets the directory to persist the revisions.
+func DirOption(dir string) Option {
+	return func(opts *options) {
+		opts.dir = dir
+	}
+}
+
+var (
+	store = &revisionStore{
+		options: options{
+			max: DefaultMaxRevisions,
+		},
+	}
+)
+
+type revisionStore struct {
+	revisions []*Revision
+	nextID    int
+	options   options
+	mu        sync.RWMutex
+}
+
+// Init sets the options of the revision history,
+// the revisions persisted in the directory are loaded.
+func Init(opts ...Option) error {
+	options := options{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.max <= 0 {
+		options.max = DefaultMaxRevisions
+	}
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	store.options = options
+	store.revisions = nil
+	store.nextID = 1
+
+	if options.dir == "" {
+		return nil
+	}
+	if err := os.MkdirAll(options.dir, 0o700); err != nil {
+		return err
+	}
+
+	files, err := filepath.Glob(filepath.Join(options.dir, "*.json"))
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		if _, err := strconv.Atoi(strings.TrimSuffix(filepath.Base(file), ".json")); err != nil {
+			continue
+		}
+		b, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		rev := &Revision{}
+		if err := json.Unmarshal(b, rev); err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+		store.revisions = append(store.revisions, rev)
+	}
+	sort.Slice(store.revisions, func(i, j int) bool {
+		return store.revisions[i].ID < store.revisions[j].ID
+	})
+	if n := len(store.revisions); n > 0 {
+		store.nextID = store.revisions[n-1].ID + 1
+	}
+	store.prune()
+
+	return nil
+}
+
+// Record adds the config cfg as a new revision and returns its ID,
+// nothing is recorded if cfg is the same as the latest revision, and the ID of the latest revision is returned.
+// It returns 0 if the config can not be recorded.
+func Record(cfg *config.Config, user, op string) int {
+	if cfg == nil {
+		return 0
+	}
+
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		logger.Default().Errorf("revision: %v", err)
+		return 0
+	}
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	if n := len(store.revisions); n > 0 && bytes.Equal(store.revisions[n-1].Data, data) {
+		return store.revisions[n-1].ID
+	}
+
+	if store.nextID == 0 {
+		store.nextID = 1
+	}
+	rev := &Revision{
+		ID:   store.nextID,
+		Time: time.Now(),
+		User: user,
+		Op:   op,
+		Data: data,
+	}
+	store.nextID++
+	store.revisions = append(store.revisions, rev)
+
+	if dir := store.options.dir; dir != "" {
+		if err := save(dir, rev); err != nil {
+			logger.Default().Errorf("revision: %v", err)
+		}
+	}
+	store.prune()
+
+	return rev.ID
+}
+
+// prune removes the revisions exceeding the maximum number.
+func (s *revisionStore) prune() {
+	n := len(s.revisions) - s.options.max
+	if n <= 0 {
+		return
+	}
+	if dir := s.options.dir; dir != "" {
+		for _, rev := range s.revisions[:n] {
+			os.Remove(filepath.Join(dir, fmt.Sprintf("%d.json", rev.ID)))
+		}
+	}
+	s.revisions = append([]*Revision(nil), s.revisions[n:]...)
+}
+
+func save(dir string, rev *Revision) error {
+	b, err := json.Marshal(rev)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, fmt.Sprintf("%d.json", rev.ID)), b, 0o600)
+}
+
+// List returns all the revisions kept, from the oldest to the latest.
+func List() []*Revision {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+
+	return append([]*Revision(nil), store.revisions...)
+}
+
+// Get returns the revision by id.
+func Get(id int) *Revision {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+
+	for _, rev := range store.revisions {
+		if rev.ID == id {
+			return rev
+		}
+	}
+	return nil
+}
+
+// Previous returns the revision before the revision id, or nil if there is none.
+func Previous(id int) *Revision {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+
+	var prev *Revision
+	for _, rev := range store.revisions {
+		if rev.ID >= id {
+			break
+		}
+		prev = rev
+	}
+	return prev
+}
+
+// Diff returns the unified diff in YAML format from the revision from to the revision to,
+// the revision from can be nil. The plaintext secrets are redacted.
+func Diff(from, to *Revision) (string, error) {
+	if to == nil {
+		return "", ErrNotFound
+	}
+
+	var a, b string
+	var fromName string
+	if from != nil {
+		s, err := yamlString(from)
+		if err != nil {
+			return "", err
+		}
+		a = s
+		fromName = fmt.Sprintf("revision %d", from.ID)
+	}
+	b, err := yamlString(to)
+	if err != nil {
+		return "", err
+	}
+
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(a),
+		B:        difflib.SplitLines(b),
+		FromFile: fromName,
+		ToFile:   fmt.Sprintf("revision %d", to.ID),
+		Context:  3,
+	})
+}
+
+func yamlString(rev *Revision) (string, error) {
+	cfg, err := rev.Config()
+	if err != nil {
+		return "", err
+	}
+	buf := &bytes.Buffer{}
+	if err := cfg.Redacted().Write(buf, "yaml"); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
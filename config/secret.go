@@ -0,0 +1,208 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const (
+	secretEnvPrefix  = "env:"
+	secretFilePrefix = "file:"
+
+	// RedactedSecret is the placeholder of the redacted secrets.
+	RedactedSecret = "******"
+)
+
+// IsSecretRef reports whether s is a secret reference,
+// in the form of env:NAME (environment variable) or file:PATH (content of file).
+func IsSecretRef(s string) bool {
+	return strings.HasPrefix(s, secretEnvPrefix) || strings.HasPrefix(s, secretFilePrefix)
+}
+
+// ResolveSecret returns the value of the secret reference s,
+// s is returned as is if it is not a reference.
+func ResolveSecret(s string) (string, error) {
+	switch {
+	case strings.HasPrefix(s, secretEnvPrefix):
+		name := strings.TrimPrefix(s, secretEnvPrefix)
+		v, ok := os.LookupEnv(name)
+		if !ok {
+			return "", fmt.Errorf("secret %s: environment variable %s is not set", s, name)
+		}
+		return v, nil
+	case strings.HasPrefix(s, secretFilePrefix):
+		b, err := os.ReadFile(strings.TrimPrefix(s, secretFilePrefix))
+		if err != nil {
+			return "", fmt.Errorf("secret %s: %w", s, err)
+		}
+		return strings.TrimRight(string(b), "\r\n"), nil
+	default:
+		return s, nil
+	}
+}
+
+// Redacted returns a copy of the config with the plaintext secrets
+// (passwords and tokens) replaced by RedactedSecret, the secret references are preserved.
+func (c *Config) Redacted() *Config {
+	cfg := &Config{}
+	if c == nil {
+		return cfg
+	}
+	// work on a deep copy, the config c may be shared.
+	b, err := json.Marshal(c)
+	if err != nil {
+		return cfg
+	}
+	json.Unmarshal(b, cfg)
+	redact(reflect.ValueOf(cfg))
+	return cfg
+}
+
+// CheckSecrets resolves all the secret references in the config, and reports the unresolvable ones.
+// The metadata are not checked, as the references in them are not resolved.
+func (c *Config) CheckSecrets() error {
+	var errs []error
+	walkSecrets(reflect.ValueOf(c), "", func(path, name string, v reflect.Value) {
+		if name == metadataSecret {
+			return
+		}
+		if _, err := ResolveSecret(v.String()); err != nil {
+			errs = append(errs, err)
+		}
+	})
+	return errors.Join(errs...)
+}
+
+// Unredact replaces the RedactedSecret placeholders in the config with the secrets
+// at the same places of the config cur, so the redacted config can be applied back.
+// The objects in the lists are matched by their names.
+// It fails if a placeholder has no secret to be replaced with.
+func (c *Config) Unredact(cur *Config) error {
+	secrets := make(map[string]string)
+	walkSecrets(reflect.ValueOf(cur), "", func(path, name string, v reflect.Value) {
+		secrets[path] = v.String()
+	})
+
+	var errs []error
+	walkSecrets(reflect.ValueOf(c), "", func(path, name string, v reflect.Value) {
+		if v.String() != RedactedSecret {
+			return
+		}
+		if s, ok := secrets[path]; ok && s != RedactedSecret {
+			v.SetString(s)
+			return
+		}
+		errs = append(errs, fmt.Errorf("%s: redacted secret without the current value", strings.TrimPrefix(path, ".")))
+	})
+	return errors.Join(errs...)
+}
+
+// redact replaces the plaintext passwords and tokens.
+func redact(v reflect.Value) {
+	walkSecrets(v, "", func(path, name string, v reflect.Value) {
+		if name == "KeyFile" {
+			return
+		}
+		if s := v.String(); s != "" && !IsSecretRef(s) {
+			v.SetString(RedactedSecret)
+		}
+	})
+}
+
+const (
+	// the name of the secrets in the metadata and the headers.
+	metadataSecret = "Metadata"
+)
+
+// isSecretKey reports whether the key of the metadata or the header holds a secret.
+func isSecretKey(key string) bool {
+	key = strings.ToLower(key)
+	if i := strings.LastIndexByte(key, '.'); i >= 0 {
+		key = key[i+1:]
+	}
+	switch key {
+	case "password", "passwd", "token", "secret", "key", "psk", "auth", "authorization", "proxy-authorization":
+		return true
+	}
+	return false
+}
+
+// walkSecrets calls fn for each string field which can hold a secret, the fields named Password, Token or KeyFile,
+// and the string values of the metadata and the headers with the keys for secrets, see isSecretKey.
+// The path of the field is passed to fn along with its name, the objects in the lists are identified by their names.
+func walkSecrets(v reflect.Value, path string, fn func(path, name string, v reflect.Value)) {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if !v.IsNil() {
+			walkSecrets(v.Elem(), path, fn)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			walkSecrets(v.Index(i), fmt.Sprintf("%s[%s]", path, elemID(v.Index(i), i)), fn)
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			key, value := iter.Key(), iter.Value()
+			if key.Kind() != reflect.String {
+				continue
+			}
+			p := path + "." + key.String()
+			if value.Kind() == reflect.Interface && !value.IsNil() {
+				value = value.Elem()
+			}
+			if value.Kind() != reflect.String {
+				walkSecrets(value, p, fn)
+				continue
+			}
+			if !isSecretKey(key.String()) {
+				continue
+			}
+			// the values of the map are not addressable, so a copy is passed to fn and set back.
+			s := reflect.New(value.Type()).Elem()
+			s.SetString(value.String())
+			fn(p, metadataSecret, s)
+			if s.String() != value.String() {
+				v.SetMapIndex(key, s)
+			}
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < v.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			p := path + "." + field.Name
+			switch field.Name {
+			case "Password", "Token", "KeyFile":
+				if v.Field(i).Kind() == reflect.String {
+					fn(p, field.Name, v.Field(i))
+				}
+			default:
+				walkSecrets(v.Field(i), p, fn)
+			}
+		}
+	}
+}
+
+// elemID returns the name of the object v in a list, or its index i if it has no name.
+func elemID(v reflect.Value, i int) string {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return strconv.Itoa(i)
+		}
+		v = v.Elem()
+	}
+	if v.Kind() == reflect.Struct {
+		if name := v.FieldByName("Name"); name.IsValid() && name.Kind() == reflect.String && name.String() != "" {
+			return strconv.Quote(name.String())
+		}
+	}
+	return strconv.Itoa(i)
+}
@@ -2,9 +2,7 @@ package mtcp
 
 import (
 	"context"
-	"errors"
 	"net"
-	"sync"
 	"time"
 
 	"github.com/go-gost/core/dialer"
@@ -19,11 +17,10 @@ func init() {
 }
 
 type mtcpDialer struct {
-	sessions     map[string]*muxSession
-	sessionMutex sync.Mutex
-	logger       logger.Logger
-	md           metadata
-	options      dialer.Options
+	pool    *mux.SessionPool
+	logger  logger.Logger
+	md      metadata
+	options dialer.Options
 }
 
 func NewDialer(opts ...dialer.Option) dialer.Dialer {
@@ -33,9 +30,8 @@ func NewDialer(opts ...dialer.Option) dialer.Dialer {
 	}
 
 	return &mtcpDialer{
-		sessions: make(map[string]*muxSession),
-		logger:   options.Logger,
-		options:  options,
+		logger:  options.Logger,
+		options: options,
 	}
 }
 
@@ -44,6 +40,7 @@ func (d *mtcpDialer) Init(md md.Metadata) (err error) {
 		return
 	}
 
+	d.pool = mux.NewSessionPool(d.md.poolCfg, d.logger)
 	return nil
 }
 
@@ -52,31 +49,49 @@ func (d *mtcpDialer) Multiplex() bool {
 	return true
 }
 
-func (d *mtcpDialer) Dial(ctx context.Context, addr string, opts ...dialer.DialOption) (conn net.Conn, err error) {
-	d.sessionMutex.Lock()
-	defer d.sessionMutex.Unlock()
+// Close implements io.Closer interface.
+func (d *mtcpDialer) Close() error {
+	if d.pool != nil {
+		return d.pool.Close()
+	}
+	return nil
+}
 
-	session, ok := d.sessions[addr]
-	if session != nil && session.IsClosed() {
-		delete(d.sessions, addr) // session is dead
-		ok = false
+func (d *mtcpDialer) Dial(ctx context.Context, addr string, opts ...dialer.DialOption) (net.Conn, error) {
+	conn, err := d.pool.Get(ctx, addr)
+	if err != nil {
+		return nil, err
+	}
+	if conn != nil {
+		return conn, nil
 	}
-	if !ok {
-		var options dialer.DialOptions
-		for _, opt := range opts {
-			opt(&options)
-		}
 
-		conn, err = options.NetDialer.Dial(ctx, "tcp", addr)
-		if err != nil {
-			return
-		}
+	var options dialer.DialOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
 
-		session = &muxSession{conn: conn}
-		d.sessions[addr] = session
+	if netd := mux.WarmDialer(options.NetDialer); netd != nil {
+		d.pool.Warm(addr, func(ctx context.Context) (*mux.Session, error) {
+			conn, err := netd.Dial(ctx, "tcp", addr)
+			if err != nil {
+				return nil, err
+			}
+			session, err := d.initSession(ctx, conn)
+			if err != nil {
+				conn.Close()
+				return nil, err
+			}
+			return session, nil
+		})
 	}
 
-	return session.conn, err
+	conn, err = options.NetDialer.Dial(ctx, "tcp", addr)
+	if err != nil {
+		d.pool.Cancel(addr)
+		return nil, err
+	}
+	return conn, nil
 }
 
 // Handshake implements dialer.Handshaker
@@ -86,46 +101,35 @@ func (d *mtcpDialer) Handshake(ctx context.Context, conn net.Conn, options ...di
 		option(opts)
 	}
 
-	d.sessionMutex.Lock()
-	defer d.sessionMutex.Unlock()
-
-	if d.md.handshakeTimeout > 0 {
-		conn.SetDeadline(time.Now().Add(d.md.handshakeTimeout))
-		defer conn.SetDeadline(time.Time{})
-	}
-
-	session, ok := d.sessions[opts.Addr]
-	if session != nil && session.conn != conn {
-		conn.Close()
-		return nil, errors.New("mtls: unrecognized connection")
-	}
-
-	if !ok || session.session == nil {
+	session := d.pool.Session(opts.Addr, conn)
+	if session == nil {
 		s, err := d.initSession(ctx, conn)
 		if err != nil {
 			d.logger.Error(err)
 			conn.Close()
-			delete(d.sessions, opts.Addr)
+			d.pool.Cancel(opts.Addr)
+			return nil, err
+		}
+		if session, err = d.pool.Add(opts.Addr, s); err != nil {
 			return nil, err
 		}
-		session = s
-		d.sessions[opts.Addr] = session
 	}
+
 	cc, err := session.GetConn()
 	if err != nil {
-		session.Close()
-		delete(d.sessions, opts.Addr)
+		d.pool.Remove(opts.Addr, session)
 		return nil, err
 	}
 
 	return cc, nil
 }
 
-func (d *mtcpDialer) initSession(ctx context.Context, conn net.Conn) (*muxSession, error) {
-	// stream multiplex
-	session, err := mux.ClientSession(conn, d.md.muxCfg)
-	if err != nil {
-		return nil, err
+func (d *mtcpDialer) initSession(ctx context.Context, conn net.Conn) (*mux.Session, error) {
+	if d.md.handshakeTimeout > 0 {
+		conn.SetDeadline(time.Now().Add(d.md.handshakeTimeout))
+		defer conn.SetDeadline(time.Time{})
 	}
-	return &muxSession{conn: conn, session: session}, nil
+
+	// stream multiplex
+	return mux.ClientSession(conn, d.md.muxCfg)
 }
@@ -11,6 +11,7 @@ import (
 type metadata struct {
 	handshakeTimeout time.Duration
 	muxCfg           *mux.Config
+	poolCfg          *mux.PoolConfig
 }
 
 func (d *mtcpDialer) parseMetadata(md mdata.Metadata) (err error) {
@@ -25,6 +26,13 @@ func (d *mtcpDialer) parseMetadata(md mdata.Metadata) (err error) {
 		MaxReceiveBuffer:  mdutil.GetInt(md, "mux.maxReceiveBuffer"),
 		MaxStreamBuffer:   mdutil.GetInt(md, "mux.maxStreamBuffer"),
 	}
+
+	d.md.poolCfg = &mux.PoolConfig{
+		Sessions:      mdutil.GetInt(md, "mux.sessions"),
+		Warm:          mdutil.GetBool(md, "mux.warm"),
+		CheckInterval: mdutil.GetDuration(md, "mux.checkInterval"),
+		IdleTimeout:   mdutil.GetDuration(md, "mux.idleTimeout"),
+	}
 	if d.md.muxCfg.Version == 0 {
 		d.md.muxCfg.Version = 2
 	}
@@ -3,9 +3,7 @@ package mtls
 import (
 	"context"
 	"crypto/tls"
-	"errors"
 	"net"
-	"sync"
 	"time"
 
 	"github.com/go-gost/core/dialer"
@@ -20,11 +18,10 @@ func init() {
 }
 
 type mtlsDialer struct {
-	sessions     map[string]*muxSession
-	sessionMutex sync.Mutex
-	logger       logger.Logger
-	md           metadata
-	options      dialer.Options
+	pool    *mux.SessionPool
+	logger  logger.Logger
+	md      metadata
+	options dialer.Options
 }
 
 func NewDialer(opts ...dialer.Option) dialer.Dialer {
@@ -34,9 +31,8 @@ func NewDialer(opts ...dialer.Option) dialer.Dialer {
 	}
 
 	return &mtlsDialer{
-		sessions: make(map[string]*muxSession),
-		logger:   options.Logger,
-		options:  options,
+		logger:  options.Logger,
+		options: options,
 	}
 }
 
@@ -45,6 +41,7 @@ func (d *mtlsDialer) Init(md md.Metadata) (err error) {
 		return
 	}
 
+	d.pool = mux.NewSessionPool(d.md.poolCfg, d.logger)
 	return nil
 }
 
@@ -53,31 +50,49 @@ func (d *mtlsDialer) Multiplex() bool {
 	return true
 }
 
-func (d *mtlsDialer) Dial(ctx context.Context, addr string, opts ...dialer.DialOption) (conn net.Conn, err error) {
-	d.sessionMutex.Lock()
-	defer d.sessionMutex.Unlock()
+// Close implements io.Closer interface.
+func (d *mtlsDialer) Close() error {
+	if d.pool != nil {
+		return d.pool.Close()
+	}
+	return nil
+}
 
-	session, ok := d.sessions[addr]
-	if session != nil && session.IsClosed() {
-		delete(d.sessions, addr) // session is dead
-		ok = false
+func (d *mtlsDialer) Dial(ctx context.Context, addr string, opts ...dialer.DialOption) (net.Conn, error) {
+	conn, err := d.pool.Get(ctx, addr)
+	if err != nil {
+		return nil, err
+	}
+	if conn != nil {
+		return conn, nil
 	}
-	if !ok {
-		var options dialer.DialOptions
-		for _, opt := range opts {
-			opt(&options)
-		}
 
-		conn, err = options.NetDialer.Dial(ctx, "tcp", addr)
-		if err != nil {
-			return
-		}
+	var options dialer.DialOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
 
-		session = &muxSession{conn: conn}
-		d.sessions[addr] = session
+	if netd := mux.WarmDialer(options.NetDialer); netd != nil {
+		d.pool.Warm(addr, func(ctx context.Context) (*mux.Session, error) {
+			conn, err := netd.Dial(ctx, "tcp", addr)
+			if err != nil {
+				return nil, err
+			}
+			session, err := d.initSession(ctx, conn)
+			if err != nil {
+				conn.Close()
+				return nil, err
+			}
+			return session, nil
+		})
 	}
 
-	return session.conn, err
+	conn, err = options.NetDialer.Dial(ctx, "tcp", addr)
+	if err != nil {
+		d.pool.Cancel(addr)
+		return nil, err
+	}
+	return conn, nil
 }
 
 // Handshake implements dialer.Handshaker
@@ -87,42 +102,35 @@ func (d *mtlsDialer) Handshake(ctx context.Context, conn net.Conn, options ...di
 		option(opts)
 	}
 
-	d.sessionMutex.Lock()
-	defer d.sessionMutex.Unlock()
-
-	if d.md.handshakeTimeout > 0 {
-		conn.SetDeadline(time.Now().Add(d.md.handshakeTimeout))
-		defer conn.SetDeadline(time.Time{})
-	}
-
-	session, ok := d.sessions[opts.Addr]
-	if session != nil && session.conn != conn {
-		conn.Close()
-		return nil, errors.New("mtls: unrecognized connection")
-	}
-
-	if !ok || session.session == nil {
+	session := d.pool.Session(opts.Addr, conn)
+	if session == nil {
 		s, err := d.initSession(ctx, conn)
 		if err != nil {
 			d.logger.Error(err)
 			conn.Close()
-			delete(d.sessions, opts.Addr)
+			d.pool.Cancel(opts.Addr)
+			return nil, err
+		}
+		if session, err = d.pool.Add(opts.Addr, s); err != nil {
 			return nil, err
 		}
-		session = s
-		d.sessions[opts.Addr] = session
 	}
+
 	cc, err := session.GetConn()
 	if err != nil {
-		session.Close()
-		delete(d.sessions, opts.Addr)
+		d.pool.Remove(opts.Addr, session)
 		return nil, err
 	}
 
 	return cc, nil
 }
 
-func (d *mtlsDialer) initSession(ctx context.Context, conn net.Conn) (*muxSession, error) {
+func (d *mtlsDialer) initSession(ctx context.Context, conn net.Conn) (*mux.Session, error) {
+	if d.md.handshakeTimeout > 0 {
+		conn.SetDeadline(time.Now().Add(d.md.handshakeTimeout))
+		defer conn.SetDeadline(time.Time{})
+	}
+
 	tlsConn := tls.Client(conn, d.options.TLSConfig)
 	if err := tlsConn.HandshakeContext(ctx); err != nil {
 		return nil, err
@@ -130,9 +138,5 @@ func (d *mtlsDialer) initSession(ctx context.Context, conn net.Conn) (*muxSessio
 	conn = tlsConn
 
 	// stream multiplex
-	session, err := mux.ClientSession(conn, d.md.muxCfg)
-	if err != nil {
-		return nil, err
-	}
-	return &muxSession{conn: conn, session: session}, nil
+	return mux.ClientSession(conn, d.md.muxCfg)
 }
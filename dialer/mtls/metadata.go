@@ -11,6 +11,7 @@ import (
 type metadata struct {
 	handshakeTimeout time.Duration
 	muxCfg           *mux.Config
+	poolCfg          *mux.PoolConfig
 }
 
 func (d *mtlsDialer) parseMetadata(md mdata.Metadata) (err error) {
@@ -25,5 +26,12 @@ func (d *mtlsDialer) parseMetadata(md mdata.Metadata) (err error) {
 		MaxReceiveBuffer:  mdutil.GetInt(md, "mux.maxReceiveBuffer"),
 		MaxStreamBuffer:   mdutil.GetInt(md, "mux.maxStreamBuffer"),
 	}
+
+	d.md.poolCfg = &mux.PoolConfig{
+		Sessions:      mdutil.GetInt(md, "mux.sessions"),
+		Warm:          mdutil.GetBool(md, "mux.warm"),
+		CheckInterval: mdutil.GetDuration(md, "mux.checkInterval"),
+		IdleTimeout:   mdutil.GetDuration(md, "mux.idleTimeout"),
+	}
 	return
 }
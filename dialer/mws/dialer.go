@@ -2,10 +2,8 @@ package mws
 
 import (
 	"context"
-	"errors"
 	"net"
 	"net/url"
-	"sync"
 	"time"
 
 	"github.com/go-gost/core/dialer"
@@ -23,11 +21,10 @@ func init() {
 }
 
 type mwsDialer struct {
-	sessions     map[string]*muxSession
-	sessionMutex sync.Mutex
-	tlsEnabled   bool
-	md           metadata
-	options      dialer.Options
+	pool       *mux.SessionPool
+	tlsEnabled bool
+	md         metadata
+	options    dialer.Options
 }
 
 func NewDialer(opts ...dialer.Option) dialer.Dialer {
@@ -37,8 +34,7 @@ func NewDialer(opts ...dialer.Option) dialer.Dialer {
 	}
 
 	return &mwsDialer{
-		sessions: make(map[string]*muxSession),
-		options:  options,
+		options: options,
 	}
 }
 
@@ -50,15 +46,16 @@ func NewTLSDialer(opts ...dialer.Option) dialer.Dialer {
 
 	return &mwsDialer{
 		tlsEnabled: true,
-		sessions:   make(map[string]*muxSession),
 		options:    options,
 	}
 }
+
 func (d *mwsDialer) Init(md md.Metadata) (err error) {
 	if err = d.parseMetadata(md); err != nil {
 		return
 	}
 
+	d.pool = mux.NewSessionPool(d.md.poolCfg, d.options.Logger)
 	return nil
 }
 
@@ -67,31 +64,49 @@ func (d *mwsDialer) Multiplex() bool {
 	return true
 }
 
-func (d *mwsDialer) Dial(ctx context.Context, addr string, opts ...dialer.DialOption) (conn net.Conn, err error) {
-	d.sessionMutex.Lock()
-	defer d.sessionMutex.Unlock()
+// Close implements io.Closer interface.
+func (d *mwsDialer) Close() error {
+	if d.pool != nil {
+		return d.pool.Close()
+	}
+	return nil
+}
 
-	session, ok := d.sessions[addr]
-	if session != nil && session.IsClosed() {
-		delete(d.sessions, addr) // session is dead
-		ok = false
+func (d *mwsDialer) Dial(ctx context.Context, addr string, opts ...dialer.DialOption) (net.Conn, error) {
+	conn, err := d.pool.Get(ctx, addr)
+	if err != nil {
+		return nil, err
+	}
+	if conn != nil {
+		return conn, nil
 	}
-	if !ok {
-		var options dialer.DialOptions
-		for _, opt := range opts {
-			opt(&options)
-		}
 
-		conn, err = options.NetDialer.Dial(ctx, "tcp", addr)
-		if err != nil {
-			return
-		}
+	var options dialer.DialOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
 
-		session = &muxSession{conn: conn}
-		d.sessions[addr] = session
+	if netd := mux.WarmDialer(options.NetDialer); netd != nil {
+		d.pool.Warm(addr, func(ctx context.Context) (*mux.Session, error) {
+			conn, err := netd.Dial(ctx, "tcp", addr)
+			if err != nil {
+				return nil, err
+			}
+			session, err := d.initSession(ctx, d.host(addr), conn, d.options.Logger)
+			if err != nil {
+				conn.Close()
+				return nil, err
+			}
+			return session, nil
+		})
 	}
 
-	return session.conn, err
+	conn, err = options.NetDialer.Dial(ctx, "tcp", addr)
+	if err != nil {
+		d.pool.Cancel(addr)
+		return nil, err
+	}
+	return conn, nil
 }
 
 // Handshake implements dialer.Handshaker
@@ -106,44 +121,38 @@ func (d *mwsDialer) Handshake(ctx context.Context, conn net.Conn, options ...dia
 		"remote": conn.RemoteAddr().String(),
 	})
 
-	d.sessionMutex.Lock()
-	defer d.sessionMutex.Unlock()
-
-	session, ok := d.sessions[opts.Addr]
-	if session != nil && session.conn != conn {
-		err := errors.New("mws: unrecognized connection")
-		log.Error(err)
-		conn.Close()
-		return nil, err
-	}
-
-	if !ok || session.session == nil {
-		host := d.md.host
-		if host == "" {
-			host = opts.Addr
-		}
-		s, err := d.initSession(ctx, host, conn, log)
+	session := d.pool.Session(opts.Addr, conn)
+	if session == nil {
+		s, err := d.initSession(ctx, d.host(opts.Addr), conn, log)
 		if err != nil {
 			log.Error(err)
 			conn.Close()
-			delete(d.sessions, opts.Addr)
+			d.pool.Cancel(opts.Addr)
+			return nil, err
+		}
+		if session, err = d.pool.Add(opts.Addr, s); err != nil {
 			return nil, err
 		}
-		session = s
-		d.sessions[opts.Addr] = session
 	}
+
 	cc, err := session.GetConn()
 	if err != nil {
 		log.Error(err)
-		session.Close()
-		delete(d.sessions, opts.Addr)
+		d.pool.Remove(opts.Addr, session)
 		return nil, err
 	}
 
 	return cc, nil
 }
 
-func (d *mwsDialer) initSession(ctx context.Context, host string, conn net.Conn, log logger.Logger) (*muxSession, error) {
+func (d *mwsDialer) host(addr string) string {
+	if d.md.host != "" {
+		return d.md.host
+	}
+	return addr
+}
+
+func (d *mwsDialer) initSession(ctx context.Context, host string, conn net.Conn, log logger.Logger) (*mux.Session, error) {
 	dialer := websocket.Dialer{
 		HandshakeTimeout:  d.md.handshakeTimeout,
 		ReadBufferSize:    d.md.readBufferSize,
@@ -187,12 +196,7 @@ func (d *mwsDialer) initSession(ctx context.Context, host string, conn net.Conn,
 	}
 
 	// stream multiplex
-	session, err := mux.ClientSession(cc, d.md.muxCfg)
-	if err != nil {
-		log.Error(err)
-		return nil, err
-	}
-	return &muxSession{conn: cc, session: session}, nil
+	return mux.ClientSession(cc, d.md.muxCfg)
 }
 
 func (d *mwsDialer) keepAlive(conn ws_util.WebsocketConn) {
@@ -27,6 +27,7 @@ type metadata struct {
 	header            http.Header
 	keepaliveInterval time.Duration
 	muxCfg            *mux.Config
+	poolCfg           *mux.PoolConfig
 }
 
 func (d *mwsDialer) parseMetadata(md mdata.Metadata) (err error) {
@@ -46,6 +47,13 @@ func (d *mwsDialer) parseMetadata(md mdata.Metadata) (err error) {
 		MaxStreamBuffer:   mdutil.GetInt(md, "mux.maxStreamBuffer"),
 	}
 
+	d.md.poolCfg = &mux.PoolConfig{
+		Sessions:      mdutil.GetInt(md, "mux.sessions"),
+		Warm:          mdutil.GetBool(md, "mux.warm"),
+		CheckInterval: mdutil.GetDuration(md, "mux.checkInterval"),
+		IdleTimeout:   mdutil.GetDuration(md, "mux.idleTimeout"),
+	}
+
 	d.md.handshakeTimeout = mdutil.GetDuration(md, "ws.handshakeTimeout", "handshakeTimeout")
 	d.md.readHeaderTimeout = mdutil.GetDuration(md, "ws.readHeaderTimeout", "readHeaderTimeout")
 	d.md.readBufferSize = mdutil.GetInt(md, "ws.readBufferSize", "readBufferSize")
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"net"
 	"strings"
 	"time"
@@ -118,6 +119,14 @@ func (h *dnsHandler) Forward(hop hop.Hop) {
 	h.hop = hop
 }
 
+// Close implements io.Closer interface.
+func (h *dnsHandler) Close() error {
+	if closer, ok := h.hop.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
 func (h *dnsHandler) Handle(ctx context.Context, conn net.Conn, opts ...handler.HandleOption) error {
 	defer conn.Close()
 
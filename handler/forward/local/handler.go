@@ -12,6 +12,7 @@ import (
 	"net/http/httputil"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-gost/core/bypass"
@@ -27,6 +28,7 @@ import (
 	"github.com/go-gost/x/internal/util/forward"
 	tls_util "github.com/go-gost/x/internal/util/tls"
 	"github.com/go-gost/x/registry"
+	xselector "github.com/go-gost/x/selector"
 )
 
 func init() {
@@ -71,6 +73,14 @@ func (h *forwardHandler) Forward(hop hop.Hop) {
 	h.hop = hop
 }
 
+// Close implements io.Closer interface.
+func (h *forwardHandler) Close() error {
+	if closer, ok := h.hop.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
 func (h *forwardHandler) Handle(ctx context.Context, conn net.Conn, opts ...handler.HandleOption) error {
 	defer conn.Close()
 
@@ -97,14 +107,18 @@ func (h *forwardHandler) Handle(ctx context.Context, conn net.Conn, opts ...hand
 	}
 
 	var rw io.ReadWriter = conn
-	var host string
+	var host, sniffedHost string
 	var protocol string
 	if network == "tcp" && h.md.sniffing {
 		if h.md.sniffingTimeout > 0 {
 			conn.SetReadDeadline(time.Now().Add(h.md.sniffingTimeout))
 		}
 		rw, host, protocol, _ = forward.Sniffing(ctx, conn)
+		sniffedHost = host
 		log.Debugf("sniffing: host=%s, protocol=%s", host, protocol)
+		if protocol != "" {
+			ctx = ctxvalue.ContextWithProtocol(ctx, ctxvalue.Protocol(protocol))
+		}
 		if h.md.sniffingTimeout > 0 {
 			conn.SetReadDeadline(time.Time{})
 		}
@@ -126,6 +140,13 @@ func (h *forwardHandler) Handle(ctx context.Context, conn net.Conn, opts ...hand
 		}
 	}
 	if h.hop != nil {
+		if host != "" {
+			hash := ctxvalue.HashFromContext(ctx).WithHost(host)
+			if protocol == forward.ProtoTLS {
+				hash = hash.WithSNI(sniffedHost)
+			}
+			ctx = ctxvalue.ContextWithHash(ctx, hash)
+		}
 		target = h.hop.Select(ctx,
 			hop.HostSelectOption(host),
 			hop.ProtocolSelectOption(protocol),
@@ -157,6 +178,8 @@ func (h *forwardHandler) Handle(ctx context.Context, conn net.Conn, opts ...hand
 
 	log.Debugf("%s >> %s", conn.RemoteAddr(), addr)
 
+	xselector.StateOf(target).Trial()
+	dialStart := time.Now()
 	cc, err := h.router.Dial(ctx, network, addr)
 	if err != nil {
 		log.Error(err)
@@ -165,12 +188,15 @@ func (h *forwardHandler) Handle(ctx context.Context, conn net.Conn, opts ...hand
 		if marker := target.Marker(); marker != nil {
 			marker.Mark()
 		}
+		xselector.StateOf(target).ObserveError()
 		return err
 	}
 	defer cc.Close()
 	if marker := target.Marker(); marker != nil {
 		marker.Reset()
 	}
+	xselector.StateOf(target).ObserveLatency(time.Since(dialStart))
+	defer acquire(target)()
 
 	t := time.Now()
 	log.Infof("%s <-> %s", conn.RemoteAddr(), target.Addr)
@@ -228,6 +254,8 @@ func (h *forwardHandler) handleHTTP(ctx context.Context, rw io.ReadWriter, remot
 				Addr: req.Host,
 			}
 			if h.hop != nil {
+				ctx := ctxvalue.ContextWithHash(ctx,
+					ctxvalue.HashFromContext(ctx).WithHost(host).WithHeader(req.Header))
 				target = h.hop.Select(ctx,
 					hop.HostSelectOption(req.Host),
 					hop.ProtocolSelectOption(forward.ProtoHTTP),
@@ -267,6 +295,8 @@ func (h *forwardHandler) handleHTTP(ctx context.Context, rw io.ReadWriter, remot
 				}
 			}
 
+			xselector.StateOf(target).Trial()
+			start := time.Now()
 			cc, err = h.router.Dial(ctx, "tcp", target.Addr)
 			if err != nil {
 				// TODO: the router itself may be failed due to the failed node in the router,
@@ -274,12 +304,15 @@ func (h *forwardHandler) handleHTTP(ctx context.Context, rw io.ReadWriter, remot
 				if marker := target.Marker(); marker != nil {
 					marker.Mark()
 				}
+				xselector.StateOf(target).ObserveError()
 				log.Warnf("connect to node %s(%s) failed: %v", target.Name, target.Addr, err)
 				return resp.Write(rw)
 			}
 			if marker := target.Marker(); marker != nil {
 				marker.Reset()
 			}
+			xselector.StateOf(target).ObserveLatency(time.Since(start))
+			cc = &nodeConn{Conn: cc, release: acquire(target)}
 
 			log.Debugf("connection to node %s(%s)", target.Name, target.Addr)
 
@@ -386,3 +419,23 @@ func getRealClientAddr(req *http.Request, raddr net.Addr) net.Addr {
 		Port: port,
 	}
 }
+
+// acquire counts a new active connection to the target node,
+// it returns the function to release the connection.
+func acquire(node *chain.Node) func() {
+	st := xselector.StateOf(node)
+	st.IncConns()
+	return st.DecConns
+}
+
+// nodeConn releases the connection of the target node when it is closed.
+type nodeConn struct {
+	net.Conn
+	release func()
+	once    sync.Once
+}
+
+func (c *nodeConn) Close() error {
+	c.once.Do(c.release)
+	return c.Conn.Close()
+}
@@ -24,6 +24,7 @@ import (
 	md "github.com/go-gost/core/metadata"
 	ctxvalue "github.com/go-gost/x/internal/ctx"
 	netpkg "github.com/go-gost/x/internal/net"
+	"github.com/go-gost/x/internal/util/forward"
 	"github.com/go-gost/x/limiter/traffic/wrapper"
 	"github.com/go-gost/x/registry"
 )
@@ -182,9 +183,15 @@ func (h *httpHandler) handleRequest(ctx context.Context, conn net.Conn, req *htt
 
 	req.Header.Del("Proxy-Authorization")
 
+	hash := ctxvalue.HashFromContext(ctx).WithHost(addr).WithHeader(req.Header)
 	switch h.md.hash {
 	case "host":
-		ctx = ctxvalue.ContextWithHash(ctx, &ctxvalue.Hash{Source: addr})
+		hash.Source = addr
+	}
+	ctx = ctxvalue.ContextWithHash(ctx, hash)
+	// the plain HTTP requests are routed as http, the protocol of the CONNECT tunnels is unknown.
+	if req.Method != http.MethodConnect {
+		ctx = ctxvalue.ContextWithProtocol(ctx, forward.ProtoHTTP)
 	}
 
 	cc, err := h.router.Dial(ctx, network, addr)
@@ -220,7 +227,7 @@ func (h *httpHandler) handleRequest(ctx context.Context, conn net.Conn, req *htt
 		}
 	}
 
-	rw := wrapper.WrapReadWriter(h.options.Limiter, conn, conn.RemoteAddr().String(),
+	rw := wrapper.WrapReadWriter(ctx, h.options.Limiter, conn, conn.RemoteAddr().String(),
 		traffic.NetworkOption(network),
 		traffic.AddrOption(addr),
 		traffic.ClientOption(clientID),
@@ -163,10 +163,12 @@ func (h *http2Handler) roundTrip(ctx context.Context, w http.ResponseWriter, req
 	req.Header.Del("Proxy-Authorization")
 	req.Header.Del("Proxy-Connection")
 
+	hash := ctxvalue.HashFromContext(ctx).WithHost(addr).WithHeader(req.Header)
 	switch h.md.hash {
 	case "host":
-		ctx = ctxvalue.ContextWithHash(ctx, &ctxvalue.Hash{Source: addr})
+		hash.Source = addr
 	}
+	ctx = ctxvalue.ContextWithHash(ctx, hash)
 
 	cc, err := h.router.Dial(ctx, "tcp", addr)
 	if err != nil {
@@ -203,7 +205,7 @@ func (h *http2Handler) roundTrip(ctx context.Context, w http.ResponseWriter, req
 			return nil
 		}
 
-		rw := wrapper.WrapReadWriter(h.options.Limiter, xio.NewReadWriter(req.Body, flushWriter{w}), req.RemoteAddr,
+		rw := wrapper.WrapReadWriter(ctx, h.options.Limiter, xio.NewReadWriter(req.Body, flushWriter{w}), req.RemoteAddr,
 			traffic.NetworkOption("tcp"),
 			traffic.AddrOption(addr),
 			traffic.ClientOption(clientID),
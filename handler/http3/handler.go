@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"net"
 	"net/http"
 	"net/http/httputil"
@@ -16,6 +17,7 @@ import (
 	md "github.com/go-gost/core/metadata"
 	ctxvalue "github.com/go-gost/x/internal/ctx"
 	"github.com/go-gost/x/registry"
+	xselector "github.com/go-gost/x/selector"
 )
 
 func init() {
@@ -58,6 +60,14 @@ func (h *http3Handler) Forward(hop hop.Hop) {
 	h.hop = hop
 }
 
+// Close implements io.Closer interface.
+func (h *http3Handler) Close() error {
+	if closer, ok := h.hop.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
 func (h *http3Handler) Handle(ctx context.Context, conn net.Conn, opts ...handler.HandleOption) error {
 	defer conn.Close()
 
@@ -112,10 +122,12 @@ func (h *http3Handler) roundTrip(ctx context.Context, w http.ResponseWriter, req
 		return nil
 	}
 
+	hash := ctxvalue.HashFromContext(ctx).WithHost(addr).WithHeader(req.Header)
 	switch h.md.hash {
 	case "host":
-		ctx = ctxvalue.ContextWithHash(ctx, &ctxvalue.Hash{Source: addr})
+		hash.Source = addr
 	}
+	ctx = ctxvalue.ContextWithHash(ctx, hash)
 
 	var target *chain.Node
 	if h.hop != nil {
@@ -147,6 +159,8 @@ func (h *http3Handler) roundTrip(ctx context.Context, w http.ResponseWriter, req
 			TLSHandshakeTimeout:   10 * time.Second,
 			ExpectContinueTimeout: 1 * time.Second,
 			DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+				xselector.StateOf(target).Trial()
+				start := time.Now()
 				conn, err := h.router.Dial(ctx, network, target.Addr)
 				if err != nil {
 					log.Error(err)
@@ -155,8 +169,11 @@ func (h *http3Handler) roundTrip(ctx context.Context, w http.ResponseWriter, req
 					if marker := target.Marker(); marker != nil {
 						marker.Mark()
 					}
+					xselector.StateOf(target).ObserveError()
+					return nil, err
 				}
-				return conn, err
+				xselector.StateOf(target).ObserveLatency(time.Since(start))
+				return conn, nil
 			},
 		},
 	}
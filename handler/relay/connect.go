@@ -51,10 +51,12 @@ func (h *relayHandler) handleConnect(ctx context.Context, conn net.Conn, network
 		return
 	}
 
+	hash := ctxvalue.HashFromContext(ctx).WithHost(address)
 	switch h.md.hash {
 	case "host":
-		ctx = ctxvalue.ContextWithHash(ctx, &ctxvalue.Hash{Source: address})
+		hash.Source = address
 	}
+	ctx = ctxvalue.ContextWithHash(ctx, hash)
 
 	var cc io.ReadWriteCloser
 
@@ -105,7 +107,7 @@ func (h *relayHandler) handleConnect(ctx context.Context, conn net.Conn, network
 		}
 	}
 
-	rw := wrapper.WrapReadWriter(h.options.Limiter, conn, conn.RemoteAddr().String(),
+	rw := wrapper.WrapReadWriter(ctx, h.options.Limiter, conn, conn.RemoteAddr().String(),
 		traffic.NetworkOption(network),
 		traffic.AddrOption(address),
 		traffic.ClientOption(string(ctxvalue.ClientIDFromContext(ctx))),
@@ -13,6 +13,7 @@ import (
 	ctxvalue "github.com/go-gost/x/internal/ctx"
 	netpkg "github.com/go-gost/x/internal/net"
 	"github.com/go-gost/x/limiter/traffic/wrapper"
+	xselector "github.com/go-gost/x/selector"
 )
 
 func (h *relayHandler) handleForward(ctx context.Context, conn net.Conn, network string, log logger.Logger) error {
@@ -36,6 +37,8 @@ func (h *relayHandler) handleForward(ctx context.Context, conn net.Conn, network
 
 	log.Debugf("%s >> %s", conn.RemoteAddr(), target.Addr)
 
+	xselector.StateOf(target).Trial()
+	start := time.Now()
 	cc, err := h.router.Dial(ctx, network, target.Addr)
 	if err != nil {
 		// TODO: the router itself may be failed due to the failed node in the router,
@@ -43,6 +46,7 @@ func (h *relayHandler) handleForward(ctx context.Context, conn net.Conn, network
 		if marker := target.Marker(); marker != nil {
 			marker.Mark()
 		}
+		xselector.StateOf(target).ObserveError()
 
 		resp.Status = relay.StatusHostUnreachable
 		resp.WriteTo(conn)
@@ -54,6 +58,7 @@ func (h *relayHandler) handleForward(ctx context.Context, conn net.Conn, network
 	if marker := target.Marker(); marker != nil {
 		marker.Reset()
 	}
+	xselector.StateOf(target).ObserveLatency(time.Since(start))
 
 	if h.md.noDelay {
 		if _, err := resp.WriteTo(conn); err != nil {
@@ -87,7 +92,7 @@ func (h *relayHandler) handleForward(ctx context.Context, conn net.Conn, network
 		conn = rc
 	}
 
-	rw := wrapper.WrapReadWriter(h.options.Limiter, conn, conn.RemoteAddr().String(),
+	rw := wrapper.WrapReadWriter(ctx, h.options.Limiter, conn, conn.RemoteAddr().String(),
 		traffic.NetworkOption(network),
 		traffic.AddrOption(target.Addr),
 		traffic.ClientOption(string(ctxvalue.ClientIDFromContext(ctx))),
@@ -3,6 +3,7 @@ package relay
 import (
 	"context"
 	"errors"
+	"io"
 	"net"
 	"strconv"
 	"time"
@@ -172,6 +173,9 @@ func (h *relayHandler) Handle(ctx context.Context, conn net.Conn, opts ...handle
 
 // Close implements io.Closer interface.
 func (h *relayHandler) Close() error {
+	if closer, ok := h.hop.(io.Closer); ok {
+		closer.Close()
+	}
 	if h.ep != nil {
 		return h.ep.Close()
 	}
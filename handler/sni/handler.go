@@ -24,6 +24,7 @@ import (
 	ctxvalue "github.com/go-gost/x/internal/ctx"
 	xio "github.com/go-gost/x/internal/io"
 	netpkg "github.com/go-gost/x/internal/net"
+	"github.com/go-gost/x/internal/util/forward"
 	"github.com/go-gost/x/registry"
 )
 
@@ -121,10 +122,13 @@ func (h *sniHandler) handleHTTP(ctx context.Context, rw io.ReadWriter, raddr net
 		return nil
 	}
 
+	hash := ctxvalue.HashFromContext(ctx).WithHost(host).WithHeader(req.Header)
 	switch h.md.hash {
 	case "host":
-		ctx = ctxvalue.ContextWithHash(ctx, &ctxvalue.Hash{Source: host})
+		hash.Source = host
 	}
+	ctx = ctxvalue.ContextWithHash(ctx, hash)
+	ctx = ctxvalue.ContextWithProtocol(ctx, forward.ProtoHTTP)
 
 	cc, err := h.router.Dial(ctx, "tcp", host)
 	if err != nil {
@@ -174,6 +178,7 @@ func (h *sniHandler) handleHTTPS(ctx context.Context, rw io.ReadWriter, raddr ne
 		log.Error(err)
 		return err
 	}
+	sni := host
 
 	if _, _, err := net.SplitHostPort(host); err != nil {
 		host = net.JoinHostPort(host, "443")
@@ -189,10 +194,13 @@ func (h *sniHandler) handleHTTPS(ctx context.Context, rw io.ReadWriter, raddr ne
 		return nil
 	}
 
+	hash := ctxvalue.HashFromContext(ctx).WithHost(host).WithSNI(sni)
 	switch h.md.hash {
 	case "host":
-		ctx = ctxvalue.ContextWithHash(ctx, &ctxvalue.Hash{Source: host})
+		hash.Source = host
 	}
+	ctx = ctxvalue.ContextWithHash(ctx, hash)
+	ctx = ctxvalue.ContextWithProtocol(ctx, forward.ProtoTLS)
 
 	cc, err := h.router.Dial(ctx, "tcp", host)
 	if err != nil {
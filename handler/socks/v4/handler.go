@@ -129,10 +129,12 @@ func (h *socks4Handler) handleConnect(ctx context.Context, conn net.Conn, req *g
 		return resp.Write(conn)
 	}
 
+	hash := ctxvalue.HashFromContext(ctx).WithHost(addr)
 	switch h.md.hash {
 	case "host":
-		ctx = ctxvalue.ContextWithHash(ctx, &ctxvalue.Hash{Source: addr})
+		hash.Source = addr
 	}
+	ctx = ctxvalue.ContextWithHash(ctx, hash)
 
 	cc, err := h.router.Dial(ctx, "tcp", addr)
 	if err != nil {
@@ -151,7 +153,7 @@ func (h *socks4Handler) handleConnect(ctx context.Context, conn net.Conn, req *g
 		return err
 	}
 
-	rw := wrapper.WrapReadWriter(h.options.Limiter, conn, conn.RemoteAddr().String(),
+	rw := wrapper.WrapReadWriter(ctx, h.options.Limiter, conn, conn.RemoteAddr().String(),
 		traffic.NetworkOption("tcp"),
 		traffic.AddrOption(addr),
 		traffic.ClientOption(string(ctxvalue.ClientIDFromContext(ctx))),
@@ -28,10 +28,12 @@ func (h *socks5Handler) handleConnect(ctx context.Context, conn net.Conn, networ
 		return resp.Write(conn)
 	}
 
+	hash := ctxvalue.HashFromContext(ctx).WithHost(address)
 	switch h.md.hash {
 	case "host":
-		ctx = ctxvalue.ContextWithHash(ctx, &ctxvalue.Hash{Source: address})
+		hash.Source = address
 	}
+	ctx = ctxvalue.ContextWithHash(ctx, hash)
 
 	cc, err := h.router.Dial(ctx, network, address)
 	if err != nil {
@@ -50,7 +52,7 @@ func (h *socks5Handler) handleConnect(ctx context.Context, conn net.Conn, networ
 		return err
 	}
 
-	rw := wrapper.WrapReadWriter(h.options.Limiter, conn, conn.RemoteAddr().String(),
+	rw := wrapper.WrapReadWriter(ctx, h.options.Limiter, conn, conn.RemoteAddr().String(),
 		traffic.NetworkOption(network),
 		traffic.AddrOption(address),
 		traffic.ClientOption(string(ctxvalue.ClientIDFromContext(ctx))),
@@ -106,10 +106,12 @@ func (h *ssHandler) Handle(ctx context.Context, conn net.Conn, opts ...handler.H
 		return nil
 	}
 
+	hash := ctxvalue.HashFromContext(ctx).WithHost(addr.String())
 	switch h.md.hash {
 	case "host":
-		ctx = ctxvalue.ContextWithHash(ctx, &ctxvalue.Hash{Source: addr.String()})
+		hash.Source = addr.String()
 	}
+	ctx = ctxvalue.ContextWithHash(ctx, hash)
 
 	cc, err := h.router.Dial(ctx, "tcp", addr.String())
 	if err != nil {
@@ -13,6 +13,7 @@ import (
 	"github.com/go-gost/core/handler"
 	"github.com/go-gost/core/logger"
 	md "github.com/go-gost/core/metadata"
+	"github.com/go-gost/x/internal/conntrack"
 	netpkg "github.com/go-gost/x/internal/net"
 	sshd_util "github.com/go-gost/x/internal/util/sshd"
 	"github.com/go-gost/x/registry"
@@ -70,7 +71,7 @@ func (h *forwardHandler) Handle(ctx context.Context, conn net.Conn, opts ...hand
 		return nil
 	}
 
-	switch cc := conn.(type) {
+	switch cc := conntrack.Unwrap(conn).(type) {
 	case *sshd_util.DirectForwardConn:
 		return h.handleDirectForward(ctx, cc, log)
 	case *sshd_util.RemoteForwardConn:
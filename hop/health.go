@@ -0,0 +1,274 @@
+package hop
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-gost/core/chain"
+	"github.com/go-gost/core/logger"
+	xselector "github.com/go-gost/x/selector"
+)
+
+const (
+	defaultHealthCheckInterval = 10 * time.Second
+	defaultHealthCheckTimeout  = 5 * time.Second
+)
+
+// HealthCheckOptions is the active health checking of the nodes in a hop.
+type HealthCheckOptions struct {
+	// probe type: tcp, tls, http or dial.
+	Type     string
+	Interval time.Duration
+	Timeout  time.Duration
+	// the number of consecutive successful probes required to re-admit a failed node.
+	Rise int
+	// URL path of the http probe.
+	Path string
+	// expected response status code of the http probe, 0 means any status below 400.
+	Status int
+	// target address connected through the node by the dial probe.
+	Target string
+}
+
+func HealthCheckOption(hc *HealthCheckOptions) Option {
+	return func(opts *options) {
+		opts.healthCheck = hc
+	}
+}
+
+// healthCheck probes the nodes periodically until ctx is done.
+// A failed probe marks the node as unhealthy, so it is skipped by the selector's fail filter
+// at once, the node is re-admitted after Rise consecutive successful probes.
+func (p *chainHop) healthCheck(ctx context.Context) {
+	hc := p.options.healthCheck
+
+	interval := hc.Interval
+	if interval <= 0 {
+		interval = defaultHealthCheckInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		p.probeNodes(ctx)
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (p *chainHop) probeNodes(ctx context.Context) {
+	hc := p.options.healthCheck
+	log := p.options.logger
+
+	timeout := hc.Timeout
+	if timeout <= 0 {
+		timeout = defaultHealthCheckTimeout
+	}
+	rise := hc.Rise
+	if rise <= 0 {
+		rise = 1
+	}
+
+	var wg sync.WaitGroup
+	for _, node := range p.Nodes() {
+		if node == nil {
+			continue
+		}
+
+		wg.Add(1)
+		go func(node *chain.Node) {
+			defer wg.Done()
+
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			err := probe(ctx, hc, node, log)
+			if ctx.Err() == context.Canceled {
+				// the hop is closed.
+				return
+			}
+
+			state := xselector.StateOf(node)
+			if err != nil {
+				if !state.Unhealthy() {
+					log.Warnf("health check: node %s(%s): %v", node.Name, node.Addr, err)
+				}
+				if marker := node.Marker(); marker != nil {
+					marker.Mark()
+				}
+				state.CheckFailed()
+				return
+			}
+
+			if state.CheckSucceeded(rise) {
+				log.Infof("health check: node %s(%s) is recovered", node.Name, node.Addr)
+				if marker := node.Marker(); marker != nil {
+					marker.Reset()
+				}
+			}
+		}(node)
+	}
+	wg.Wait()
+}
+
+func probe(ctx context.Context, hc *HealthCheckOptions, node *chain.Node, log logger.Logger) error {
+	switch hc.Type {
+	case "tls":
+		return probeTLS(ctx, node)
+	case "http":
+		return probeHTTP(ctx, hc, node)
+	case "dial":
+		return probeDial(ctx, hc, node, log)
+	default:
+		return probeTCP(ctx, node)
+	}
+}
+
+func probeTCP(ctx context.Context, node *chain.Node) error {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", node.Addr)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+func probeTLS(ctx context.Context, node *chain.Node) error {
+	d := tls.Dialer{
+		Config: nodeTLSConfig(node),
+	}
+	conn, err := d.DialContext(ctx, "tcp", node.Addr)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+func probeHTTP(ctx context.Context, hc *HealthCheckOptions, node *chain.Node) error {
+	scheme := "http"
+	if settings := node.Options().TLS; settings != nil {
+		scheme = "https"
+	}
+	path := hc.Path
+	if path == "" {
+		path = "/"
+	}
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s://%s%s", scheme, node.Addr, path), nil)
+	if err != nil {
+		return err
+	}
+	if settings := node.Options().HTTP; settings != nil {
+		if settings.Host != "" {
+			req.Host = settings.Host
+		}
+		for k, v := range settings.Header {
+			req.Header.Set(k, v)
+		}
+	} else if host := node.Options().Host; host != "" && !strings.HasPrefix(host, ".") {
+		req.Host = host
+	}
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig:   nodeTLSConfig(node),
+			DisableKeepAlives: true,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if hc.Status > 0 && resp.StatusCode != hc.Status ||
+		hc.Status <= 0 && resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+	return nil
+}
+
+// probeDial connects to the target through the node's dialer and connector.
+func probeDial(ctx context.Context, hc *HealthCheckOptions, node *chain.Node, log logger.Logger) error {
+	tr := node.Options().Transport
+	if tr == nil {
+		return probeTCP(ctx, node)
+	}
+
+	addr, err := chain.Resolve(ctx, "ip", node.Addr, node.Options().Resolver, node.Options().HostMapper, log)
+	if err != nil {
+		return err
+	}
+
+	cc, err := tr.Dial(ctx, addr)
+	if err != nil {
+		return err
+	}
+
+	// the connection of a multiplexed transport is the session shared by the other streams,
+	// only the stream created by the handshake belongs to the probe.
+	mux := tr.Multiplex()
+	if !mux {
+		defer cc.Close()
+		// the deadline also covers the handshakes which may not honor the context.
+		setDeadline(ctx, cc)
+	}
+
+	cn, err := tr.Handshake(ctx, cc)
+	if err != nil {
+		return err
+	}
+	if cn != cc {
+		defer cn.Close()
+		if mux {
+			setDeadline(ctx, cn)
+		}
+	}
+
+	conn, err := tr.Connect(ctx, cn, "tcp", hc.Target)
+	if err != nil {
+		return err
+	}
+	if conn != cn {
+		conn.Close()
+	}
+	return nil
+}
+
+func setDeadline(ctx context.Context, conn net.Conn) {
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+}
+
+func nodeTLSConfig(node *chain.Node) *tls.Config {
+	cfg := &tls.Config{
+		InsecureSkipVerify: true,
+	}
+	if settings := node.Options().TLS; settings != nil {
+		cfg.ServerName = settings.ServerName
+		cfg.InsecureSkipVerify = !settings.Secure
+	}
+	if cfg.ServerName == "" {
+		if host, _, _ := net.SplitHostPort(node.Addr); host != "" {
+			cfg.ServerName = host
+		}
+	}
+	return cfg
+}
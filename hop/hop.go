@@ -3,7 +3,9 @@ package hop
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
 	"encoding/json"
+	"fmt"
 	"io"
 	"net"
 	"sort"
@@ -30,6 +32,9 @@ type options struct {
 	redisLoader loader.Loader
 	httpLoader  loader.Loader
 	period      time.Duration
+	healthCheck *HealthCheckOptions
+	raceCount   int
+	raceDelay   time.Duration
 	logger      logger.Logger
 }
 
@@ -81,6 +86,15 @@ func HTTPLoaderOption(httpLoader loader.Loader) Option {
 		opts.httpLoader = httpLoader
 	}
 }
+
+// RaceOption sets the number of the candidate nodes dialed concurrently and the delay between the attempts.
+func RaceOption(count int, delay time.Duration) Option {
+	return func(opts *options) {
+		opts.raceCount = count
+		opts.raceDelay = delay
+	}
+}
+
 func LoggerOption(logger logger.Logger) Option {
 	return func(opts *options) {
 		opts.logger = logger
@@ -88,7 +102,11 @@ func LoggerOption(logger logger.Logger) Option {
 }
 
 type chainHop struct {
-	nodes      []*chain.Node
+	nodes []*chain.Node
+	// the nodes loaded by the loaders keyed by their names and configs,
+	// they are reused by the next reload if their configs are not changed,
+	// so the runtime state of the nodes is kept.
+	loaded     map[string]*chain.Node
 	mu         sync.RWMutex
 	cancelFunc context.CancelFunc
 	options    options
@@ -114,10 +132,27 @@ func NewHop(opts ...Option) hop.Hop {
 	if p.options.period > 0 {
 		go p.periodReload(ctx)
 	}
+	if p.options.healthCheck != nil {
+		go p.healthCheck(ctx)
+	}
 
 	return p
 }
 
+func (p *chainHop) Name() string {
+	return p.options.name
+}
+
+// Race returns the number of the candidate nodes dialed concurrently and the delay between the attempts.
+func (p *chainHop) Race() (int, time.Duration) {
+	return p.options.raceCount, p.options.raceDelay
+}
+
+// Selector returns the selector of the nodes.
+func (p *chainHop) Selector() selector.Selector[*chain.Node] {
+	return p.options.selector
+}
+
 func (p *chainHop) Nodes() []*chain.Node {
 	if p == nil {
 		return nil
@@ -248,27 +283,53 @@ func (p *chainHop) periodReload(ctx context.Context) error {
 func (p *chainHop) reload(ctx context.Context) (err error) {
 	nodes := p.options.nodes
 
-	nl, err := p.load(ctx)
+	loaded := make(map[string]*chain.Node)
+	nl, err := p.load(ctx, loaded)
 
 	nodes = append(nodes, nl...)
 
-	p.options.logger.Debugf("load items %d", len(nodes))
-
 	p.mu.Lock()
-	defer p.mu.Unlock()
-
+	if ctx.Err() != nil {
+		// the hop is closed while loading.
+		p.mu.Unlock()
+		closeNodes(loaded, p.loaded)
+		return ctx.Err()
+	}
+	prev := p.loaded
+	p.loaded = loaded
 	p.nodes = nodes
+	p.mu.Unlock()
+
+	// the nodes removed or changed are released.
+	closeNodes(prev, loaded)
+
+	var reused int
+	for k, node := range loaded {
+		if prev[k] == node {
+			reused++
+		}
+	}
+	p.options.logger.Debugf("load items %d, %d unchanged", len(nodes), reused)
 
 	return
 }
 
-func (p *chainHop) load(ctx context.Context) (nodes []*chain.Node, err error) {
+// closeNodes closes the nodes in m which are not in the keep.
+func closeNodes(m, keep map[string]*chain.Node) {
+	for k, node := range m {
+		if keep[k] != node {
+			node_parser.Close(node)
+		}
+	}
+}
+
+func (p *chainHop) load(ctx context.Context, loaded map[string]*chain.Node) (nodes []*chain.Node, err error) {
 	if p.options.fileLoader != nil {
 		r, er := p.options.fileLoader.Load(ctx)
 		if er != nil {
 			p.options.logger.Warnf("file loader: %v", er)
 		}
-		nodes, _ = p.parseNode(r)
+		nodes, _ = p.parseNode(r, loaded)
 	}
 
 	if p.options.redisLoader != nil {
@@ -278,7 +339,7 @@ func (p *chainHop) load(ctx context.Context) (nodes []*chain.Node, err error) {
 				p.options.logger.Warnf("redis loader: %v", er)
 			}
 			for _, s := range list {
-				nl, _ := p.parseNode(bytes.NewReader([]byte(s)))
+				nl, _ := p.parseNode(bytes.NewReader([]byte(s)), loaded)
 				nodes = append(nodes, nl...)
 			}
 		}
@@ -288,7 +349,7 @@ func (p *chainHop) load(ctx context.Context) (nodes []*chain.Node, err error) {
 		if er != nil {
 			p.options.logger.Warnf("http loader: %v", er)
 		}
-		if node, _ := p.parseNode(r); node != nil {
+		if node, _ := p.parseNode(r, loaded); node != nil {
 			nodes = append(nodes, node...)
 		}
 	}
@@ -296,7 +357,9 @@ func (p *chainHop) load(ctx context.Context) (nodes []*chain.Node, err error) {
 	return
 }
 
-func (p *chainHop) parseNode(r io.Reader) ([]*chain.Node, error) {
+// parseNode parses the node configs read from r, the nodes are added to loaded keyed by their names and configs.
+// The node of the last reload is reused if its config is not changed.
+func (p *chainHop) parseNode(r io.Reader, loaded map[string]*chain.Node) ([]*chain.Node, error) {
 	var ncs []*config.NodeConfig
 	if err := json.NewDecoder(r).Decode(&ncs); err != nil {
 		return nil, err
@@ -308,10 +371,24 @@ func (p *chainHop) parseNode(r io.Reader) ([]*chain.Node, error) {
 			continue
 		}
 
+		// the config is encoded before parsing, as the parser fills in the default values.
+		b, _ := json.Marshal(nc)
+		key := fmt.Sprintf("%s@%x", nc.Name, sha256.Sum256(b))
+		node := loaded[key]
+		if node == nil {
+			node = p.loaded[key]
+		}
+		if node != nil {
+			loaded[key] = node
+			nodes = append(nodes, node)
+			continue
+		}
+
 		node, err := node_parser.ParseNode(p.options.name, nc)
 		if err != nil {
 			return nodes, err
 		}
+		loaded[key] = node
 		nodes = append(nodes, node)
 	}
 	return nodes, nil
@@ -325,5 +402,13 @@ func (p *chainHop) Close() error {
 	if p.options.redisLoader != nil {
 		p.options.redisLoader.Close()
 	}
+
+	p.mu.Lock()
+	closeNodes(p.loaded, nil)
+	p.mu.Unlock()
+
+	for _, node := range p.options.nodes {
+		node_parser.Close(node)
+	}
 	return nil
 }
@@ -52,6 +52,10 @@ func NewGRPCPlugin(name string, addr string, opts ...plugin.Option) hop.Hop {
 	return p
 }
 
+func (p *grpcPlugin) Name() string {
+	return p.name
+}
+
 func (p *grpcPlugin) Select(ctx context.Context, opts ...hop.SelectOption) *chain.Node {
 	if p.client == nil {
 		return nil
@@ -141,6 +145,10 @@ func NewHTTPPlugin(name string, url string, opts ...plugin.Option) hop.Hop {
 	}
 }
 
+func (p *httpPlugin) Name() string {
+	return p.name
+}
+
 func (p *httpPlugin) Select(ctx context.Context, opts ...hop.SelectOption) *chain.Node {
 	if p.client == nil {
 		return nil
@@ -0,0 +1,96 @@
+package conntrack
+
+import (
+	"errors"
+	"net"
+	"sync/atomic"
+	"syscall"
+
+	"github.com/go-gost/core/metadata"
+)
+
+var (
+	errUnsupport = errors.New("unsupported operation")
+)
+
+// serverConn is a server side Conn which counts the bytes transferred.
+type serverConn struct {
+	net.Conn
+	in  *atomic.Uint64
+	out *atomic.Uint64
+}
+
+func wrapConn(c net.Conn, in, out *atomic.Uint64) net.Conn {
+	sc := &serverConn{
+		Conn: c,
+		in:   in,
+		out:  out,
+	}
+	if pc, ok := c.(net.PacketConn); ok {
+		return &packetConn{
+			serverConn: sc,
+			pc:         pc,
+		}
+	}
+	return sc
+}
+
+func (c *serverConn) Read(b []byte) (n int, err error) {
+	n, err = c.Conn.Read(b)
+	c.in.Add(uint64(n))
+	return
+}
+
+func (c *serverConn) Write(b []byte) (n int, err error) {
+	n, err = c.Conn.Write(b)
+	c.out.Add(uint64(n))
+	return
+}
+
+func (c *serverConn) SyscallConn() (rc syscall.RawConn, err error) {
+	if sc, ok := c.Conn.(syscall.Conn); ok {
+		rc, err = sc.SyscallConn()
+		return
+	}
+	err = errUnsupport
+	return
+}
+
+func (c *serverConn) Metadata() metadata.Metadata {
+	if md, ok := c.Conn.(metadata.Metadatable); ok {
+		return md.Metadata()
+	}
+	return nil
+}
+
+// Unwrap returns the original connection.
+func (c *serverConn) Unwrap() net.Conn {
+	return c.Conn
+}
+
+// packetConn is a serverConn which is also a PacketConn.
+type packetConn struct {
+	*serverConn
+	pc net.PacketConn
+}
+
+func (c *packetConn) ReadFrom(p []byte) (n int, addr net.Addr, err error) {
+	n, addr, err = c.pc.ReadFrom(p)
+	c.in.Add(uint64(n))
+	return
+}
+
+func (c *packetConn) WriteTo(p []byte, addr net.Addr) (n int, err error) {
+	n, err = c.pc.WriteTo(p, addr)
+	c.out.Add(uint64(n))
+	return
+}
+
+// Unwrap returns the original connection of the connection c wrapped by the tracker,
+// c is returned as is if it is not wrapped.
+func Unwrap(c net.Conn) net.Conn {
+	if v, ok := c.(interface{ Unwrap() net.Conn }); ok {
+		return v.Unwrap()
+	}
+	return c
+}
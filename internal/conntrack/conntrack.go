@@ -0,0 +1,250 @@
+// Package conntrack tracks the active client connections of the services.
+package conntrack
+
+import (
+	"context"
+	"net"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	climiter "github.com/go-gost/core/limiter/conn"
+	ctxvalue "github.com/go-gost/x/internal/ctx"
+)
+
+// Info is a snapshot of a tracked connection.
+type Info struct {
+	Sid        string    `json:"sid"`
+	Service    string    `json:"service"`
+	Network    string    `json:"network,omitempty"`
+	ClientAddr string    `json:"clientAddr"`
+	ClientID   string    `json:"clientID,omitempty"`
+	Dst        string    `json:"dst,omitempty"`
+	Chain      string    `json:"chain,omitempty"`
+	Nodes      []string  `json:"nodes,omitempty"`
+	Start      time.Time `json:"start"`
+	// bytes received from the client.
+	BytesIn uint64 `json:"bytesIn"`
+	// bytes sent to the client.
+	BytesOut uint64 `json:"bytesOut"`
+}
+
+// Conn is a tracked client connection.
+type Conn struct {
+	sid        string
+	service    string
+	clientAddr string
+	start      time.Time
+	conn       net.Conn
+	wrapped    net.Conn
+
+	bytesIn  atomic.Uint64
+	bytesOut atomic.Uint64
+
+	mu       sync.RWMutex
+	network  string
+	clientID string
+	groups   []string
+	dst      string
+	chain    string
+	nodes    []string
+	// the limiters acquired by the connection, released when it is removed.
+	limiters []climiter.Limiter
+	removed  bool
+}
+
+// NewConn creates a tracked connection for the client connection conn accepted by the service.
+func NewConn(sid string, service string, conn net.Conn) *Conn {
+	c := &Conn{
+		sid:        sid,
+		service:    service,
+		clientAddr: conn.RemoteAddr().String(),
+		start:      time.Now(),
+		conn:       conn,
+	}
+	c.wrapped = wrapConn(conn, &c.bytesIn, &c.bytesOut)
+	return c
+}
+
+// Sid returns the session ID of the connection.
+func (c *Conn) Sid() string {
+	return c.sid
+}
+
+// Conn returns the client connection which counts the bytes transferred,
+// it should be used in place of the original connection.
+func (c *Conn) Conn() net.Conn {
+	return c.wrapped
+}
+
+// Close terminates the connection.
+func (c *Conn) Close() error {
+	return c.conn.Close()
+}
+
+// Info returns a snapshot of the connection.
+func (c *Conn) Info() Info {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return Info{
+		Sid:        c.sid,
+		Service:    c.service,
+		Network:    c.network,
+		ClientAddr: c.clientAddr,
+		ClientID:   c.clientID,
+		Dst:        c.dst,
+		Chain:      c.chain,
+		Nodes:      append([]string(nil), c.nodes...),
+		Start:      c.start,
+		BytesIn:    c.bytesIn.Load(),
+		BytesOut:   c.bytesOut.Load(),
+	}
+}
+
+var (
+	conns sync.Map // sid -> *Conn
+)
+
+// Add adds the connection c to the registry.
+func Add(c *Conn) {
+	if c != nil && c.sid != "" {
+		conns.Store(c.sid, c)
+	}
+}
+
+// Remove removes the connection c from the registry,
+// and releases the limiters acquired by it.
+func Remove(c *Conn) {
+	if c == nil {
+		return
+	}
+	conns.CompareAndDelete(c.sid, c)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, lim := range c.limiters {
+		lim.Allow(-1)
+	}
+	c.limiters = nil
+	c.removed = true
+}
+
+// Get returns the connection by session ID, or nil if it is not found.
+func Get(sid string) *Conn {
+	if v, ok := conns.Load(sid); ok {
+		return v.(*Conn)
+	}
+	return nil
+}
+
+// List returns the connections matching the filter, ordered by the start time.
+// A nil filter matches all the connections.
+func List(filter func(*Info) bool) []Info {
+	var list []Info
+	conns.Range(func(_, v any) bool {
+		info := v.(*Conn).Info()
+		if filter == nil || filter(&info) {
+			list = append(list, info)
+		}
+		return true
+	})
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Start.Before(list[j].Start)
+	})
+	return list
+}
+
+// Kill terminates the connections matching the filter, and returns the number of connections terminated.
+func Kill(filter func(*Info) bool) int {
+	n := 0
+	conns.Range(func(_, v any) bool {
+		c := v.(*Conn)
+		info := c.Info()
+		if filter == nil || filter(&info) {
+			c.Close()
+			n++
+		}
+		return true
+	})
+	return n
+}
+
+// SetRoute records the destination, the chain and the nodes used by the connection in the context ctx,
+// as well as the client ID if it is authenticated.
+func SetRoute(ctx context.Context, network, dst string, chain string, nodes []string) {
+	c := Get(string(ctxvalue.SidFromContext(ctx)))
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.network = network
+	c.dst = dst
+	c.chain = chain
+	c.nodes = nodes
+	if clientID := ctxvalue.ClientIDFromContext(ctx); clientID != "" {
+		c.clientID = string(clientID)
+	}
+}
+
+// SetGroups records the groups of the client authenticated for the connection in the context ctx.
+func SetGroups(ctx context.Context, groups []string) {
+	c := Get(string(ctxvalue.SidFromContext(ctx)))
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.groups = groups
+}
+
+// Groups returns the groups of the client authenticated for the connection in the context ctx.
+func Groups(ctx context.Context) []string {
+	c := Get(string(ctxvalue.SidFromContext(ctx)))
+	if c == nil {
+		return nil
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.groups
+}
+
+// Acquire acquires a connection from the limiter lim for the connection in the context ctx,
+// which is released when the connection is removed. The limiter is acquired at most once
+// for each connection, so it can be called for every request of the connection.
+// It reports whether the connection is allowed, the untracked connections are always allowed.
+func Acquire(ctx context.Context, lim climiter.Limiter) bool {
+	if lim == nil {
+		return true
+	}
+	c := Get(string(ctxvalue.SidFromContext(ctx)))
+	if c == nil {
+		return true
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.removed {
+		return true
+	}
+	for _, v := range c.limiters {
+		if v == lim {
+			return true
+		}
+	}
+	if !lim.Allow(1) {
+		return false
+	}
+	c.limiters = append(c.limiters, lim)
+	return true
+}
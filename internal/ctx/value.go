@@ -1,6 +1,9 @@
 package ctx
 
-import "context"
+import (
+	"context"
+	"net/http"
+)
 
 // clientAddrKey saves the client address.
 type clientAddrKey struct{}
@@ -42,6 +45,41 @@ type hashKey struct{}
 
 type Hash struct {
 	Source string
+	// Host is the destination address of the request.
+	Host string
+	// SNI is the server name of the TLS client hello.
+	SNI string
+	// Header is the header of the HTTP request.
+	Header http.Header
+}
+
+// WithHost returns a copy of h with the destination address set.
+func (h *Hash) WithHost(host string) *Hash {
+	v := h.clone()
+	v.Host = host
+	return v
+}
+
+// WithSNI returns a copy of h with the server name set.
+func (h *Hash) WithSNI(sni string) *Hash {
+	v := h.clone()
+	v.SNI = sni
+	return v
+}
+
+// WithHeader returns a copy of h with the HTTP request header set.
+func (h *Hash) WithHeader(header http.Header) *Hash {
+	v := h.clone()
+	v.Header = header
+	return v
+}
+
+func (h *Hash) clone() *Hash {
+	if h == nil {
+		return &Hash{}
+	}
+	v := *h
+	return &v
 }
 
 var (
@@ -74,3 +112,20 @@ func ClientIDFromContext(ctx context.Context) ClientID {
 	v, _ := ctx.Value(keyClientID).(ClientID)
 	return v
 }
+
+// protocolKey saves the sniffed protocol of the client connection.
+type protocolKey struct{}
+type Protocol string
+
+var (
+	keyProtocol = &protocolKey{}
+)
+
+func ContextWithProtocol(ctx context.Context, protocol Protocol) context.Context {
+	return context.WithValue(ctx, keyProtocol, protocol)
+}
+
+func ProtocolFromContext(ctx context.Context) Protocol {
+	v, _ := ctx.Value(keyProtocol).(Protocol)
+	return v
+}
@@ -0,0 +1,27 @@
+package net
+
+import (
+	"context"
+	"net"
+
+	"github.com/go-gost/core/hosts"
+	"github.com/go-gost/core/resolver"
+)
+
+// LookupIP resolves the host to its IP addresses the same way as the router dialing it:
+// the host mapper takes precedence over the resolver, and the system resolver
+// is used only if the resolver is nil.
+func LookupIP(ctx context.Context, network, host string, r resolver.Resolver, hosts hosts.HostMapper) ([]net.IP, error) {
+	if ip := net.ParseIP(host); ip != nil {
+		return []net.IP{ip}, nil
+	}
+	if hosts != nil {
+		if ips, _ := hosts.Lookup(ctx, network, host); len(ips) > 0 {
+			return ips, nil
+		}
+	}
+	if r != nil {
+		return r.Resolve(ctx, network, host)
+	}
+	return net.DefaultResolver.LookupIP(ctx, network, host)
+}
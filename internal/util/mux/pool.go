@@ -0,0 +1,351 @@
+package mux
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	net_dialer "github.com/go-gost/core/common/net/dialer"
+	"github.com/go-gost/core/logger"
+)
+
+const (
+	defaultPoolCheckInterval = 30 * time.Second
+	defaultPoolIdleTimeout   = 5 * time.Minute
+)
+
+var (
+	ErrPoolClosed = errors.New("mux pool is closed")
+)
+
+type PoolConfig struct {
+	// the number of the sessions kept for each server address, default is 1.
+	Sessions int
+	// establish the sessions in advance instead of on demand,
+	// and replace the dead sessions as soon as they are found.
+	Warm bool
+	// how often to check the sessions, default is 30s.
+	CheckInterval time.Duration
+	// the sessions of the address are no longer warmed
+	// if no stream is opened for this period, default is 5m.
+	IdleTimeout time.Duration
+}
+
+// DialSessionFunc establishes a new client session.
+type DialSessionFunc func(ctx context.Context) (*Session, error)
+
+// SessionPool keeps the client sessions for each server address,
+// the streams are opened from the least loaded sessions.
+// The dead sessions are found by the keepalive of the sessions.
+type SessionPool struct {
+	cfg    PoolConfig
+	pools  map[string]*sessionList
+	mu     sync.Mutex
+	done   chan struct{}
+	closed bool
+	logger logger.Logger
+}
+
+type sessionList struct {
+	sessions []*Session
+	dial     DialSessionFunc
+	// the number of the sessions being established on demand.
+	pending int
+	// closed when a pending session is established or canceled.
+	ready chan struct{}
+	// the last time a stream is requested.
+	used time.Time
+	// the maintenance of the sessions is running.
+	running bool
+}
+
+func NewSessionPool(cfg *PoolConfig, logger logger.Logger) *SessionPool {
+	p := &SessionPool{
+		pools:  make(map[string]*sessionList),
+		done:   make(chan struct{}),
+		logger: logger,
+	}
+	if cfg != nil {
+		p.cfg = *cfg
+	}
+	if p.cfg.Sessions <= 0 {
+		p.cfg.Sessions = 1
+	}
+	if p.cfg.CheckInterval <= 0 {
+		p.cfg.CheckInterval = defaultPoolCheckInterval
+	}
+	if p.cfg.IdleTimeout <= 0 {
+		p.cfg.IdleTimeout = defaultPoolIdleTimeout
+	}
+	return p
+}
+
+// Get returns the connection of the least loaded session of the address,
+// it returns nil if a new session should be established,
+// the caller must call Add or Cancel after establishing the session.
+// If the sessions being established reach the limit, it waits for one of them.
+func (p *SessionPool) Get(ctx context.Context, addr string) (net.Conn, error) {
+	for {
+		p.mu.Lock()
+		if p.closed {
+			p.mu.Unlock()
+			return nil, ErrPoolClosed
+		}
+
+		l := p.list(addr)
+		l.used = time.Now()
+		l.purge()
+
+		// the missing sessions are established by the maintenance when it is running.
+		if len(l.sessions)+l.pending < p.cfg.Sessions &&
+			(len(l.sessions) == 0 || !l.running) {
+			l.pending++
+			p.mu.Unlock()
+			return nil, nil
+		}
+
+		if s := l.leastLoaded(); s != nil {
+			p.mu.Unlock()
+			return s.conn, nil
+		}
+
+		ready := l.readyChan()
+		p.mu.Unlock()
+
+		select {
+		case <-ready:
+		case <-p.done:
+			return nil, ErrPoolClosed
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// Session returns the session of the connection conn returned by Get,
+// it returns nil if conn is not the connection of a session.
+func (p *SessionPool) Session(addr string, conn net.Conn) *Session {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, s := range p.list(addr).sessions {
+		if s.conn == conn {
+			return s
+		}
+	}
+	return nil
+}
+
+// Add adds the session of the address established on demand,
+// it returns the session to use, the session s is closed if the sessions of the address are full.
+func (p *SessionPool) Add(addr string, s *Session) (*Session, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	l := p.list(addr)
+	l.done()
+	if p.closed {
+		s.Close()
+		return nil, ErrPoolClosed
+	}
+	if !l.add(s, p.cfg.Sessions) {
+		s.Close()
+		return l.leastLoaded(), nil
+	}
+	return s, nil
+}
+
+// Cancel cancels the session of the address being established on demand.
+func (p *SessionPool) Cancel(addr string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.list(addr).done()
+}
+
+// Remove closes and removes the session of the address.
+func (p *SessionPool) Remove(addr string, s *Session) {
+	s.Close()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	l := p.list(addr)
+	for i, v := range l.sessions {
+		if v == s {
+			l.sessions = append(l.sessions[:i], l.sessions[i+1:]...)
+			break
+		}
+	}
+}
+
+// Warm starts the maintenance of the sessions of the address if it is not running,
+// the function dial is used to establish the missing sessions when warming is enabled.
+func (p *SessionPool) Warm(addr string, dial DialSessionFunc) {
+	if !p.cfg.Warm {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return
+	}
+	l := p.list(addr)
+	l.dial = dial
+	if l.running {
+		return
+	}
+	l.running = true
+	go p.maintain(addr, l)
+}
+
+// WarmDialer returns the dialer used to warm the sessions, it is built from the node-level
+// options of the dialer netd of a request, so the warming does not depend on the request.
+// It returns nil if netd dials through the nodes before the node, as the route belongs to the request,
+// the sessions of such a node are established on demand.
+func WarmDialer(netd *net_dialer.NetDialer) *net_dialer.NetDialer {
+	if netd == nil {
+		return &net_dialer.NetDialer{}
+	}
+	if netd.DialFunc != nil {
+		return nil
+	}
+	return &net_dialer.NetDialer{
+		Interface: netd.Interface,
+		Mark:      netd.Mark,
+		Timeout:   netd.Timeout,
+		Logger:    netd.Logger,
+	}
+}
+
+func (p *SessionPool) list(addr string) *sessionList {
+	l := p.pools[addr]
+	if l == nil {
+		l = &sessionList{}
+		p.pools[addr] = l
+	}
+	return l
+}
+
+// maintain keeps the sessions of the address warm until the address is idle.
+func (p *SessionPool) maintain(addr string, l *sessionList) {
+	ticker := time.NewTicker(p.cfg.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		p.mu.Lock()
+		l.purge()
+		if time.Since(l.used) > p.cfg.IdleTimeout {
+			l.running = false
+			p.mu.Unlock()
+			p.logger.Debugf("mux pool %s: idle, stop warming", addr)
+			return
+		}
+		n := p.cfg.Sessions - len(l.sessions) - l.pending
+		dial := l.dial
+		p.mu.Unlock()
+
+		for i := 0; i < n; i++ {
+			ctx, cancel := context.WithTimeout(context.Background(), p.cfg.CheckInterval)
+			s, err := dial(ctx)
+			cancel()
+			if err != nil {
+				p.logger.Warnf("mux pool %s: %v", addr, err)
+				break
+			}
+			p.mu.Lock()
+			added := !p.closed && l.add(s, p.cfg.Sessions)
+			p.mu.Unlock()
+			if !added {
+				s.Close()
+				break
+			}
+			p.logger.Debugf("mux pool %s: new session", addr)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-p.done:
+			return
+		}
+	}
+}
+
+// Close stops the maintenance and closes all the sessions,
+// the streams opened from the sessions are also closed.
+func (p *SessionPool) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return nil
+	}
+	p.closed = true
+	close(p.done)
+
+	for _, l := range p.pools {
+		for _, s := range l.sessions {
+			s.Close()
+		}
+		l.sessions = nil
+	}
+	return nil
+}
+
+// add adds the session s if the number of the sessions is under the limit n.
+func (l *sessionList) add(s *Session, n int) bool {
+	l.purge()
+	if len(l.sessions) >= n {
+		return false
+	}
+	l.sessions = append(l.sessions, s)
+	return true
+}
+
+// leastLoaded returns the session with the fewest streams, or nil if there is no session.
+func (l *sessionList) leastLoaded() *Session {
+	var s *Session
+	for _, v := range l.sessions {
+		if s == nil || v.NumStreams() < s.NumStreams() {
+			s = v
+		}
+	}
+	return s
+}
+
+// readyChan returns the channel closed when a pending session is done.
+func (l *sessionList) readyChan() chan struct{} {
+	if l.ready == nil {
+		l.ready = make(chan struct{})
+	}
+	return l.ready
+}
+
+// done finishes a pending session and wakes up the waiters.
+func (l *sessionList) done() {
+	if l.pending > 0 {
+		l.pending--
+	}
+	if l.ready != nil {
+		close(l.ready)
+		l.ready = nil
+	}
+}
+
+// purge removes the dead sessions.
+func (l *sessionList) purge() {
+	sessions := l.sessions[:0]
+	for _, s := range l.sessions {
+		if !s.IsClosed() {
+			sessions = append(sessions, s)
+		}
+	}
+	for i := len(sessions); i < len(l.sessions); i++ {
+		l.sessions[i] = nil
+	}
+	l.sessions = sessions
+}
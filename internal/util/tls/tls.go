@@ -47,7 +47,7 @@ const (
 
 // LoadDefaultConfig loads the certificate from cert & key files and optional CA file.
 func LoadDefaultConfig(certFile, keyFile, caFile string) (*tls.Config, error) {
-	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	cert, err := loadX509KeyPair(certFile, keyFile)
 	if err != nil {
 		return nil, err
 	}
@@ -66,13 +66,31 @@ func LoadDefaultConfig(certFile, keyFile, caFile string) (*tls.Config, error) {
 	return cfg, nil
 }
 
+// loadX509KeyPair loads the certificate from cert & key files,
+// the key file can be a secret reference (env:NAME or file:PATH) to the PEM encoded key.
+func loadX509KeyPair(certFile, keyFile string) (tls.Certificate, error) {
+	if !config.IsSecretRef(keyFile) {
+		return tls.LoadX509KeyPair(certFile, keyFile)
+	}
+
+	key, err := config.ResolveSecret(keyFile)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	cert, err := os.ReadFile(certFile)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	return tls.X509KeyPair(cert, []byte(key))
+}
+
 // LoadServerConfig loads the certificate from cert & key files and client CA file.
 func LoadServerConfig(config *config.TLSConfig) (*tls.Config, error) {
 	if config.CertFile == "" && config.KeyFile == "" {
 		return nil, nil
 	}
 
-	cert, err := tls.LoadX509KeyPair(config.CertFile, config.KeyFile)
+	cert, err := loadX509KeyPair(config.CertFile, config.KeyFile)
 	if err != nil {
 		return nil, err
 	}
@@ -100,7 +118,7 @@ func LoadClientConfig(config *config.TLSConfig) (*tls.Config, error) {
 	if config.CertFile == "" && config.KeyFile == "" {
 		cfg = &tls.Config{}
 	} else {
-		cert, err := tls.LoadX509KeyPair(config.CertFile, config.KeyFile)
+		cert, err := loadX509KeyPair(config.CertFile, config.KeyFile)
 		if err != nil {
 			return nil, err
 		}
@@ -3,8 +3,10 @@ package conn
 import (
 	"bufio"
 	"context"
+	"fmt"
 	"io"
 	"net"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
@@ -21,6 +23,34 @@ const (
 	IPLimitKey     = "$$"
 )
 
+const (
+	// the key prefix of the limit of a client.
+	UserLimitPrefix = "user:"
+	// the key prefix of the limit of the clients in a group, each client in the group has its own limit.
+	GroupLimitPrefix = "group:"
+)
+
+// the separator of the client ID and its groups in the key of the client.
+const groupsSep = " groups="
+
+// ClientLimitKey returns the key of the limiter of the authenticated client in the groups.
+func ClientLimitKey(client string, groups []string) string {
+	key := UserLimitPrefix + client
+	if len(groups) > 0 {
+		key += groupsSep + strings.Join(groups, ",")
+	}
+	return key
+}
+
+// parseClientLimitKey splits the key of the client without the prefix into the client ID and its groups.
+func parseClientLimitKey(s string) (client string, groups []string) {
+	i := strings.LastIndex(s, groupsSep)
+	if i < 0 {
+		return s, nil
+	}
+	return s[:i], strings.Split(s[i+len(groupsSep):], ",")
+}
+
 type options struct {
 	limits      []string
 	fileLoader  loader.Loader
@@ -69,12 +99,14 @@ func LoggerOption(logger logger.Logger) Option {
 }
 
 type connLimiter struct {
-	ipLimits   map[string]ConnLimitGenerator
-	cidrLimits cidranger.Ranger
-	limits     map[string]limiter.Limiter
-	mu         sync.Mutex
-	cancelFunc context.CancelFunc
-	options    options
+	ipLimits    map[string]ConnLimitGenerator
+	cidrLimits  cidranger.Ranger
+	userLimits  map[string]ConnLimitGenerator
+	groupLimits map[string]int
+	limits      map[string]limiter.Limiter
+	mu          sync.Mutex
+	cancelFunc  context.CancelFunc
+	options     options
 }
 
 func NewConnLimiter(opts ...Option) limiter.ConnLimiter {
@@ -85,11 +117,13 @@ func NewConnLimiter(opts ...Option) limiter.ConnLimiter {
 
 	ctx, cancel := context.WithCancel(context.TODO())
 	lim := &connLimiter{
-		ipLimits:   make(map[string]ConnLimitGenerator),
-		cidrLimits: cidranger.NewPCTrieRanger(),
-		limits:     make(map[string]limiter.Limiter),
-		options:    options,
-		cancelFunc: cancel,
+		ipLimits:    make(map[string]ConnLimitGenerator),
+		cidrLimits:  cidranger.NewPCTrieRanger(),
+		userLimits:  make(map[string]ConnLimitGenerator),
+		groupLimits: make(map[string]int),
+		limits:      make(map[string]limiter.Limiter),
+		options:     options,
+		cancelFunc:  cancel,
 	}
 
 	if err := lim.reload(ctx); err != nil {
@@ -105,10 +139,41 @@ func (l *connLimiter) Limiter(key string) limiter.Limiter {
 	l.mu.Lock()
 	defer l.mu.Unlock()
 
+	key = l.cacheKey(key)
 	if lim, ok := l.limits[key]; ok {
 		return lim
 	}
 
+	lim := l.limiter(key)
+	l.limits[key] = lim
+
+	if lim != nil && l.options.logger != nil {
+		l.options.logger.Debugf("conn limit for %s: %d", key, lim.Limit())
+	}
+
+	return lim
+}
+
+// cacheKey returns the key of the cached limiter for the key, l.mu must be held.
+// The limiter of a client with its own limit is shared by all the connections of the client,
+// otherwise it is cached along with the groups of the client, as the groups may be changed.
+func (l *connLimiter) cacheKey(key string) string {
+	if s, ok := strings.CutPrefix(key, UserLimitPrefix); ok {
+		if client, _ := parseClientLimitKey(s); l.userLimits[client] != nil {
+			return UserLimitPrefix + client
+		}
+	}
+	return key
+}
+
+// limiter creates the limiter for the key, l.mu must be held.
+func (l *connLimiter) limiter(key string) limiter.Limiter {
+	// the key of an authenticated client is generated by ClientLimitKey,
+	// which is only limited by the limit of the client or its groups.
+	if s, ok := strings.CutPrefix(key, UserLimitPrefix); ok {
+		return l.clientLimiter(parseClientLimitKey(s))
+	}
+
 	var lims []limiter.Limiter
 
 	if ip := net.ParseIP(key); ip != nil {
@@ -144,17 +209,30 @@ func (l *connLimiter) Limiter(key string) limiter.Limiter {
 		}
 	}
 
-	var lim limiter.Limiter
-	if len(lims) > 0 {
-		lim = newLimiterGroup(lims...)
+	if len(lims) == 0 {
+		return nil
 	}
-	l.limits[key] = lim
+	return newLimiterGroup(lims...)
+}
 
-	if lim != nil && l.options.logger != nil {
-		l.options.logger.Debugf("conn limit for %s: %d", key, lim.Limit())
+// clientLimiter returns the limiter of the client, l.mu must be held.
+// The limit of the client takes precedence over the limits of its groups,
+// and the largest one is used if the client is in multiple groups with limits.
+func (l *connLimiter) clientLimiter(client string, groups []string) limiter.Limiter {
+	if p := l.userLimits[client]; p != nil {
+		return p.Limiter()
 	}
 
-	return lim
+	n := 0
+	for _, group := range groups {
+		if v := l.groupLimits[group]; v > n {
+			n = v
+		}
+	}
+	if n <= 0 {
+		return nil
+	}
+	return NewLimiter(n)
 }
 
 func (l *connLimiter) periodReload(ctx context.Context) error {
@@ -188,6 +266,8 @@ func (l *connLimiter) reload(ctx context.Context) error {
 
 	ipLimits := make(map[string]ConnLimitGenerator)
 	cidrLimits := cidranger.NewPCTrieRanger()
+	userLimits := make(map[string]ConnLimitGenerator)
+	groupLimits := make(map[string]int)
 
 	for _, s := range lines {
 		key, limit := l.parseLimit(s)
@@ -200,6 +280,14 @@ func (l *connLimiter) reload(ctx context.Context) error {
 		case IPLimitKey:
 			ipLimits[key] = NewConnLimitGenerator(limit)
 		default:
+			if client, ok := strings.CutPrefix(key, UserLimitPrefix); ok {
+				userLimits[client] = NewConnLimitGenerator(limit)
+				break
+			}
+			if group, ok := strings.CutPrefix(key, GroupLimitPrefix); ok {
+				groupLimits[group] = limit
+				break
+			}
 			if ip := net.ParseIP(key); ip != nil {
 				ipLimits[key] = NewConnLimitSingleGenerator(limit)
 				break
@@ -216,13 +304,87 @@ func (l *connLimiter) reload(ctx context.Context) error {
 	l.mu.Lock()
 	defer l.mu.Unlock()
 
+	// the limiters shared by the connections are kept, so the connections counted by them are not lost.
+	for key, p := range ipLimits {
+		if _, ok := p.(*connLimitSingleGenerator); !ok {
+			continue
+		}
+		if lim := l.single(key); lim != nil {
+			lim.Set(p.Limiter().Limit())
+			ipLimits[key] = l.ipLimits[key]
+		}
+	}
+
 	l.ipLimits = ipLimits
 	l.cidrLimits = cidrLimits
-	l.limits = make(map[string]limiter.Limiter)
+	l.userLimits = userLimits
+	l.groupLimits = groupLimits
+
+	// the cached limiters are updated to the new limits rather than discarded,
+	// as they are held by the active connections until the connections are closed.
+	for key, lim := range l.limits {
+		if k := l.cacheKey(key); k != key {
+			delete(l.limits, key)
+			continue
+		}
+		l.limits[key] = update(lim, l.limiter(key))
+	}
 
 	return nil
 }
 
+// single returns the limiter of the single generator for the key, l.mu must be held.
+func (l *connLimiter) single(key string) *llimiter {
+	if p, _ := l.ipLimits[key].(*connLimitSingleGenerator); p != nil {
+		lim, _ := p.limiter.(*llimiter)
+		return lim
+	}
+	return nil
+}
+
+// update updates the limits of the cached limiter lim to the limits of the limiter v
+// created with the reloaded limits, and returns lim if it is kept.
+// The limiter lim is replaced by v if they are not of the same kind.
+func update(lim, v limiter.Limiter) limiter.Limiter {
+	switch lim := lim.(type) {
+	case *llimiter:
+		if v, ok := v.(*llimiter); ok {
+			lim.Set(v.Limit())
+			return lim
+		}
+	case *limiterGroup:
+		v, ok := v.(*limiterGroup)
+		if !ok || len(v.limiters) != len(lim.limiters) {
+			break
+		}
+		// the shared limiters are the same, and the remaining ones of the key are paired.
+		var olds, news []*llimiter
+		for _, o := range lim.limiters {
+			if !slices.Contains(v.limiters, o) {
+				ol, _ := o.(*llimiter)
+				olds = append(olds, ol)
+			}
+		}
+		for _, n := range v.limiters {
+			if !slices.Contains(lim.limiters, n) {
+				nl, _ := n.(*llimiter)
+				news = append(news, nl)
+			}
+		}
+		if len(olds) > 1 || len(olds) != len(news) {
+			break
+		}
+		if len(olds) == 1 {
+			if olds[0] == nil || news[0] == nil {
+				break
+			}
+			olds[0].Set(news[0].Limit())
+		}
+		return lim
+	}
+	return v
+}
+
 func (l *connLimiter) load(ctx context.Context) (patterns []string, err error) {
 	if l.options.fileLoader != nil {
 		if lister, ok := l.options.fileLoader.(loader.Lister); ok {
@@ -318,6 +480,22 @@ func (l *connLimiter) parseLimit(s string) (key string, limit int) {
 	return
 }
 
+// ValidateLimit checks the limit string s,
+// which is in the form of "<key> <limit>".
+func ValidateLimit(s string) error {
+	ss := strings.Fields(s)
+	if len(ss) != 2 {
+		return fmt.Errorf("invalid limit: %q", s)
+	}
+	if ss[0] == UserLimitPrefix || ss[0] == GroupLimitPrefix {
+		return fmt.Errorf("invalid limit: %q: empty %s", s, strings.TrimSuffix(ss[0], ":"))
+	}
+	if _, err := strconv.Atoi(ss[1]); err != nil {
+		return fmt.Errorf("invalid limit: %q: %v", s, err)
+	}
+	return nil
+}
+
 func (l *connLimiter) Close() error {
 	l.cancelFunc()
 	if l.options.fileLoader != nil {
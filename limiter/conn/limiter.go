@@ -8,20 +8,27 @@ import (
 )
 
 type llimiter struct {
-	limit   int
+	limit   atomic.Int64
 	current int64
 }
 
 func NewLimiter(n int) limiter.Limiter {
-	return &llimiter{limit: n}
+	l := &llimiter{}
+	l.limit.Store(int64(n))
+	return l
 }
 
 func (l *llimiter) Limit() int {
-	return l.limit
+	return int(l.limit.Load())
+}
+
+// Set updates the limit, the connections already allowed are kept.
+func (l *llimiter) Set(n int) {
+	l.limit.Store(int64(n))
 }
 
 func (l *llimiter) Allow(n int) bool {
-	if atomic.AddInt64(&l.current, int64(n)) > int64(l.limit) {
+	if atomic.AddInt64(&l.current, int64(n)) > l.limit.Load() {
 		if n > 0 {
 			atomic.AddInt64(&l.current, -int64(n))
 		}
@@ -58,10 +65,18 @@ func (l *limiterGroup) Allow(n int) (b bool) {
 	return
 }
 
+// Limit returns the smallest limit of the limiters,
+// which may be updated by Set after the group is created.
 func (l *limiterGroup) Limit() int {
 	if len(l.limiters) == 0 {
 		return 0
 	}
 
-	return l.limiters[0].Limit()
+	n := l.limiters[0].Limit()
+	for _, lim := range l.limiters[1:] {
+		if v := lim.Limit(); v < n {
+			n = v
+		}
+	}
+	return n
 }
@@ -3,6 +3,7 @@ package rate
 import (
 	"bufio"
 	"context"
+	"fmt"
 	"io"
 	"net"
 	"strconv"
@@ -318,6 +319,19 @@ func (l *rateLimiter) parseLimit(s string) (key string, limit float64) {
 	return
 }
 
+// ValidateLimit checks the limit string s,
+// which is in the form of "<key> <limit>".
+func ValidateLimit(s string) error {
+	ss := strings.Fields(s)
+	if len(ss) != 2 {
+		return fmt.Errorf("invalid limit: %q", s)
+	}
+	if _, err := strconv.ParseFloat(ss[1], 64); err != nil {
+		return fmt.Errorf("invalid limit: %q: %v", s, err)
+	}
+	return nil
+}
+
 func (l *rateLimiter) Close() error {
 	l.cancelFunc()
 	if l.options.fileLoader != nil {
@@ -3,6 +3,7 @@ package traffic
 import (
 	"bufio"
 	"context"
+	"fmt"
 	"io"
 	"net"
 	"strings"
@@ -12,6 +13,7 @@ import (
 	"github.com/alecthomas/units"
 	limiter "github.com/go-gost/core/limiter/traffic"
 	"github.com/go-gost/core/logger"
+	xauth "github.com/go-gost/x/auth"
 	"github.com/go-gost/x/internal/loader"
 	"github.com/patrickmn/go-cache"
 	"github.com/yl2chen/cidranger"
@@ -22,6 +24,13 @@ const (
 	ConnLimitKey   = "$$"
 )
 
+const (
+	// the key prefix of the limit of a client, which is shared by all the connections of the client.
+	UserLimitPrefix = "user:"
+	// the key prefix of the limit of the clients in a group, each client in the group has its own limiter.
+	GroupLimitPrefix = "group:"
+)
+
 const (
 	defaultExpiration = 15 * time.Second
 	cleanupInterval   = 30 * time.Second
@@ -82,6 +91,7 @@ type limitValue struct {
 type trafficLimiter struct {
 	generators     sync.Map
 	cidrGenerators cidranger.Ranger
+	groupLimits    map[string]limitValue
 	// connection level in/out limits
 	connInLimits  *cache.Cache
 	connOutLimits *cache.Cache
@@ -171,6 +181,11 @@ func (l *trafficLimiter) In(ctx context.Context, key string, opts ...limiter.Opt
 		}
 	}
 
+	// client level limiter
+	if lim := l.clientLimiter(l.inLimits, clientOf(opts), xauth.GroupsFromContext(ctx), true); lim != nil {
+		lims = append(lims, lim)
+	}
+
 	var lim limiter.Limiter
 	if len(lims) > 0 {
 		lim = newLimiterGroup(lims...)
@@ -235,6 +250,11 @@ func (l *trafficLimiter) Out(ctx context.Context, key string, opts ...limiter.Op
 		}
 	}
 
+	// client level limiter
+	if lim := l.clientLimiter(l.outLimits, clientOf(opts), xauth.GroupsFromContext(ctx), false); lim != nil {
+		lims = append(lims, lim)
+	}
+
 	var lim limiter.Limiter
 	if len(lims) > 0 {
 		lim = newLimiterGroup(lims...)
@@ -247,6 +267,100 @@ func (l *trafficLimiter) Out(ctx context.Context, key string, opts ...limiter.Op
 	return lim
 }
 
+// clientLimiter returns the limiter of the authenticated client in the groups in the direction in,
+// the limit of the client takes precedence over the limits of its groups,
+// and the largest one is used if the client is in multiple groups with limits.
+func (l *trafficLimiter) clientLimiter(limits *cache.Cache, client string, groups []string, in bool) limiter.Limiter {
+	if client == "" {
+		return nil
+	}
+
+	// the limiter of the client loaded from the limits.
+	if lim, ok := limits.Get(UserLimitPrefix + client); ok {
+		if lim != nil {
+			return lim.(limiter.Limiter)
+		}
+		return nil
+	}
+
+	// the limiter generated from the limits of the groups is cached along with the groups,
+	// so a client whose groups are changed gets the limiter of its current groups.
+	key := groupsLimitKey(client, groups)
+	if lim, ok := limits.Get(key); ok {
+		if lim != nil {
+			return lim.(limiter.Limiter)
+		}
+		return nil
+	}
+
+	l.mu.RLock()
+	groupLimits := l.groupLimits
+	l.mu.RUnlock()
+
+	value := groupLimit(groupLimits, groups)
+	limit := value.out
+	if in {
+		limit = value.in
+	}
+	if limit <= 0 {
+		return nil
+	}
+
+	lim := NewLimiter(limit)
+	limits.Set(key, lim, cache.NoExpiration)
+	return lim
+}
+
+// the separator of the client ID and its groups in the key of the limiter generated from the groups.
+const groupsSep = " groups="
+
+// groupsLimitKey returns the key of the limiter of the client generated from the limits of the groups.
+func groupsLimitKey(client string, groups []string) string {
+	return UserLimitPrefix + client + groupsSep + strings.Join(groups, ",")
+}
+
+// parseGroupsLimitKey returns the client and the groups of the key generated by groupsLimitKey,
+// ok is false if the key is not the key of a limiter generated from the groups.
+func parseGroupsLimitKey(key string) (client string, groups []string, ok bool) {
+	s, ok := strings.CutPrefix(key, UserLimitPrefix)
+	if !ok {
+		return
+	}
+	i := strings.LastIndex(s, groupsSep)
+	if i < 0 {
+		return "", nil, false
+	}
+	if s[i+len(groupsSep):] != "" {
+		groups = strings.Split(s[i+len(groupsSep):], ",")
+	}
+	return s[:i], groups, true
+}
+
+// groupLimit returns the largest limits of the groups.
+func groupLimit(groupLimits map[string]limitValue, groups []string) (value limitValue) {
+	for _, group := range groups {
+		v, ok := groupLimits[group]
+		if !ok {
+			continue
+		}
+		if v.in > value.in {
+			value.in = v.in
+		}
+		if v.out > value.out {
+			value.out = v.out
+		}
+	}
+	return
+}
+
+func clientOf(opts []limiter.Option) string {
+	var options limiter.Options
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options.Client
+}
+
 func (l *trafficLimiter) periodReload(ctx context.Context) error {
 	period := l.options.period
 	if period < time.Second {
@@ -341,8 +455,17 @@ func (l *trafficLimiter) reload(ctx context.Context) error {
 		delete(values, ConnLimitKey)
 	}
 
+	// group level limits, the limiters of the clients in the groups are generated on demand.
+	groupLimits := make(map[string]limitValue)
+	for key, value := range values {
+		if group, ok := strings.CutPrefix(key, GroupLimitPrefix); ok {
+			groupLimits[group] = value
+			delete(values, key)
+		}
+	}
+
 	cidrGenerators := cidranger.NewPCTrieRanger()
-	// IP/CIDR level limiters
+	// IP/CIDR and client level limiters
 	{
 		// snapshot of the current limiters
 		inLimits := l.inLimits.Items()
@@ -389,8 +512,20 @@ func (l *trafficLimiter) reload(ctx context.Context) error {
 			}
 		}
 
-		// check the CIDR for remain limiters, clean the unmatched ones.
+		// check the CIDR or the groups for remain limiters, clean the unmatched ones.
 		for k, v := range inLimits {
+			if client, groups, ok := parseGroupsLimitKey(k); ok {
+				if _, ok := values[UserLimitPrefix+client]; ok {
+					l.inLimits.Delete(k)
+					continue
+				}
+				l.resetLimiter(l.inLimits, k, v.Object, groupLimit(groupLimits, groups).in)
+				continue
+			}
+			if strings.HasPrefix(k, UserLimitPrefix) {
+				l.inLimits.Delete(k)
+				continue
+			}
 			if p, _ := cidrGenerators.ContainingNetworks(net.ParseIP(k)); len(p) > 0 {
 				if le, _ := p[0].(*cidrLimitEntry); le != nil {
 					in := le.generator.in
@@ -408,6 +543,18 @@ func (l *trafficLimiter) reload(ctx context.Context) error {
 			}
 		}
 		for k, v := range outLimits {
+			if client, groups, ok := parseGroupsLimitKey(k); ok {
+				if _, ok := values[UserLimitPrefix+client]; ok {
+					l.outLimits.Delete(k)
+					continue
+				}
+				l.resetLimiter(l.outLimits, k, v.Object, groupLimit(groupLimits, groups).out)
+				continue
+			}
+			if strings.HasPrefix(k, UserLimitPrefix) {
+				l.outLimits.Delete(k)
+				continue
+			}
 			if p, _ := cidrGenerators.ContainingNetworks(net.ParseIP(k)); len(p) > 0 {
 				if le, _ := p[0].(*cidrLimitEntry); le != nil {
 					out := le.generator.out
@@ -431,10 +578,22 @@ func (l *trafficLimiter) reload(ctx context.Context) error {
 	defer l.mu.Unlock()
 
 	l.cidrGenerators = cidrGenerators
+	l.groupLimits = groupLimits
 
 	return nil
 }
 
+// resetLimiter updates the cached limiter v of the key to the limit, or removes it if the limit is not positive.
+func (l *trafficLimiter) resetLimiter(limits *cache.Cache, key string, v any, limit int) {
+	if limit <= 0 || v == nil {
+		limits.Delete(key)
+		return
+	}
+	if lim := v.(limiter.Limiter); lim.Limit() != limit {
+		lim.Set(limit)
+	}
+}
+
 func (l *trafficLimiter) load(ctx context.Context) (values map[string]limitValue, err error) {
 	values = make(map[string]limitValue)
 
@@ -574,6 +733,24 @@ func (l *trafficLimiter) parseLimit(s string) (key string, in, out int) {
 	return
 }
 
+// ValidateLimit checks the limit string s,
+// which is in the form of "<key> <in> [<out>]".
+func ValidateLimit(s string) error {
+	ss := strings.Fields(s)
+	if len(ss) < 2 || len(ss) > 3 {
+		return fmt.Errorf("invalid limit: %q", s)
+	}
+	if ss[0] == UserLimitPrefix || ss[0] == GroupLimitPrefix {
+		return fmt.Errorf("invalid limit: %q: empty %s", s, strings.TrimSuffix(ss[0], ":"))
+	}
+	for _, v := range ss[1:] {
+		if _, err := units.ParseBase2Bytes(v); err != nil {
+			return fmt.Errorf("invalid limit: %q: %v", s, err)
+		}
+	}
+	return nil
+}
+
 func (l *trafficLimiter) Close() error {
 	l.cancelFunc()
 	if l.options.fileLoader != nil {
@@ -20,9 +20,11 @@ type readWriter struct {
 	expOut     int64
 	opts       []limiter.Option
 	key        string
+	// the context of the connection, the limiters of the client are looked up by it.
+	ctx context.Context
 }
 
-func WrapReadWriter(limiter limiter.TrafficLimiter, rw io.ReadWriter, key string, opts ...limiter.Option) io.ReadWriter {
+func WrapReadWriter(ctx context.Context, limiter limiter.TrafficLimiter, rw io.ReadWriter, key string, opts ...limiter.Option) io.ReadWriter {
 	if limiter == nil {
 		return rw
 	}
@@ -31,6 +33,7 @@ func WrapReadWriter(limiter limiter.TrafficLimiter, rw io.ReadWriter, key string
 		ReadWriter: rw,
 		limiter:    limiter,
 		opts:       opts,
+		ctx:        ctx,
 	}
 }
 
@@ -38,7 +41,7 @@ func (p *readWriter) getInLimiter() limiter.Limiter {
 	now := time.Now().UnixNano()
 	// cache the limiter for 60s
 	if p.limiter != nil && time.Duration(now-p.expIn) > 60*time.Second {
-		if lim := p.limiter.In(context.Background(), p.key, p.opts...); lim != nil {
+		if lim := p.limiter.In(p.ctx, p.key, p.opts...); lim != nil {
 			p.limiterIn = lim
 		}
 		p.expIn = now
@@ -50,7 +53,7 @@ func (p *readWriter) getOutLimiter() limiter.Limiter {
 	now := time.Now().UnixNano()
 	// cache the limiter for 60s
 	if p.limiter != nil && time.Duration(now-p.expOut) > 60*time.Second {
-		if lim := p.limiter.Out(context.Background(), p.key, p.opts...); lim != nil {
+		if lim := p.limiter.Out(p.ctx, p.key, p.opts...); lim != nil {
 			p.limiterOut = lim
 		}
 		p.expOut = now
@@ -71,18 +71,7 @@ func NewLogger(opts ...Option) logger.Logger {
 		})
 	}
 
-	switch options.Level {
-	case logger.TraceLevel,
-		logger.DebugLevel,
-		logger.InfoLevel,
-		logger.WarnLevel,
-		logger.ErrorLevel,
-		logger.FatalLevel:
-		lvl, _ := logrus.ParseLevel(string(options.Level))
-		log.SetLevel(lvl)
-	default:
-		log.SetLevel(logrus.InfoLevel)
-	}
+	log.SetLevel(parseLevel(options.Level))
 
 	return &logrusLogger{
 		logger: logrus.NewEntry(log),
@@ -162,11 +151,32 @@ func (l *logrusLogger) GetLevel() logger.LogLevel {
 	return logger.LogLevel(l.logger.Logger.GetLevel().String())
 }
 
+// SetLevel changes the level of the logger at runtime,
+// the loggers derived from it by WithFields are changed as well.
+func (l *logrusLogger) SetLevel(level logger.LogLevel) {
+	l.logger.Logger.SetLevel(parseLevel(level))
+}
+
 func (l *logrusLogger) IsLevelEnabled(level logger.LogLevel) bool {
 	lvl, _ := logrus.ParseLevel(string(level))
 	return l.logger.Logger.IsLevelEnabled(lvl)
 }
 
+func parseLevel(level logger.LogLevel) logrus.Level {
+	switch level {
+	case logger.TraceLevel,
+		logger.DebugLevel,
+		logger.InfoLevel,
+		logger.WarnLevel,
+		logger.ErrorLevel,
+		logger.FatalLevel:
+		lvl, _ := logrus.ParseLevel(string(level))
+		return lvl
+	default:
+		return logrus.InfoLevel
+	}
+}
+
 func (l *logrusLogger) log(level logrus.Level, args ...any) {
 	lg := l.logger
 	if l.logger.Logger.IsLevelEnabled(logrus.DebugLevel) {
@@ -20,6 +20,7 @@ import (
 var (
 	cfgFile      string
 	outputFormat string
+	testConfig   bool
 	services     stringList
 	nodes        stringList
 	debug        bool
@@ -77,6 +78,7 @@ func init() {
 	flag.StringVar(&cfgFile, "C", "", "configuration file")
 	flag.BoolVar(&printVersion, "V", false, "print version")
 	flag.StringVar(&outputFormat, "O", "", "output format, one of yaml|json format")
+	flag.BoolVar(&testConfig, "t", false, "test the configuration and exit")
 	flag.BoolVar(&debug, "D", false, "debug mode")
 	flag.StringVar(&apiAddr, "api", "", "api service address")
 	flag.StringVar(&metricsAddr, "metrics", "", "metrics service address")
@@ -1,38 +1,98 @@
 package main
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"os"
+	"sync"
 
 	"github.com/go-gost/core/logger"
+	"github.com/go-gost/core/service"
 	"github.com/go-gost/x/config"
+	"github.com/go-gost/x/config/loader"
 	"github.com/go-gost/x/config/parsing"
+	"github.com/go-gost/x/config/revision"
 	logger_parser "github.com/go-gost/x/config/parsing/logger"
 	xmetrics "github.com/go-gost/x/metrics"
 	"github.com/go-gost/x/registry"
+	xservice "github.com/go-gost/x/service"
 	"github.com/judwhite/go-svc"
 )
 
 type program struct {
+	// the config files and include patterns to watch.
+	sources []string
 }
 
 func (p *program) Init(env svc.Environment) error {
+	cfg, err := p.loadConfig()
+	if testConfig {
+		if err == nil {
+			err = errors.Join(checkCmd(services, nodes), loader.Check(cfg))
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		fmt.Fprintln(os.Stdout, "configuration test is successful")
+		os.Exit(0)
+	}
+	if err != nil {
+		return err
+	}
+
+	logCfg := cfg.Log
+	if logCfg == nil {
+		logCfg = &config.LogConfig{}
+	}
+	logger.SetDefault(logger_parser.ParseLogger(&config.LoggerConfig{Log: logCfg}))
+
+	if outputFormat != "" {
+		if err := cfg.Redacted().Write(os.Stdout, outputFormat); err != nil {
+			return err
+		}
+		os.Exit(0)
+	}
+
+	parsing.BuildDefaultTLSConfig(cfg.TLS)
+
+	config.Set(cfg)
+
+	var revOpts []revision.Option
+	if cfg.API != nil && cfg.API.Revision != nil {
+		revOpts = append(revOpts,
+			revision.MaxOption(cfg.API.Revision.Max),
+			revision.DirOption(cfg.API.Revision.Dir),
+		)
+	}
+	if err := revision.Init(revOpts...); err != nil {
+		logger.Default().Warnf("revision: %v", err)
+	}
+	revision.Record(cfg, "", "init")
+
+	return nil
+}
+
+// loadConfig builds the config from the config file, command line and environment variables.
+func (p *program) loadConfig() (*config.Config, error) {
 	cfg := &config.Config{}
 	if cfgFile != "" {
 		if err := cfg.ReadFile(cfgFile); err != nil {
-			return err
+			return nil, err
 		}
+		p.sources = cfg.Sources()
 	}
 
 	cmdCfg, err := buildConfigFromCmd(services, nodes)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	cfg = p.mergeConfig(cfg, cmdCfg)
 
 	if len(cfg.Services) == 0 && apiAddr == "" {
 		if err := cfg.Load(); err != nil {
-			return err
+			return nil, err
 		}
 	}
 
@@ -74,24 +134,7 @@ func (p *program) Init(env svc.Environment) error {
 		}
 	}
 
-	logCfg := cfg.Log
-	if logCfg == nil {
-		logCfg = &config.LogConfig{}
-	}
-	logger.SetDefault(logger_parser.ParseLogger(&config.LoggerConfig{Log: logCfg}))
-
-	if outputFormat != "" {
-		if err := cfg.Write(os.Stdout, outputFormat); err != nil {
-			return err
-		}
-		os.Exit(0)
-	}
-
-	parsing.BuildDefaultTLSConfig(cfg.TLS)
-
-	config.Set(cfg)
-
-	return nil
+	return cfg, nil
 }
 
 func (p *program) Start() error {
@@ -142,14 +185,24 @@ func (p *program) Start() error {
 		}()
 	}
 
+	if cfgFile != "" {
+		go p.watch()
+	}
+
 	return nil
 }
 
 func (p *program) Stop() error {
+	var wg sync.WaitGroup
 	for name, srv := range registry.ServiceRegistry().GetAll() {
-		srv.Close()
-		logger.Default().Debugf("service %s shutdown", name)
+		wg.Add(1)
+		go func(name string, srv service.Service) {
+			defer wg.Done()
+			xservice.Shutdown(srv)
+			logger.Default().Debugf("service %s shutdown", name)
+		}(name, srv)
 	}
+	wg.Wait()
 	return nil
 }
 
@@ -178,6 +231,9 @@ func (p *program) mergeConfig(cfg1, cfg2 *config.Config) *config.Config {
 		RLimiters:  append(cfg1.RLimiters, cfg2.RLimiters...),
 		Loggers:    append(cfg1.Loggers, cfg2.Loggers...),
 		Routers:    append(cfg1.Routers, cfg2.Routers...),
+		Rules:      append(cfg1.Rules, cfg2.Rules...),
+		ACLs:       append(cfg1.ACLs, cfg2.ACLs...),
+		Quotas:     append(cfg1.Quotas, cfg2.Quotas...),
 		TLS:        cfg1.TLS,
 		Log:        cfg1.Log,
 		API:        cfg1.API,
@@ -0,0 +1,94 @@
+package quota
+
+import (
+	"context"
+
+	"github.com/go-gost/core/limiter/traffic"
+)
+
+// trafficLimiter counts the bytes of the connections for the quota,
+// and limits the rate of the connections by the traffic limiter it wraps.
+type trafficLimiter struct {
+	quota   Quota
+	service string
+	limiter traffic.TrafficLimiter
+}
+
+// NewTrafficLimiter creates a traffic.TrafficLimiter which records the bytes
+// transferred by the clients of the service to the quota q.
+// The rates are limited by the limiter if it is not nil.
+func NewTrafficLimiter(q Quota, service string, limiter traffic.TrafficLimiter) traffic.TrafficLimiter {
+	return &trafficLimiter{
+		quota:   q,
+		service: service,
+		limiter: limiter,
+	}
+}
+
+func (l *trafficLimiter) In(ctx context.Context, key string, opts ...traffic.Option) traffic.Limiter {
+	var inner traffic.Limiter
+	if l.limiter != nil {
+		inner = l.limiter.In(ctx, key, opts...)
+	}
+	return l.wrap(inner, true, opts)
+}
+
+func (l *trafficLimiter) Out(ctx context.Context, key string, opts ...traffic.Option) traffic.Limiter {
+	var inner traffic.Limiter
+	if l.limiter != nil {
+		inner = l.limiter.Out(ctx, key, opts...)
+	}
+	return l.wrap(inner, false, opts)
+}
+
+func (l *trafficLimiter) wrap(inner traffic.Limiter, up bool, opts []traffic.Option) traffic.Limiter {
+	var options traffic.Options
+	for _, opt := range opts {
+		opt(&options)
+	}
+	// only the authenticated clients have quotas.
+	if options.Client == "" {
+		return inner
+	}
+	return &limiter{
+		quota:   l.quota,
+		client:  options.Client,
+		service: l.service,
+		up:      up,
+		inner:   inner,
+	}
+}
+
+// limiter records the bytes passed through it.
+type limiter struct {
+	quota   Quota
+	client  string
+	service string
+	up      bool
+	inner   traffic.Limiter
+}
+
+func (l *limiter) Wait(ctx context.Context, n int) int {
+	if l.inner != nil {
+		n = l.inner.Wait(ctx, n)
+	}
+	if l.up {
+		l.quota.Record(ctx, l.client, l.service, int64(n), 0)
+	} else {
+		l.quota.Record(ctx, l.client, l.service, 0, int64(n))
+	}
+	return n
+}
+
+func (l *limiter) Limit() int {
+	if l.inner != nil {
+		return l.inner.Limit()
+	}
+	return 0
+}
+
+func (l *limiter) Set(n int) {
+	if l.inner != nil {
+		l.inner.Set(n)
+	}
+}
@@ -0,0 +1,727 @@
+package quota
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/alecthomas/units"
+	"github.com/go-gost/core/logger"
+	xauth "github.com/go-gost/x/auth"
+	"github.com/go-gost/x/internal/conntrack"
+	"github.com/go-gost/x/internal/loader"
+)
+
+const (
+	// the subject prefix of the limit of a client.
+	SubjectUser = "user:"
+	// the subject prefix of the limit of the clients in a group.
+	SubjectGroup = "group:"
+	// the subject of the limit of all the clients.
+	SubjectAll = "*"
+)
+
+const (
+	PeriodDaily   = "daily"
+	PeriodMonthly = "monthly"
+)
+
+var (
+	errNotRestored = errors.New("the stored usage is not restored")
+)
+
+const (
+	defaultSaveInterval = 10 * time.Second
+	// how long the resolved limit of a client is used before resolving it again,
+	// as the groups of the client may change.
+	limitTTL = 30 * time.Second
+)
+
+// Quota limits the total bytes transferred by each client in a period.
+type Quota interface {
+	// Allow reports whether the client can open new connections,
+	// it is false if the client has exhausted its hard limit.
+	Allow(ctx context.Context, client string) bool
+	// Record records the bytes transferred by the client through the service,
+	// up is the bytes received from the client and down is the bytes sent to the client.
+	Record(ctx context.Context, client, service string, up, down int64)
+	// Usage returns the usage of the clients in the current period.
+	Usage(ctx context.Context) []Usage
+	// Reset clears the usage of the client, or of all the clients if client is empty.
+	Reset(ctx context.Context, client string)
+}
+
+// Traffic is the bytes transferred.
+type Traffic struct {
+	// the bytes received from the client.
+	Up int64 `json:"up"`
+	// the bytes sent to the client.
+	Down int64 `json:"down"`
+}
+
+// Usage is the usage of a client in the current period.
+type Usage struct {
+	Client string `json:"client"`
+	Traffic
+	// the traffic of the client through each service.
+	Services map[string]Traffic `json:"services,omitempty"`
+	// the limit of the total bytes, 0 for no limit.
+	Limit int64 `json:"limit"`
+	// the limit is a soft limit, which is only reported when exceeded.
+	Soft bool `json:"soft,omitempty"`
+	// the client has exceeded its limit.
+	Exceeded bool `json:"exceeded"`
+	// the start of the current period.
+	Start time.Time `json:"start"`
+}
+
+// limit is the limit of the total bytes of a client.
+type limit struct {
+	bytes int64
+	soft  bool
+}
+
+// ValidateLimit checks the limit string s,
+// which is in the form of "<subject> <bytes> [hard|soft]".
+func ValidateLimit(s string) error {
+	_, _, err := parseLimit(s)
+	return err
+}
+
+// parseLimit parses a limit in the format: <subject> <bytes> [hard|soft],
+// the subject is user:<client ID>, group:<group> or * for all the clients.
+func parseLimit(s string) (string, *limit, error) {
+	fields := strings.Fields(s)
+	if len(fields) < 2 || len(fields) > 3 {
+		return "", nil, fmt.Errorf("invalid limit: %q", s)
+	}
+
+	subject := fields[0]
+	if subject != SubjectAll &&
+		!strings.HasPrefix(subject, SubjectUser) &&
+		!strings.HasPrefix(subject, SubjectGroup) {
+		return "", nil, fmt.Errorf("invalid limit: %q: invalid subject", s)
+	}
+
+	n, err := units.ParseBase2Bytes(fields[1])
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid limit: %q: %v", s, err)
+	}
+
+	lim := &limit{bytes: int64(n)}
+	if len(fields) == 3 {
+		switch strings.ToLower(fields[2]) {
+		case "soft":
+			lim.soft = true
+		case "hard":
+		default:
+			return "", nil, fmt.Errorf("invalid limit: %q: unknown type %s", s, fields[2])
+		}
+	}
+	return subject, lim, nil
+}
+
+// ValidPeriod reports whether s is a valid reset period.
+func ValidPeriod(s string) bool {
+	switch s {
+	case "", PeriodDaily, PeriodMonthly:
+		return true
+	}
+	return false
+}
+
+type options struct {
+	period      string
+	limits      []string
+	cut         bool
+	store       Store
+	interval    time.Duration
+	fileLoader  loader.Loader
+	redisLoader loader.Loader
+	httpLoader  loader.Loader
+	reload      time.Duration
+	logger      logger.Logger
+}
+
+type Option func(opts *options)
+
+// PeriodOption sets the period to reset the usage, daily or monthly,
+// the usage is never reset if it is empty.
+func PeriodOption(period string) Option {
+	return func(opts *options) {
+		opts.period = period
+	}
+}
+
+func LimitsOption(limits ...string) Option {
+	return func(opts *options) {
+		opts.limits = limits
+	}
+}
+
+// CutOption sets whether to cut the existing connections of the clients exhausted their hard limits.
+func CutOption(cut bool) Option {
+	return func(opts *options) {
+		opts.cut = cut
+	}
+}
+
+// StoreOption sets the store to persist the usage and the interval to save it.
+func StoreOption(store Store, interval time.Duration) Option {
+	return func(opts *options) {
+		opts.store = store
+		opts.interval = interval
+	}
+}
+
+func ReloadPeriodOption(period time.Duration) Option {
+	return func(opts *options) {
+		opts.reload = period
+	}
+}
+
+func FileLoaderOption(fileLoader loader.Loader) Option {
+	return func(opts *options) {
+		opts.fileLoader = fileLoader
+	}
+}
+
+func RedisLoaderOption(redisLoader loader.Loader) Option {
+	return func(opts *options) {
+		opts.redisLoader = redisLoader
+	}
+}
+
+func HTTPLoaderOption(httpLoader loader.Loader) Option {
+	return func(opts *options) {
+		opts.httpLoader = httpLoader
+	}
+}
+
+func LoggerOption(logger logger.Logger) Option {
+	return func(opts *options) {
+		opts.logger = logger
+	}
+}
+
+// account is the usage of a client.
+type account struct {
+	services map[string]*Traffic
+	total    int64
+	limit    *limit
+	resolved time.Time
+	// the groups of the client, they are only known from the connections of the client.
+	groups []string
+	// the exceeding of the limit has been reported.
+	reported bool
+	// the connections of the client have been cut for exhausting the limit.
+	cut bool
+}
+
+type localQuota struct {
+	limits   map[string]*limit
+	accounts map[string]*account
+	// the start and the end of the current period.
+	start time.Time
+	end   time.Time
+	// the usage is restored from the store on the first use,
+	// and it is retried after the save interval until it succeeds.
+	restored  atomic.Bool
+	restoreMu sync.Mutex
+	retryAt   time.Time
+	// the usage has changed since the last save.
+	dirty      bool
+	mu         sync.Mutex
+	cancelFunc context.CancelFunc
+	options    options
+}
+
+// NewQuota creates a Quota which counts the bytes of the clients in memory,
+// and persists the usage to the store if any.
+func NewQuota(opts ...Option) Quota {
+	var options options
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.logger == nil {
+		options.logger = logger.Default()
+	}
+	if options.interval <= 0 {
+		options.interval = defaultSaveInterval
+	}
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	q := &localQuota{
+		limits:     make(map[string]*limit),
+		accounts:   make(map[string]*account),
+		cancelFunc: cancel,
+		options:    options,
+	}
+	q.start, q.end = q.period(time.Now())
+
+	if err := q.reload(ctx); err != nil {
+		options.logger.Warnf("reload: %v", err)
+	}
+	if options.store != nil {
+		go q.periodSave(ctx)
+	}
+	if options.reload > 0 {
+		go q.periodReload(ctx)
+	}
+
+	return q
+}
+
+// period returns the start and the end of the period containing the time t,
+// the end is zero if the usage is never reset.
+func (q *localQuota) period(t time.Time) (start, end time.Time) {
+	y, m, d := t.Date()
+	switch q.options.period {
+	case PeriodDaily:
+		start = time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+		end = start.AddDate(0, 0, 1)
+	case PeriodMonthly:
+		start = time.Date(y, m, 1, 0, 0, 0, 0, t.Location())
+		end = start.AddDate(0, 1, 0)
+	}
+	return
+}
+
+// rotate resets the usage if the current period is over, q.mu must be held.
+func (q *localQuota) rotate(now time.Time) {
+	if q.end.IsZero() || now.Before(q.end) {
+		return
+	}
+	q.start, q.end = q.period(now)
+	q.accounts = make(map[string]*account)
+	q.dirty = true
+	q.options.logger.Infof("new period from %s, usage reset", q.start.Format(time.DateOnly))
+}
+
+// account returns the account of the client, q.mu must be held.
+func (q *localQuota) account(client string, now time.Time) *account {
+	acc := q.accounts[client]
+	if acc == nil {
+		acc = &account{
+			services: make(map[string]*Traffic),
+		}
+		q.accounts[client] = acc
+	}
+	if now.Sub(acc.resolved) > limitTTL {
+		acc.limit = q.resolve(client, acc.groups)
+		acc.resolved = now
+	}
+	return acc
+}
+
+// resolve returns the limit of the client, the limit of the client takes precedence
+// over the limits of its groups, which take precedence over the limit of all the clients.
+// The largest limit is used if the client is in multiple groups with limits.
+func (q *localQuota) resolve(client string, groups []string) *limit {
+	if lim := q.limits[SubjectUser+client]; lim != nil {
+		return lim
+	}
+
+	var lim *limit
+	for _, group := range groups {
+		if v := q.limits[SubjectGroup+group]; v != nil && (lim == nil || v.bytes > lim.bytes) {
+			lim = v
+		}
+	}
+	if lim != nil {
+		return lim
+	}
+	return q.limits[SubjectAll]
+}
+
+func (q *localQuota) Allow(ctx context.Context, client string) bool {
+	if client == "" {
+		return true
+	}
+
+	q.init()
+
+	now := time.Now()
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.rotate(now)
+	acc := q.account(client, now)
+	if groups := xauth.GroupsFromContext(ctx); !slices.Equal(groups, acc.groups) {
+		acc.groups = groups
+		acc.limit = q.resolve(client, groups)
+	}
+	return acc.limit == nil || acc.limit.soft || acc.total < acc.limit.bytes
+}
+
+func (q *localQuota) Record(ctx context.Context, client, service string, up, down int64) {
+	if client == "" || up+down <= 0 {
+		return
+	}
+
+	q.init()
+
+	now := time.Now()
+
+	q.mu.Lock()
+
+	q.rotate(now)
+	acc := q.account(client, now)
+	t := acc.services[service]
+	if t == nil {
+		t = &Traffic{}
+		acc.services[service] = t
+	}
+	t.Up += up
+	t.Down += down
+	acc.total += up + down
+	q.dirty = true
+
+	lim := acc.limit
+	exceeded := lim != nil && acc.total >= lim.bytes
+	report := exceeded && !acc.reported
+	if report {
+		acc.reported = true
+	}
+	// the connections are cut once when the limit is exhausted,
+	// the new connections of the client are rejected by Allow since then.
+	cut := exceeded && !lim.soft && q.options.cut && !acc.cut
+	if cut {
+		acc.cut = true
+	}
+
+	q.mu.Unlock()
+
+	if report {
+		if lim.soft {
+			q.options.logger.Warnf("client %s exceeded the soft limit %d bytes", client, lim.bytes)
+		} else {
+			q.options.logger.Infof("client %s exhausted the limit %d bytes", client, lim.bytes)
+		}
+	}
+
+	if cut {
+		if n := conntrack.Kill(func(info *conntrack.Info) bool {
+			return info.ClientID == client
+		}); n > 0 {
+			q.options.logger.Debugf("client %s: %d connections cut", client, n)
+		}
+	}
+}
+
+func (q *localQuota) Usage(ctx context.Context) []Usage {
+	q.init()
+
+	now := time.Now()
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.rotate(now)
+
+	list := make([]Usage, 0, len(q.accounts))
+	for client, acc := range q.accounts {
+		acc = q.account(client, now)
+		u := Usage{
+			Client:   client,
+			Services: make(map[string]Traffic),
+			Start:    q.start,
+		}
+		for service, t := range acc.services {
+			u.Services[service] = *t
+			u.Up += t.Up
+			u.Down += t.Down
+		}
+		if acc.limit != nil {
+			u.Limit = acc.limit.bytes
+			u.Soft = acc.limit.soft
+			u.Exceeded = acc.total >= acc.limit.bytes
+		}
+		list = append(list, u)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Client < list[j].Client
+	})
+	return list
+}
+
+func (q *localQuota) Reset(ctx context.Context, client string) {
+	q.init()
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if client == "" {
+		q.accounts = make(map[string]*account)
+	} else {
+		delete(q.accounts, client)
+	}
+	q.dirty = true
+}
+
+func (q *localQuota) periodReload(ctx context.Context) error {
+	period := q.options.reload
+	if period < time.Second {
+		period = time.Second
+	}
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := q.reload(ctx); err != nil {
+				q.options.logger.Warnf("reload: %v", err)
+				// return err
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (q *localQuota) reload(ctx context.Context) error {
+	lines, err := q.load(ctx)
+	if err != nil {
+		return err
+	}
+	lines = append(slices.Clip(q.options.limits), lines...)
+	q.options.logger.Debugf("load items %d", len(lines))
+
+	limits := make(map[string]*limit)
+	for _, s := range lines {
+		subject, lim, err := parseLimit(s)
+		if err != nil {
+			q.options.logger.Warn(err)
+			continue
+		}
+		limits[subject] = lim
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.limits = limits
+	// resolve the limits again on the next use.
+	for _, acc := range q.accounts {
+		acc.resolved = time.Time{}
+		acc.reported = false
+		acc.cut = false
+	}
+
+	return nil
+}
+
+func (q *localQuota) load(ctx context.Context) (lines []string, err error) {
+	if q.options.fileLoader != nil {
+		r, er := q.options.fileLoader.Load(ctx)
+		if er != nil {
+			q.options.logger.Warnf("file loader: %v", er)
+		}
+		if v, _ := q.parseLines(r); v != nil {
+			lines = append(lines, v...)
+		}
+	}
+	if q.options.redisLoader != nil {
+		if lister, ok := q.options.redisLoader.(loader.Lister); ok {
+			list, er := lister.List(ctx)
+			if er != nil {
+				q.options.logger.Warnf("redis loader: %v", er)
+			}
+			for _, s := range list {
+				if line := q.parseLine(s); line != "" {
+					lines = append(lines, line)
+				}
+			}
+		} else {
+			r, er := q.options.redisLoader.Load(ctx)
+			if er != nil {
+				q.options.logger.Warnf("redis loader: %v", er)
+			}
+			if v, _ := q.parseLines(r); v != nil {
+				lines = append(lines, v...)
+			}
+		}
+	}
+	if q.options.httpLoader != nil {
+		r, er := q.options.httpLoader.Load(ctx)
+		if er != nil {
+			q.options.logger.Warnf("http loader: %v", er)
+		}
+		if v, _ := q.parseLines(r); v != nil {
+			lines = append(lines, v...)
+		}
+	}
+
+	return
+}
+
+func (q *localQuota) parseLines(r io.Reader) (lines []string, err error) {
+	if r == nil {
+		return
+	}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if line := q.parseLine(scanner.Text()); line != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	err = scanner.Err()
+	return
+}
+
+func (q *localQuota) parseLine(s string) string {
+	if n := strings.IndexByte(s, '#'); n >= 0 {
+		s = s[:n]
+	}
+	return strings.TrimSpace(s)
+}
+
+// init restores the usage from the store once, and reports whether the usage has been restored.
+// It is done on the first use rather than on the creation, so the usage saved by the Close
+// of the quota replaced by q is restored. A failed restore is retried after the save interval.
+func (q *localQuota) init() bool {
+	if q.options.store == nil || q.restored.Load() {
+		return true
+	}
+
+	q.restoreMu.Lock()
+	defer q.restoreMu.Unlock()
+
+	if q.restored.Load() {
+		return true
+	}
+	now := time.Now()
+	if now.Before(q.retryAt) {
+		return false
+	}
+	if err := q.restore(context.Background()); err != nil {
+		q.options.logger.Warnf("restore: %v", err)
+		q.retryAt = now.Add(q.options.interval)
+		return false
+	}
+	q.restored.Store(true)
+	return true
+}
+
+// restore loads the usage of the current period from the store,
+// it is added to the usage counted before the restore.
+func (q *localQuota) restore(ctx context.Context) error {
+	snap, err := q.options.store.Load(ctx)
+	if err != nil || snap == nil {
+		return err
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if !q.end.IsZero() && !snap.Start.Equal(q.start) {
+		q.options.logger.Debugf("stored usage of the period from %s is discarded", snap.Start.Format(time.DateOnly))
+		return nil
+	}
+
+	for client, services := range snap.Usage {
+		acc := q.accounts[client]
+		if acc == nil {
+			acc = &account{
+				services: make(map[string]*Traffic),
+			}
+			q.accounts[client] = acc
+		}
+		for service, t := range services {
+			if v := acc.services[service]; v != nil {
+				v.Up += t.Up
+				v.Down += t.Down
+			} else {
+				t := t
+				acc.services[service] = &t
+			}
+			acc.total += t.Up + t.Down
+		}
+	}
+	return nil
+}
+
+func (q *localQuota) periodSave(ctx context.Context) error {
+	ticker := time.NewTicker(q.options.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			// the stored usage is not overwritten until it is restored.
+			if !q.init() {
+				continue
+			}
+			if err := q.save(ctx); err != nil {
+				q.options.logger.Warnf("save: %v", err)
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// save saves the usage to the store if it has changed.
+func (q *localQuota) save(ctx context.Context) error {
+	if !q.restored.Load() {
+		return errNotRestored
+	}
+
+	q.mu.Lock()
+	if !q.dirty {
+		q.mu.Unlock()
+		return nil
+	}
+	q.rotate(time.Now())
+
+	snap := &Snapshot{
+		Start: q.start,
+		Usage: make(map[string]map[string]Traffic),
+	}
+	for client, acc := range q.accounts {
+		services := make(map[string]Traffic)
+		for service, t := range acc.services {
+			services[service] = *t
+		}
+		snap.Usage[client] = services
+	}
+	q.dirty = false
+	q.mu.Unlock()
+
+	if err := q.options.store.Save(ctx, snap); err != nil {
+		q.mu.Lock()
+		q.dirty = true
+		q.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+func (q *localQuota) Close() error {
+	q.cancelFunc()
+	if q.options.store != nil {
+		if err := q.save(context.Background()); err != nil {
+			q.options.logger.Warnf("save: %v", err)
+		}
+		q.options.store.Close()
+	}
+	if q.options.fileLoader != nil {
+		q.options.fileLoader.Close()
+	}
+	if q.options.redisLoader != nil {
+		q.options.redisLoader.Close()
+	}
+	return nil
+}
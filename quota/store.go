@@ -0,0 +1,168 @@
+package quota
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	DefaultRedisKey = "gost:quota"
+)
+
+// Snapshot is the usage of the clients in a period.
+type Snapshot struct {
+	// the start of the period.
+	Start time.Time `json:"start"`
+	// the traffic of the clients through each service.
+	Usage map[string]map[string]Traffic `json:"usage"`
+}
+
+// Store persists the usage, so it survives restarts.
+type Store interface {
+	// Load returns the saved usage, or nil if nothing is saved.
+	Load(ctx context.Context) (*Snapshot, error)
+	Save(ctx context.Context, snap *Snapshot) error
+	Close() error
+}
+
+type fileStore struct {
+	path string
+}
+
+// FileStore creates a Store which saves the usage to the local file in JSON.
+func FileStore(path string) Store {
+	return &fileStore{path: path}
+}
+
+func (s *fileStore) Load(ctx context.Context) (*Snapshot, error) {
+	b, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	snap := &Snapshot{}
+	if err := json.Unmarshal(b, snap); err != nil {
+		return nil, err
+	}
+	return snap, nil
+}
+
+// Save writes the usage to a temporary file and then renames it,
+// so the saved usage is never truncated.
+func (s *fileStore) Save(ctx context.Context, snap *Snapshot) error {
+	b, err := json.Marshal(snap)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), s.path)
+}
+
+func (s *fileStore) Close() error {
+	return nil
+}
+
+type redisStoreOptions struct {
+	db       int
+	password string
+	key      string
+}
+
+type RedisStoreOption func(opts *redisStoreOptions)
+
+func DBRedisStoreOption(db int) RedisStoreOption {
+	return func(opts *redisStoreOptions) {
+		opts.db = db
+	}
+}
+
+func PasswordRedisStoreOption(password string) RedisStoreOption {
+	return func(opts *redisStoreOptions) {
+		opts.password = password
+	}
+}
+
+func KeyRedisStoreOption(key string) RedisStoreOption {
+	return func(opts *redisStoreOptions) {
+		opts.key = key
+	}
+}
+
+type redisStore struct {
+	client *redis.Client
+	key    string
+}
+
+// RedisStore creates a Store which saves the usage to the redis string in JSON.
+func RedisStore(addr string, opts ...RedisStoreOption) Store {
+	var options redisStoreOptions
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&options)
+		}
+	}
+
+	key := options.key
+	if key == "" {
+		key = DefaultRedisKey
+	}
+
+	return &redisStore{
+		client: redis.NewClient(&redis.Options{
+			Addr:     addr,
+			Password: options.password,
+			DB:       options.db,
+		}),
+		key: key,
+	}
+}
+
+func (s *redisStore) Load(ctx context.Context) (*Snapshot, error) {
+	b, err := s.client.Get(ctx, s.key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	snap := &Snapshot{}
+	if err := json.Unmarshal(b, snap); err != nil {
+		return nil, err
+	}
+	return snap, nil
+}
+
+func (s *redisStore) Save(ctx context.Context, snap *Snapshot) error {
+	b, err := json.Marshal(snap)
+	if err != nil {
+		return err
+	}
+	return s.client.Set(ctx, s.key, b, 0).Err()
+}
+
+func (s *redisStore) Close() error {
+	return s.client.Close()
+}
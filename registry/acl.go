@@ -0,0 +1,39 @@
+package registry
+
+import (
+	"context"
+
+	"github.com/go-gost/x/acl"
+)
+
+type aclRegistry struct {
+	registry[acl.ACL]
+}
+
+func (r *aclRegistry) Register(name string, v acl.ACL) error {
+	return r.registry.Register(name, v)
+}
+
+func (r *aclRegistry) Get(name string) acl.ACL {
+	if name != "" {
+		return &aclWrapper{name: name, r: r}
+	}
+	return nil
+}
+
+func (r *aclRegistry) get(name string) acl.ACL {
+	return r.registry.Get(name)
+}
+
+type aclWrapper struct {
+	name string
+	r    *aclRegistry
+}
+
+func (w *aclWrapper) Allowed(ctx context.Context, network, addr string, opts ...acl.AllowOption) bool {
+	v := w.r.get(w.name)
+	if v == nil {
+		return true
+	}
+	return v.Allowed(ctx, network, addr, opts...)
+}
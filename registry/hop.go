@@ -2,9 +2,11 @@ package registry
 
 import (
 	"context"
+	"time"
 
 	"github.com/go-gost/core/chain"
 	"github.com/go-gost/core/hop"
+	"github.com/go-gost/core/selector"
 )
 
 type hopRegistry struct {
@@ -31,6 +33,10 @@ type hopWrapper struct {
 	r    *hopRegistry
 }
 
+func (w *hopWrapper) Name() string {
+	return w.name
+}
+
 func (w *hopWrapper) Nodes() []*chain.Node {
 	v := w.r.get(w.name)
 	if v == nil {
@@ -42,6 +48,28 @@ func (w *hopWrapper) Nodes() []*chain.Node {
 	return nil
 }
 
+// Race returns the racing options of the hop if it supports racing.
+func (w *hopWrapper) Race() (int, time.Duration) {
+	v := w.r.get(w.name)
+	if r, ok := v.(interface {
+		Race() (int, time.Duration)
+	}); ok {
+		return r.Race()
+	}
+	return 0, 0
+}
+
+// Selector returns the selector of the nodes of the hop if it has one.
+func (w *hopWrapper) Selector() selector.Selector[*chain.Node] {
+	v := w.r.get(w.name)
+	if s, ok := v.(interface {
+		Selector() selector.Selector[*chain.Node]
+	}); ok {
+		return s.Selector()
+	}
+	return nil
+}
+
 func (w *hopWrapper) Select(ctx context.Context, opts ...hop.SelectOption) *chain.Node {
 	v := w.r.get(w.name)
 	if v == nil {
@@ -0,0 +1,63 @@
+package registry
+
+import (
+	"context"
+
+	"github.com/go-gost/x/quota"
+)
+
+type quotaRegistry struct {
+	registry[quota.Quota]
+}
+
+func (r *quotaRegistry) Register(name string, v quota.Quota) error {
+	return r.registry.Register(name, v)
+}
+
+func (r *quotaRegistry) Get(name string) quota.Quota {
+	if name != "" {
+		return &quotaWrapper{name: name, r: r}
+	}
+	return nil
+}
+
+func (r *quotaRegistry) get(name string) quota.Quota {
+	return r.registry.Get(name)
+}
+
+type quotaWrapper struct {
+	name string
+	r    *quotaRegistry
+}
+
+func (w *quotaWrapper) Allow(ctx context.Context, client string) bool {
+	v := w.r.get(w.name)
+	if v == nil {
+		return true
+	}
+	return v.Allow(ctx, client)
+}
+
+func (w *quotaWrapper) Record(ctx context.Context, client, service string, up, down int64) {
+	v := w.r.get(w.name)
+	if v == nil {
+		return
+	}
+	v.Record(ctx, client, service, up, down)
+}
+
+func (w *quotaWrapper) Usage(ctx context.Context) []quota.Usage {
+	v := w.r.get(w.name)
+	if v == nil {
+		return nil
+	}
+	return v.Usage(ctx)
+}
+
+func (w *quotaWrapper) Reset(ctx context.Context, client string) {
+	v := w.r.get(w.name)
+	if v == nil {
+		return
+	}
+	v.Reset(ctx, client)
+}
@@ -22,6 +22,9 @@ import (
 	"github.com/go-gost/core/router"
 	"github.com/go-gost/core/sd"
 	"github.com/go-gost/core/service"
+	"github.com/go-gost/x/acl"
+	"github.com/go-gost/x/quota"
+	"github.com/go-gost/x/rule"
 )
 
 var (
@@ -50,6 +53,9 @@ var (
 	ingressReg reg.Registry[ingress.Ingress] = new(ingressRegistry)
 	routerReg  reg.Registry[router.Router]   = new(routerRegistry)
 	sdReg      reg.Registry[sd.SD]           = new(sdRegistry)
+	rulesReg   reg.Registry[rule.Rules]      = new(rulesRegistry)
+	aclReg     reg.Registry[acl.ACL]         = new(aclRegistry)
+	quotaReg   reg.Registry[quota.Quota]     = new(quotaRegistry)
 
 	loggerReg reg.Registry[logger.Logger] = new(loggerRegistry)
 )
@@ -179,6 +185,18 @@ func SDRegistry() reg.Registry[sd.SD] {
 	return sdReg
 }
 
+func RulesRegistry() reg.Registry[rule.Rules] {
+	return rulesReg
+}
+
+func ACLRegistry() reg.Registry[acl.ACL] {
+	return aclReg
+}
+
+func QuotaRegistry() reg.Registry[quota.Quota] {
+	return quotaReg
+}
+
 func LoggerRegistry() reg.Registry[logger.Logger] {
 	return loggerReg
 }
@@ -0,0 +1,39 @@
+package registry
+
+import (
+	"context"
+
+	"github.com/go-gost/x/rule"
+)
+
+type rulesRegistry struct {
+	registry[rule.Rules]
+}
+
+func (r *rulesRegistry) Register(name string, v rule.Rules) error {
+	return r.registry.Register(name, v)
+}
+
+func (r *rulesRegistry) Get(name string) rule.Rules {
+	if name != "" {
+		return &rulesWrapper{name: name, r: r}
+	}
+	return nil
+}
+
+func (r *rulesRegistry) get(name string) rule.Rules {
+	return r.registry.Get(name)
+}
+
+type rulesWrapper struct {
+	name string
+	r    *rulesRegistry
+}
+
+func (w *rulesWrapper) Match(ctx context.Context, network, addr string, opts ...rule.MatchOption) *rule.Target {
+	v := w.r.get(w.name)
+	if v == nil {
+		return nil
+	}
+	return v.Match(ctx, network, addr, opts...)
+}
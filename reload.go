@@ -0,0 +1,132 @@
+package main
+
+import (
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/go-gost/core/logger"
+	"github.com/go-gost/x/config"
+	"github.com/go-gost/x/config/loader"
+	"github.com/go-gost/x/config/revision"
+)
+
+const (
+	// reloadDelay is used to merge the burst of file events
+	// generated by editors when saving the config file.
+	reloadDelay = time.Second
+)
+
+// watch reloads the config when the config files are changed or SIGHUP is received.
+func (p *program) watch() {
+	log := logger.Default().WithFields(map[string]any{
+		"kind": "reload",
+		"file": cfgFile,
+	})
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGHUP)
+	defer signal.Stop(sigs)
+
+	var events chan fsnotify.Event
+	var errs chan error
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		log.Warnf("watch: %v", err)
+	} else {
+		defer watcher.Close()
+		p.watchSources(watcher, log)
+		events, errs = watcher.Events, watcher.Errors
+	}
+
+	timer := time.NewTimer(reloadDelay)
+	timer.Stop()
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-sigs:
+			log.Info("SIGHUP received, reloading")
+			p.reload(log)
+			if watcher != nil {
+				p.watchSources(watcher, log)
+			}
+
+		case ev, ok := <-events:
+			if !ok {
+				events = nil
+				continue
+			}
+			if !p.isSource(ev.Name) {
+				continue
+			}
+			if ev.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename|fsnotify.Remove) == 0 {
+				continue
+			}
+			timer.Reset(reloadDelay)
+
+		case <-timer.C:
+			log.Info("config file changed, reloading")
+			p.reload(log)
+			p.watchSources(watcher, log)
+
+		case err, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+			log.Warnf("watch: %v", err)
+		}
+	}
+}
+
+// watchSources adds the directories of the config files and include patterns to the watcher,
+// the files may be replaced by editors or configmap updates, so the directories are watched rather than the files.
+func (p *program) watchSources(watcher *fsnotify.Watcher, log logger.Logger) {
+	watched := make(map[string]bool)
+	for _, name := range watcher.WatchList() {
+		watched[name] = true
+	}
+	for _, src := range p.sources {
+		dir := filepath.Dir(src)
+		if watched[dir] {
+			continue
+		}
+		if err := watcher.Add(dir); err != nil {
+			log.Warnf("watch: %v", err)
+			continue
+		}
+		watched[dir] = true
+	}
+}
+
+// isSource reports whether the file name is a config file or matches an include pattern.
+func (p *program) isSource(name string) bool {
+	name, _ = filepath.Abs(name)
+	for _, src := range p.sources {
+		if ok, _ := filepath.Match(src, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *program) reload(log logger.Logger) {
+	cfg, err := p.loadConfig()
+	if err != nil {
+		log.Errorf("reload: %v", err)
+		return
+	}
+
+	err = loader.Apply(cfg)
+	revision.Record(config.Global(), "", "reload")
+	if err != nil {
+		log.Errorf("reload: %v", err)
+		return
+	}
+	log.Info("reload done")
+}
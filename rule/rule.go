@@ -0,0 +1,289 @@
+package rule
+
+import (
+	"context"
+	"errors"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/go-gost/core/chain"
+	"github.com/go-gost/core/logger"
+	ctxvalue "github.com/go-gost/x/internal/ctx"
+	"github.com/go-gost/x/internal/matcher"
+	xnet "github.com/go-gost/x/internal/net"
+)
+
+var (
+	ErrRejected = errors.New("rule: connection rejected")
+)
+
+// Target is the target of the connections matched by a rule.
+type Target struct {
+	// Chain is the chain (or chain group) to route the connections through,
+	// the connections are dialed directly if it is nil.
+	Chain chain.Chainer
+	// Reject rejects the connections.
+	Reject bool
+}
+
+type MatchOptions struct {
+	// Host is the destination address before resolving.
+	Host string
+}
+
+type MatchOption func(opts *MatchOptions)
+
+func HostMatchOption(host string) MatchOption {
+	return func(opts *MatchOptions) {
+		opts.Host = host
+	}
+}
+
+// Rules is an ordered list of rules consulted by the router before dialing.
+type Rules interface {
+	// Match returns the target of the first rule matched by the connection,
+	// it returns nil if no rule is matched.
+	Match(ctx context.Context, network, addr string, opts ...MatchOption) *Target
+}
+
+// Rule is a rule of the rule list.
+// The rule is matched if all the non-empty matchers are matched,
+// a matcher is matched if any of its values is matched,
+// so the rule without matchers matches all the connections.
+type Rule struct {
+	Name string
+	// plain domains such as 'example.com', or '.example.com' for the domain and its subdomains.
+	Domains []string
+	// wildcard domains such as '*.example.com', with an optional port (range).
+	Wildcards []string
+	// IP addresses or CIDR notation IP networks.
+	CIDRs []string
+	// ports or port ranges such as '8000-9000'.
+	Ports []string
+	// tcp or udp.
+	Networks []string
+	// client IDs of the authenticated clients.
+	Clients []string
+	// sniffed protocols, such as http, tls and ssh.
+	Protocols []string
+	Target    Target
+}
+
+type options struct {
+	rules  []*Rule
+	logger logger.Logger
+}
+
+type Option func(opts *options)
+
+func RulesOption(rules []*Rule) Option {
+	return func(opts *options) {
+		opts.rules = rules
+	}
+}
+
+func LoggerOption(logger logger.Logger) Option {
+	return func(opts *options) {
+		opts.logger = logger
+	}
+}
+
+type rule struct {
+	name            string
+	domainMatcher   matcher.Matcher
+	wildcardMatcher matcher.Matcher
+	cidrMatcher     matcher.Matcher
+	ports           []*xnet.PortRange
+	networks        []string
+	clients         []string
+	protocols       []string
+	target          *Target
+}
+
+type localRules struct {
+	rules   []*rule
+	options options
+}
+
+// NewRules creates a Rules with the ordered rule list.
+func NewRules(opts ...Option) Rules {
+	var options options
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.logger == nil {
+		options.logger = logger.Default()
+	}
+
+	p := &localRules{
+		options: options,
+	}
+	for i, r := range options.rules {
+		if r == nil {
+			continue
+		}
+		p.rules = append(p.rules, p.parseRule(i, r))
+	}
+	return p
+}
+
+func (p *localRules) parseRule(i int, r *Rule) *rule {
+	name := r.Name
+	if name == "" {
+		name = strconv.Itoa(i)
+	}
+	rl := &rule{
+		name:      name,
+		networks:  r.Networks,
+		clients:   r.Clients,
+		protocols: r.Protocols,
+		target:    &r.Target,
+	}
+
+	if len(r.Domains) > 0 {
+		rl.domainMatcher = matcher.DomainMatcher(r.Domains)
+	}
+	if len(r.Wildcards) > 0 {
+		rl.wildcardMatcher = matcher.WildcardMatcher(r.Wildcards)
+	}
+
+	var inets []*net.IPNet
+	for _, s := range r.CIDRs {
+		if ip := net.ParseIP(s); ip != nil {
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			inets = append(inets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, inet, err := net.ParseCIDR(s)
+		if err != nil {
+			p.options.logger.Warnf("rule %s: invalid CIDR %s", name, s)
+			continue
+		}
+		inets = append(inets, inet)
+	}
+	if len(r.CIDRs) > 0 {
+		rl.cidrMatcher = matcher.CIDRMatcher(inets)
+	}
+
+	for _, s := range r.Ports {
+		pr := &xnet.PortRange{}
+		if err := pr.Parse(s); err != nil {
+			p.options.logger.Warnf("rule %s: invalid port %s", name, s)
+			continue
+		}
+		rl.ports = append(rl.ports, pr)
+	}
+
+	return rl
+}
+
+func (p *localRules) Match(ctx context.Context, network, addr string, opts ...MatchOption) *Target {
+	if p == nil || len(p.rules) == 0 {
+		return nil
+	}
+
+	var options MatchOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	c := &conn{
+		network:  strings.TrimRight(network, "46"),
+		client:   string(ctxvalue.ClientIDFromContext(ctx)),
+		protocol: string(ctxvalue.ProtocolFromContext(ctx)),
+	}
+	c.ip, _, _ = net.SplitHostPort(addr)
+	if c.ip == "" {
+		c.ip = addr
+	}
+	if net.ParseIP(c.ip) == nil {
+		c.ip = ""
+	}
+
+	c.host = options.Host
+	if c.host == "" {
+		c.host = addr
+	}
+	host, sp, _ := net.SplitHostPort(c.host)
+	if host == "" {
+		host = c.host
+	}
+	if sp == "" {
+		_, sp, _ = net.SplitHostPort(addr)
+	}
+	c.port, _ = strconv.Atoi(sp)
+	if net.ParseIP(host) == nil {
+		c.domain = host
+	} else if c.ip == "" {
+		c.ip = host
+	}
+
+	for _, r := range p.rules {
+		if r.match(c) {
+			p.options.logger.Debugf("rule %s matched: %s/%s", r.name, c.host, network)
+			return r.target
+		}
+	}
+	return nil
+}
+
+// conn is the connection to be matched.
+type conn struct {
+	network string
+	// the destination address before resolving.
+	host string
+	// the domain of the destination, empty if the destination is an IP address.
+	domain   string
+	ip       string
+	port     int
+	client   string
+	protocol string
+}
+
+func (r *rule) match(c *conn) bool {
+	if r.domainMatcher != nil && (c.domain == "" || !r.domainMatcher.Match(c.domain)) {
+		return false
+	}
+	if r.wildcardMatcher != nil && (c.domain == "" || !r.wildcardMatcher.Match(c.host)) {
+		return false
+	}
+	if r.cidrMatcher != nil && (c.ip == "" || !r.cidrMatcher.Match(c.ip)) {
+		return false
+	}
+	if len(r.ports) > 0 && !r.matchPort(c.port) {
+		return false
+	}
+	if len(r.networks) > 0 && !contains(r.networks, c.network) {
+		return false
+	}
+	if len(r.clients) > 0 && (c.client == "" || !slices.Contains(r.clients, c.client)) {
+		return false
+	}
+	if len(r.protocols) > 0 && (c.protocol == "" || !contains(r.protocols, c.protocol)) {
+		return false
+	}
+	return true
+}
+
+func (r *rule) matchPort(port int) bool {
+	for _, pr := range r.ports {
+		if pr.Contains(port) {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,252 @@
+package selector
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-gost/core/selector"
+)
+
+const (
+	DefaultBreakerWindow         = 60 * time.Second
+	DefaultBreakerMinRequests    = 10
+	DefaultBreakerFailureRate    = 0.5
+	DefaultBreakerOpenTimeout    = 30 * time.Second
+	DefaultBreakerHalfOpenTrials = 1
+)
+
+const (
+	// the number of the buckets of the sliding window.
+	breakerBuckets = 10
+)
+
+type BreakerState int
+
+const (
+	BreakerClosed BreakerState = iota
+	BreakerOpen
+	BreakerHalfOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+type BreakerOptions struct {
+	// the sliding window of the connection outcomes.
+	Window time.Duration
+	// the minimum number of the connections in the window to open the breaker.
+	MinRequests int
+	// the failure rate in the window to open the breaker.
+	FailureRate float64
+	// how long the breaker stays open before the trial connections.
+	OpenTimeout time.Duration
+	// the number of the successful trial connections to close the breaker.
+	HalfOpenTrials int
+}
+
+type bucket struct {
+	// the index of the bucket since the unix epoch.
+	index     int64
+	successes int
+	failures  int
+}
+
+// breaker is the circuit breaker of an object.
+type breaker struct {
+	opts    BreakerOptions
+	width   int64
+	mu      sync.Mutex
+	state   BreakerState
+	buckets [breakerBuckets]bucket
+	// the time the breaker is opened or half-opened.
+	since time.Time
+	// the trial connections let through and succeeded in the half-open state.
+	trials    int
+	successes int
+}
+
+func newBreaker(opts BreakerOptions) *breaker {
+	width := int64(opts.Window) / breakerBuckets
+	if width <= 0 {
+		width = 1
+	}
+	return &breaker{
+		opts:  opts,
+		width: width,
+	}
+}
+
+// ready reports whether a connection would be allowed through the object,
+// it does not take the trial connections of the half-open state.
+func (b *breaker) ready(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		return now.Sub(b.since) >= b.opts.OpenTimeout
+	case BreakerHalfOpen:
+		return b.trials < b.opts.HalfOpenTrials || now.Sub(b.since) >= b.opts.OpenTimeout
+	default:
+		return true
+	}
+}
+
+// allow reports whether a connection is allowed through the object.
+// The trial connections of the half-open state are counted when they are allowed,
+// a trial which does not report its outcome in the open timeout is given to another connection.
+func (b *breaker) allow(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		if now.Sub(b.since) < b.opts.OpenTimeout {
+			return false
+		}
+		b.halfOpen(now)
+	case BreakerHalfOpen:
+		if b.trials >= b.opts.HalfOpenTrials {
+			if now.Sub(b.since) < b.opts.OpenTimeout {
+				return false
+			}
+			b.halfOpen(now)
+		}
+	default:
+		return true
+	}
+
+	b.trials++
+	return true
+}
+
+// record records the outcome of a connection through the object.
+func (b *breaker) record(now time.Time, ok bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+	case BreakerHalfOpen:
+		if !ok {
+			b.open(now)
+			return
+		}
+		b.successes++
+		if b.successes >= b.opts.HalfOpenTrials {
+			b.close()
+		}
+	default:
+		index := now.UnixNano() / b.width
+		bk := &b.buckets[index%breakerBuckets]
+		if bk.index != index {
+			*bk = bucket{index: index}
+		}
+		if ok {
+			bk.successes++
+		} else {
+			bk.failures++
+		}
+
+		var total, failures int
+		for i := range b.buckets {
+			if bk := &b.buckets[i]; index-bk.index < breakerBuckets {
+				total += bk.successes + bk.failures
+				failures += bk.failures
+			}
+		}
+		if total >= b.opts.MinRequests && float64(failures) >= b.opts.FailureRate*float64(total) {
+			b.open(now)
+		}
+	}
+}
+
+func (b *breaker) open(now time.Time) {
+	b.state = BreakerOpen
+	b.since = now
+}
+
+func (b *breaker) halfOpen(now time.Time) {
+	b.state = BreakerHalfOpen
+	b.since = now
+	b.trials = 0
+	b.successes = 0
+}
+
+func (b *breaker) close() {
+	b.state = BreakerClosed
+	b.buckets = [breakerBuckets]bucket{}
+}
+
+func (b *breaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+type breakerFilter[T any] struct {
+	opts BreakerOptions
+}
+
+// CircuitBreakerFilter filters the objects whose circuit breakers are open,
+// as well as the objects marked down manually or unhealthy.
+// The breaker of an object is opened when the failure rate of its connections
+// in the sliding window reaches the threshold, the open object is filtered out
+// for the open timeout, then only the trial connections are let through
+// until the object is re-admitted or the breaker is reopened by a failed trial.
+func CircuitBreakerFilter[T any](opts BreakerOptions) selector.Filter[T] {
+	if opts.Window <= 0 {
+		opts.Window = DefaultBreakerWindow
+	}
+	if opts.MinRequests <= 0 {
+		opts.MinRequests = DefaultBreakerMinRequests
+	}
+	if opts.FailureRate <= 0 || opts.FailureRate > 1 {
+		opts.FailureRate = DefaultBreakerFailureRate
+	}
+	if opts.OpenTimeout <= 0 {
+		opts.OpenTimeout = DefaultBreakerOpenTimeout
+	}
+	if opts.HalfOpenTrials <= 0 {
+		opts.HalfOpenTrials = DefaultBreakerHalfOpenTrials
+	}
+	return &breakerFilter[T]{
+		opts: opts,
+	}
+}
+
+// Filter filters the objects which do not allow connections.
+// The trial connections are taken by State.Trial when the object is dialed,
+// so the objects selected but not dialed do not consume the trials.
+func (f *breakerFilter[T]) Filter(ctx context.Context, vs ...T) []T {
+	if len(vs) <= 1 {
+		return vs
+	}
+
+	var l []T
+	for _, v := range vs {
+		if !f.Filtered(v) {
+			l = append(l, v)
+		}
+	}
+	return l
+}
+
+// Filtered implements objectFilter, it reports whether the object v does not allow connections.
+func (f *breakerFilter[T]) Filtered(v T) bool {
+	s := StateOf(v)
+	if s.Down() || s.Unhealthy() {
+		return true
+	}
+	b := s.breakerOf(f.opts)
+	return b != nil && !b.ready(time.Now())
+}
@@ -30,37 +30,51 @@ func (f *failFilter[T]) Filter(ctx context.Context, vs ...T) []T {
 	}
 	var l []T
 	for _, v := range vs {
-		maxFails := f.maxFails
-		failTimeout := f.failTimeout
-		if mi, _ := any(v).(metadata.Metadatable); mi != nil {
-			if md := mi.Metadata(); md != nil {
-				if md.IsExists(labelMaxFails) {
-					maxFails = mdutil.GetInt(md, labelMaxFails)
-				}
-				if md.IsExists(labelFailTimeout) {
-					failTimeout = mdutil.GetDuration(md, labelFailTimeout)
-				}
-			}
-		}
-		if maxFails <= 0 {
-			maxFails = 1
-		}
-		if failTimeout <= 0 {
-			failTimeout = DefaultFailTimeout
+		if !f.Filtered(v) {
+			l = append(l, v)
 		}
+	}
+	return l
+}
+
+// Filtered implements objectFilter, it reports whether the object v is dead.
+func (f *failFilter[T]) Filtered(v T) bool {
+	return IsFailed(v, f.maxFails, f.failTimeout)
+}
+
+// IsFailed reports whether the object v is dead for FailFilter,
+// the maxFails and failTimeout in the metadata of v take precedence.
+// An object marked down manually or failed the health check is dead
+// until it is marked up or recovers, regardless of its fail count and the fail timeout.
+func IsFailed(v any, maxFails int, failTimeout time.Duration) bool {
+	if s := StateOf(v); s.Down() || s.Unhealthy() {
+		return true
+	}
 
-		if mi, _ := any(v).(selector.Markable); mi != nil {
-			if marker := mi.Marker(); marker != nil {
-				if marker.Count() < int64(maxFails) ||
-					time.Since(marker.Time()) >= failTimeout {
-					l = append(l, v)
-				}
-				continue
+	if mi, _ := v.(metadata.Metadatable); mi != nil {
+		if md := mi.Metadata(); md != nil {
+			if md.IsExists(labelMaxFails) {
+				maxFails = mdutil.GetInt(md, labelMaxFails)
+			}
+			if md.IsExists(labelFailTimeout) {
+				failTimeout = mdutil.GetDuration(md, labelFailTimeout)
 			}
 		}
-		l = append(l, v)
 	}
-	return l
+	if maxFails <= 0 {
+		maxFails = 1
+	}
+	if failTimeout <= 0 {
+		failTimeout = DefaultFailTimeout
+	}
+
+	if mi, _ := v.(selector.Markable); mi != nil {
+		if marker := mi.Marker(); marker != nil {
+			return marker.Count() >= int64(maxFails) &&
+				time.Since(marker.Time()) < failTimeout
+		}
+	}
+	return false
 }
 
 type backupFilter[T any] struct{}
@@ -79,11 +93,9 @@ func (f *backupFilter[T]) Filter(ctx context.Context, vs ...T) []T {
 
 	var l, backups []T
 	for _, v := range vs {
-		if mi, _ := any(v).(metadata.Metadatable); mi != nil {
-			if mdutil.GetBool(mi.Metadata(), labelBackup) {
-				backups = append(backups, v)
-				continue
-			}
+		if IsBackup(v) {
+			backups = append(backups, v)
+			continue
 		}
 		l = append(l, v)
 	}
@@ -0,0 +1,226 @@
+package selector
+
+import (
+	"context"
+	"hash/fnv"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/go-gost/core/chain"
+	"github.com/go-gost/core/logger"
+	"github.com/go-gost/core/selector"
+	ctxvalue "github.com/go-gost/x/internal/ctx"
+)
+
+// hash keys of the hash strategies.
+const (
+	// the hash source set by the service and handlers, the client IP or the destination host.
+	HashKeySource = ""
+	// the client IP.
+	HashKeyClientIP = "ip"
+	// the authenticated client ID.
+	HashKeyClient = "client"
+	// the destination host.
+	HashKeyHost = "host"
+	// the server name of the TLS client hello.
+	HashKeySNI = "sni"
+	// the HTTP request header, in the form of header:<name>.
+	HashKeyHeaderPrefix = "header:"
+	// the HTTP request cookie, in the form of cookie:<name>.
+	HashKeyCookiePrefix = "cookie:"
+)
+
+// hashValue returns the value of the hash key from the context,
+// the hash source is used if the value of the key is not available.
+func hashValue(ctx context.Context, key string) string {
+	h := ctxvalue.HashFromContext(ctx)
+
+	var v string
+	switch {
+	case key == HashKeyClientIP:
+		v = string(ctxvalue.ClientAddrFromContext(ctx))
+		if host, _, _ := net.SplitHostPort(v); host != "" {
+			v = host
+		}
+	case key == HashKeyClient:
+		v = string(ctxvalue.ClientIDFromContext(ctx))
+	case key == HashKeyHost:
+		if h != nil {
+			v = h.Host
+			if host, _, err := net.SplitHostPort(v); err == nil {
+				v = host
+			}
+		}
+	case key == HashKeySNI:
+		if h != nil {
+			v = h.SNI
+		}
+	case strings.HasPrefix(key, HashKeyHeaderPrefix):
+		if h != nil && h.Header != nil {
+			v = h.Header.Get(strings.TrimPrefix(key, HashKeyHeaderPrefix))
+		}
+	case strings.HasPrefix(key, HashKeyCookiePrefix):
+		if h != nil && h.Header != nil {
+			req := http.Request{Header: h.Header}
+			if c, _ := req.Cookie(strings.TrimPrefix(key, HashKeyCookiePrefix)); c != nil {
+				v = c.Value
+			}
+		}
+	}
+
+	if v == "" && h != nil {
+		v = h.Source
+	}
+	return v
+}
+
+// ValidHashKey reports whether the hash key is supported by the hash strategies.
+func ValidHashKey(key string) bool {
+	switch key {
+	case HashKeySource, HashKeyClientIP, HashKeyClient, HashKeyHost, HashKeySNI:
+		return true
+	}
+	for _, prefix := range []string{HashKeyHeaderPrefix, HashKeyCookiePrefix} {
+		if strings.HasPrefix(key, prefix) && len(key) > len(prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+const (
+	// the number of virtual nodes per weight on the hash ring.
+	hashRingReplicas = 160
+	// the maximum number of hash rings cached for the different sets of the available nodes.
+	maxHashRings = 16
+)
+
+type consistentHashStrategy[T any] struct {
+	key   string
+	rings map[string]*hashRing
+	rw    *randomWeighted[T]
+	mu    sync.Mutex
+}
+
+// ConsistentHashStrategy is a strategy for node selector.
+// The node will be selected by the hash value of the hash key on a consistent hash ring
+// with virtual nodes proportional to the node weights,
+// so adding, removing or failing a node only remaps the keys of that node.
+// The node will be selected randomly by weight if the hash key has no value.
+func ConsistentHashStrategy[T any](key string) selector.Strategy[T] {
+	return &consistentHashStrategy[T]{
+		key:   key,
+		rings: make(map[string]*hashRing),
+		rw:    newRandomWeighted[T](),
+	}
+}
+
+func (s *consistentHashStrategy[T]) Apply(ctx context.Context, vs ...T) (v T) {
+	if len(vs) == 0 {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	value := hashValue(ctx, s.key)
+	if value == "" {
+		s.rw.Reset()
+		for i := range vs {
+			s.rw.Add(vs[i], Weight(vs[i]))
+		}
+		return s.rw.Next()
+	}
+
+	ids := make([]string, len(vs))
+	weights := make([]int, len(vs))
+	var b strings.Builder
+	for i := range vs {
+		ids[i] = hashID(vs[i], i)
+		weights[i] = Weight(vs[i])
+		b.WriteString(ids[i])
+		b.WriteByte('/')
+		b.WriteString(strconv.Itoa(weights[i]))
+		b.WriteByte(',')
+	}
+
+	ring := s.rings[b.String()]
+	if ring == nil {
+		if len(s.rings) >= maxHashRings {
+			s.rings = make(map[string]*hashRing)
+		}
+		ring = newHashRing(ids, weights)
+		s.rings[b.String()] = ring
+	}
+
+	i := ring.get(hash64(value))
+	logger.Default().Tracef("hash %s %s -> %s", s.key, value, ids[i])
+	return vs[i]
+}
+
+// hashID returns the identity of the object v on the hash ring,
+// the index i in the list is used if v has no name.
+func hashID(v any, i int) string {
+	switch t := v.(type) {
+	case *chain.Node:
+		if t != nil {
+			return t.Name + "@" + t.Addr
+		}
+	case interface{ Name() string }:
+		return t.Name()
+	}
+	return "#" + strconv.Itoa(i)
+}
+
+type hashRingPoint struct {
+	hash  uint64
+	index int
+}
+
+type hashRing struct {
+	points []hashRingPoint
+}
+
+func newHashRing(ids []string, weights []int) *hashRing {
+	r := &hashRing{}
+	for i, id := range ids {
+		for j := 0; j < hashRingReplicas*weights[i]; j++ {
+			r.points = append(r.points, hashRingPoint{
+				hash:  hash64(id + "#" + strconv.Itoa(j)),
+				index: i,
+			})
+		}
+	}
+	sort.Slice(r.points, func(i, j int) bool {
+		return r.points[i].hash < r.points[j].hash
+	})
+	return r
+}
+
+// get returns the index of the node owning the hash value h.
+func (r *hashRing) get(h uint64) int {
+	n := sort.Search(len(r.points), func(i int) bool {
+		return r.points[i].hash >= h
+	})
+	if n == len(r.points) {
+		n = 0
+	}
+	return r.points[n].index
+}
+
+func hash64(s string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	// finalizer of splitmix64 for a better distribution of the similar keys.
+	x := h.Sum64()
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"time"
 
+	"github.com/go-gost/core/metadata"
+	mdutil "github.com/go-gost/core/metadata/util"
 	"github.com/go-gost/core/selector"
 )
 
@@ -20,6 +22,26 @@ const (
 	labelFailTimeout = "failTimeout"
 )
 
+// Weight returns the weight of the object v set in its metadata, the default weight is 1.
+func Weight(v any) int {
+	weight := 0
+	if mi, _ := v.(metadata.Metadatable); mi != nil {
+		weight = mdutil.GetInt(mi.Metadata(), labelWeight)
+	}
+	if weight <= 0 {
+		weight = 1
+	}
+	return weight
+}
+
+// IsBackup reports whether the object v is marked as backup in its metadata.
+func IsBackup(v any) bool {
+	if mi, _ := v.(metadata.Metadatable); mi != nil {
+		return mdutil.GetBool(mi.Metadata(), labelBackup)
+	}
+	return false
+}
+
 type defaultSelector[T any] struct {
 	strategy selector.Strategy[T]
 	filters  []selector.Filter[T]
@@ -33,6 +55,7 @@ func NewSelector[T any](strategy selector.Strategy[T], filters ...selector.Filte
 }
 
 func (s *defaultSelector[T]) Select(ctx context.Context, vs ...T) (v T) {
+	vs = excludeFilter(ctx, vs)
 	for _, filter := range s.filters {
 		vs = filter.Filter(ctx, vs...)
 	}
@@ -41,3 +64,60 @@ func (s *defaultSelector[T]) Select(ctx context.Context, vs ...T) (v T) {
 	}
 	return s.strategy.Apply(ctx, vs...)
 }
+
+// objectFilter is a filter which can tell whether an object is filtered out by itself.
+type objectFilter[T any] interface {
+	// Filtered reports whether the object v is filtered out regardless of the other objects.
+	Filtered(v T) bool
+}
+
+// Filtered reports whether the object v is filtered out by the filters of the selector s,
+// it is the state of the object regardless of the other objects, as the only object is never filtered.
+func Filtered[T any](s selector.Selector[T], v T) bool {
+	ds, ok := s.(*defaultSelector[T])
+	if !ok {
+		return false
+	}
+	for _, filter := range ds.filters {
+		if f, ok := filter.(objectFilter[T]); ok && f.Filtered(v) {
+			return true
+		}
+	}
+	return false
+}
+
+type excludedKey struct{}
+
+// ContextWithExcluded returns a copy of ctx in which the objects vs are excluded from the selection,
+// along with the objects excluded by ctx.
+func ContextWithExcluded(ctx context.Context, vs ...any) context.Context {
+	excluded, _ := ctx.Value(excludedKey{}).([]any)
+	return context.WithValue(ctx, excludedKey{}, append(excluded[:len(excluded):len(excluded)], vs...))
+}
+
+func hasExcluded(ctx context.Context) bool {
+	excluded, _ := ctx.Value(excludedKey{}).([]any)
+	return len(excluded) > 0
+}
+
+func excludeFilter[T any](ctx context.Context, vs []T) []T {
+	excluded, _ := ctx.Value(excludedKey{}).([]any)
+	if len(excluded) == 0 {
+		return vs
+	}
+
+	var l []T
+	for _, v := range vs {
+		found := false
+		for _, e := range excluded {
+			if any(v) == e {
+				found = true
+				break
+			}
+		}
+		if !found {
+			l = append(l, v)
+		}
+	}
+	return l
+}
@@ -0,0 +1,236 @@
+package selector
+
+import (
+	"math"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-gost/core/metadata"
+)
+
+const (
+	// the metadata key of the runtime state.
+	labelState = "_state"
+)
+
+const (
+	// the smoothing factor of the moving averages of the latency and error rate.
+	ewmaAlpha = 0.3
+)
+
+// State is the runtime state of a node, it is kept in the metadata of the node.
+type State struct {
+	down      atomic.Bool
+	unhealthy atomic.Bool
+	successes atomic.Int64
+	latency   atomic.Int64
+	conns     atomic.Int64
+	// moving averages of the latency in nanoseconds and the error rate, stored as float64 bits.
+	latencyAvg atomic.Uint64
+	errorAvg   atomic.Uint64
+	// the time in unix nanoseconds of the latest observation or probe.
+	observed atomic.Int64
+	// the circuit breaker, created by the first CircuitBreakerFilter the object passes.
+	breaker atomic.Pointer[breaker]
+}
+
+// InitState attaches a new state to the metadata md.
+func InitState(md metadata.Metadata) {
+	if md != nil {
+		md.Set(labelState, &State{})
+	}
+}
+
+// StateOf returns the state of the object v, or nil if v has no state.
+func StateOf(v any) *State {
+	if mi, _ := v.(metadata.Metadatable); mi != nil {
+		if md := mi.Metadata(); md != nil {
+			s, _ := md.Get(labelState).(*State)
+			return s
+		}
+	}
+	return nil
+}
+
+// Down reports whether the object is marked down manually.
+func (s *State) Down() bool {
+	if s == nil {
+		return false
+	}
+	return s.down.Load()
+}
+
+// SetDown marks the object down or up manually,
+// a down object is filtered out by FailFilter regardless of its fail count.
+func (s *State) SetDown(down bool) {
+	if s != nil {
+		s.down.Store(down)
+	}
+}
+
+// Unhealthy reports whether the object failed the health check and has not recovered yet.
+// An unhealthy object is filtered out by FailFilter until it recovers.
+func (s *State) Unhealthy() bool {
+	if s == nil {
+		return false
+	}
+	return s.unhealthy.Load()
+}
+
+// CheckFailed records a failed health check.
+func (s *State) CheckFailed() {
+	if s != nil {
+		s.unhealthy.Store(true)
+		s.successes.Store(0)
+	}
+}
+
+// CheckSucceeded records a successful health check,
+// it reports whether the object has recovered after rise consecutive successful checks.
+func (s *State) CheckSucceeded(rise int) bool {
+	if s == nil || !s.unhealthy.Load() {
+		return false
+	}
+	if s.successes.Add(1) < int64(rise) {
+		return false
+	}
+	s.unhealthy.Store(false)
+	s.successes.Store(0)
+	return true
+}
+
+// Latency returns the latency of the latest successful connection.
+func (s *State) Latency() time.Duration {
+	if s == nil {
+		return 0
+	}
+	return time.Duration(s.latency.Load())
+}
+
+// ObserveLatency records the latency of a successful connection.
+func (s *State) ObserveLatency(d time.Duration) {
+	if s == nil {
+		return
+	}
+	s.latency.Store(int64(d))
+	updateEWMA(&s.latencyAvg, float64(d), true)
+	updateEWMA(&s.errorAvg, 0, false)
+	now := time.Now()
+	s.observed.Store(now.UnixNano())
+	if b := s.breaker.Load(); b != nil {
+		b.record(now, true)
+	}
+}
+
+// ObserveError records a failed connection.
+func (s *State) ObserveError() {
+	if s == nil {
+		return
+	}
+	updateEWMA(&s.errorAvg, 1, false)
+	now := time.Now()
+	s.observed.Store(now.UnixNano())
+	if b := s.breaker.Load(); b != nil {
+		b.record(now, false)
+	}
+}
+
+// LatencyAvg returns the moving average of the latency.
+func (s *State) LatencyAvg() time.Duration {
+	if s == nil {
+		return 0
+	}
+	return time.Duration(math.Float64frombits(s.latencyAvg.Load()))
+}
+
+// ErrorRate returns the moving average of the error rate, in the range [0, 1].
+func (s *State) ErrorRate() float64 {
+	if s == nil {
+		return 0
+	}
+	return math.Float64frombits(s.errorAvg.Load())
+}
+
+// BreakerState returns the state of the circuit breaker,
+// an object without circuit breaker is always closed.
+func (s *State) BreakerState() BreakerState {
+	if s == nil {
+		return BreakerClosed
+	}
+	if b := s.breaker.Load(); b != nil {
+		return b.State()
+	}
+	return BreakerClosed
+}
+
+// Trial takes a trial connection of the circuit breaker if it is open or half-open,
+// it is called when a connection is actually made through the object.
+// The connection is not rejected if there is no trial left,
+// as the object is only selected in that case when there is no other choice.
+func (s *State) Trial() {
+	if s == nil {
+		return
+	}
+	if b := s.breaker.Load(); b != nil {
+		b.allow(time.Now())
+	}
+}
+
+// breakerOf returns the circuit breaker of the object, it is created with opts if not exists.
+func (s *State) breakerOf(opts BreakerOptions) *breaker {
+	if s == nil {
+		return nil
+	}
+	if b := s.breaker.Load(); b != nil {
+		return b
+	}
+	s.breaker.CompareAndSwap(nil, newBreaker(opts))
+	return s.breaker.Load()
+}
+
+// claim marks the stale object as being probed,
+// it reports false if the object has been claimed by others.
+func (s *State) claim(now time.Time, d time.Duration) bool {
+	if s == nil {
+		return false
+	}
+	t := s.observed.Load()
+	return now.UnixNano()-t > int64(d) && s.observed.CompareAndSwap(t, now.UnixNano())
+}
+
+// updateEWMA adds the value v to the moving average stored in p,
+// if init is true, the average starts from the first value rather than zero.
+func updateEWMA(p *atomic.Uint64, v float64, init bool) {
+	for {
+		old := p.Load()
+		avg := v
+		if old != 0 || !init {
+			avg = ewmaAlpha*v + (1-ewmaAlpha)*math.Float64frombits(old)
+		}
+		if p.CompareAndSwap(old, math.Float64bits(avg)) {
+			return
+		}
+	}
+}
+
+// Conns returns the number of the active connections through the object.
+func (s *State) Conns() int64 {
+	if s == nil {
+		return 0
+	}
+	return s.conns.Load()
+}
+
+// IncConns increases the number of the active connections.
+func (s *State) IncConns() {
+	if s != nil {
+		s.conns.Add(1)
+	}
+}
+
+// DecConns decreases the number of the active connections.
+func (s *State) DecConns() {
+	if s != nil {
+		s.conns.Add(-1)
+	}
+}
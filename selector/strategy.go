@@ -9,8 +9,6 @@ import (
 	"time"
 
 	"github.com/go-gost/core/logger"
-	"github.com/go-gost/core/metadata"
-	mdutil "github.com/go-gost/core/metadata/util"
 	"github.com/go-gost/core/selector"
 	ctxvalue "github.com/go-gost/x/internal/ctx"
 )
@@ -30,7 +28,14 @@ func (s *roundRobinStrategy[T]) Apply(ctx context.Context, vs ...T) (v T) {
 		return
 	}
 
-	n := atomic.AddUint64(&s.counter, 1) - 1
+	// the selection excluding the nodes already selected (failover and racing)
+	// does not advance the counter, to keep the distribution of the first selections.
+	var n uint64
+	if hasExcluded(ctx) {
+		n = atomic.LoadUint64(&s.counter)
+	} else {
+		n = atomic.AddUint64(&s.counter, 1) - 1
+	}
 	return vs[int(n%uint64(len(vs)))]
 }
 
@@ -57,16 +62,100 @@ func (s *randomStrategy[T]) Apply(ctx context.Context, vs ...T) (v T) {
 
 	s.rw.Reset()
 	for i := range vs {
-		weight := 0
-		if md, _ := any(vs[i]).(metadata.Metadatable); md != nil {
-			weight = mdutil.GetInt(md.Metadata(), labelWeight)
+		s.rw.Add(vs[i], Weight(vs[i]))
+	}
+
+	return s.rw.Next()
+}
+
+type leastConnStrategy[T any] struct {
+	counter uint64
+}
+
+// LeastConnStrategy is a strategy for node selector.
+// The node with the least active connections relative to its weight will be selected,
+// the ties are broken by round-robin.
+func LeastConnStrategy[T any]() selector.Strategy[T] {
+	return &leastConnStrategy[T]{}
+}
+
+func (s *leastConnStrategy[T]) Apply(ctx context.Context, vs ...T) (v T) {
+	if len(vs) == 0 {
+		return
+	}
+
+	n := len(vs)
+	start := int(atomic.AddUint64(&s.counter, 1) % uint64(n))
+
+	best := -1
+	var bestConns, bestWeight int64
+	for i := 0; i < n; i++ {
+		k := (start + i) % n
+		conns, weight := StateOf(vs[k]).Conns(), int64(Weight(vs[k]))
+		// conns/weight < bestConns/bestWeight
+		if best < 0 || conns*bestWeight < bestConns*weight {
+			best, bestConns, bestWeight = k, conns, weight
+		}
+	}
+	return vs[best]
+}
+
+const (
+	// the nodes within the tolerance of the lowest cost are selected randomly.
+	latencyTolerance = 0.2
+	// the latency added to the cost of a node for its error rate.
+	latencyErrorPenalty = 5 * time.Second
+	// the node without observation in this duration is probed by a real connection.
+	latencyStaleTime = 30 * time.Second
+)
+
+type latencyStrategy[T any] struct {
+	rw *randomWeighted[T]
+	mu sync.Mutex
+}
+
+// LatencyStrategy is a strategy for node selector.
+// The node with the lowest moving average of latency and error rate will be selected,
+// the nodes close to the best are selected randomly by weight to avoid herding.
+// The nodes without recent observation are probed one connection at a time.
+func LatencyStrategy[T any]() selector.Strategy[T] {
+	return &latencyStrategy[T]{
+		rw: newRandomWeighted[T](),
+	}
+}
+
+func (s *latencyStrategy[T]) Apply(ctx context.Context, vs ...T) (v T) {
+	if len(vs) == 0 {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for _, i := range s.rw.r.Perm(len(vs)) {
+		if StateOf(vs[i]).claim(now, latencyStaleTime) {
+			return vs[i]
 		}
-		if weight <= 0 {
-			weight = 1
+	}
+
+	// the nodes being probed for the first time have no cost yet.
+	costs := make([]float64, len(vs))
+	best := -1.0
+	for i := range vs {
+		st := StateOf(vs[i])
+		costs[i] = float64(st.LatencyAvg()) + st.ErrorRate()*float64(latencyErrorPenalty)
+		if costs[i] > 0 && (best < 0 || costs[i] < best) {
+			best = costs[i]
 		}
-		s.rw.Add(vs[i], weight)
 	}
 
+	s.rw.Reset()
+	for i := range vs {
+		if best < 0 || costs[i] > 0 && costs[i] <= best*(1+latencyTolerance)+float64(time.Millisecond) {
+			s.rw.Add(vs[i], Weight(vs[i]))
+		}
+	}
 	return s.rw.Next()
 }
 
@@ -88,13 +177,24 @@ func (s *fifoStrategy[T]) Apply(ctx context.Context, vs ...T) (v T) {
 }
 
 type hashStrategy[T any] struct {
-	r  *rand.Rand
-	mu sync.Mutex
+	key string
+	r   *rand.Rand
+	mu  sync.Mutex
 }
 
+// HashStrategy is a strategy for node selector.
+// The node will be selected by the hash value of the request modulo the number of the nodes.
 func HashStrategy[T any]() selector.Strategy[T] {
+	return HashKeyStrategy[T]("")
+}
+
+// HashKeyStrategy is a strategy for node selector.
+// The node will be selected by the hash value of the hash key modulo the number of the nodes,
+// the empty key hashes the request as HashStrategy.
+func HashKeyStrategy[T any](key string) selector.Strategy[T] {
 	return &hashStrategy[T]{
-		r: rand.New(rand.NewSource(time.Now().UnixNano())),
+		key: key,
+		r:   rand.New(rand.NewSource(time.Now().UnixNano())),
 	}
 }
 
@@ -102,9 +202,9 @@ func (s *hashStrategy[T]) Apply(ctx context.Context, vs ...T) (v T) {
 	if len(vs) == 0 {
 		return
 	}
-	if h := ctxvalue.HashFromContext(ctx); h != nil {
-		value := uint64(crc32.ChecksumIEEE([]byte(h.Source)))
-		logger.Default().Tracef("hash %s %d", h.Source, value)
+	if source := hashValue(ctx, s.key); source != "" || ctxvalue.HashFromContext(ctx) != nil {
+		value := uint64(crc32.ChecksumIEEE([]byte(source)))
+		logger.Default().Tracef("hash %s %d", source, value)
 		return vs[value%uint64(len(vs))]
 	}
 
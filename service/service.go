@@ -6,6 +6,7 @@ import (
 	"net"
 	"os/exec"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-gost/core/admission"
@@ -15,19 +16,21 @@ import (
 	"github.com/go-gost/core/metrics"
 	"github.com/go-gost/core/recorder"
 	"github.com/go-gost/core/service"
+	"github.com/go-gost/x/internal/conntrack"
 	ctxvalue "github.com/go-gost/x/internal/ctx"
 	xmetrics "github.com/go-gost/x/metrics"
 	"github.com/rs/xid"
 )
 
 type options struct {
-	admission admission.Admission
-	recorders []recorder.RecorderObject
-	preUp     []string
-	postUp    []string
-	preDown   []string
-	postDown  []string
-	logger    logger.Logger
+	admission   admission.Admission
+	recorders   []recorder.RecorderObject
+	preUp       []string
+	postUp      []string
+	preDown     []string
+	postDown    []string
+	gracePeriod time.Duration
+	logger      logger.Logger
 }
 
 type Option func(opts *options)
@@ -68,6 +71,14 @@ func PostDownOption(cmds []string) Option {
 	}
 }
 
+// GracePeriodOption sets the maximum duration to wait for
+// the active connections to be done when the service is closed.
+func GracePeriodOption(d time.Duration) Option {
+	return func(opts *options) {
+		opts.gracePeriod = d
+	}
+}
+
 func LoggerOption(logger logger.Logger) Option {
 	return func(opts *options) {
 		opts.logger = logger
@@ -79,6 +90,14 @@ type defaultService struct {
 	listener listener.Listener
 	handler  handler.Handler
 	options  options
+
+	// the active connections.
+	conns     map[net.Conn]struct{}
+	wg        sync.WaitGroup
+	closed    bool
+	mu        sync.Mutex
+	closeOnce sync.Once
+	done      chan struct{}
 }
 
 func NewService(name string, ln listener.Listener, h handler.Handler, opts ...Option) service.Service {
@@ -91,6 +110,8 @@ func NewService(name string, ln listener.Listener, h handler.Handler, opts ...Op
 		listener: ln,
 		handler:  h,
 		options:  options,
+		conns:    make(map[net.Conn]struct{}),
+		done:     make(chan struct{}),
 	}
 
 	s.execCmds("pre-up", s.options.preUp)
@@ -102,14 +123,93 @@ func (s *defaultService) Addr() net.Addr {
 	return s.listener.Addr()
 }
 
-func (s *defaultService) Close() error {
-	s.execCmds("pre-down", s.options.preDown)
-	defer s.execCmds("post-down", s.options.postDown)
+// Close stops accepting new connections.
+// If the grace period is set, the active connections are drained in the background,
+// and the ones still active when the grace period expires are closed forcibly.
+func (s *defaultService) Close() (err error) {
+	s.closeOnce.Do(func() {
+		s.execCmds("pre-down", s.options.preDown)
+
+		s.mu.Lock()
+		s.closed = true
+		s.mu.Unlock()
+
+		err = s.listener.Close()
+
+		if s.options.gracePeriod > 0 {
+			go s.drain()
+			return
+		}
+		s.shutdown()
+	})
+	return
+}
+
+// Done returns a channel that is closed when the service is completely shut down.
+func (s *defaultService) Done() <-chan struct{} {
+	return s.done
+}
+
+func (s *defaultService) drain() {
+	s.mu.Lock()
+	n := len(s.conns)
+	s.mu.Unlock()
+	if n > 0 {
+		s.options.logger.Infof("draining %d connections in %v", n, s.options.gracePeriod)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	timer := time.NewTimer(s.options.gracePeriod)
+	defer timer.Stop()
+
+	select {
+	case <-done:
+	case <-timer.C:
+		s.mu.Lock()
+		n := len(s.conns)
+		for conn := range s.conns {
+			conn.Close()
+		}
+		s.mu.Unlock()
+		s.options.logger.Warnf("grace period expired, %d connections closed", n)
+	}
+
+	s.shutdown()
+}
 
+func (s *defaultService) shutdown() {
 	if closer, ok := s.handler.(io.Closer); ok {
 		closer.Close()
 	}
-	return s.listener.Close()
+	s.execCmds("post-down", s.options.postDown)
+	close(s.done)
+}
+
+// track adds the connection conn to the active connections,
+// it returns false if the service is closed.
+func (s *defaultService) track(conn net.Conn) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return false
+	}
+	s.conns[conn] = struct{}{}
+	s.wg.Add(1)
+	return true
+}
+
+func (s *defaultService) untrack(conn net.Conn) {
+	s.mu.Lock()
+	delete(s.conns, conn)
+	s.mu.Unlock()
+
+	s.wg.Done()
 }
 
 func (s *defaultService) Serve() error {
@@ -151,7 +251,8 @@ func (s *defaultService) Serve() error {
 			clientIP = h
 		}
 
-		ctx := ctxvalue.ContextWithSid(context.Background(), ctxvalue.Sid(xid.New().String()))
+		sid := xid.New().String()
+		ctx := ctxvalue.ContextWithSid(context.Background(), ctxvalue.Sid(sid))
 		ctx = ctxvalue.ContextWithClientAddr(ctx, ctxvalue.ClientAddr(clientAddr))
 		ctx = ctxvalue.ContextWithHash(ctx, &ctxvalue.Hash{Source: clientIP})
 
@@ -170,7 +271,18 @@ func (s *defaultService) Serve() error {
 			continue
 		}
 
+		if !s.track(conn) {
+			conn.Close()
+			continue
+		}
+
+		tc := conntrack.NewConn(sid, s.name, conn)
+		conntrack.Add(tc)
+
 		go func() {
+			defer s.untrack(conn)
+			defer conntrack.Remove(tc)
+
 			if v := xmetrics.GetCounter(xmetrics.MetricServiceRequestsCounter,
 				metrics.Labels{"service": s.name, "client": clientIP}); v != nil {
 				v.Inc()
@@ -190,7 +302,7 @@ func (s *defaultService) Serve() error {
 				}()
 			}
 
-			if err := s.handler.Handle(ctx, conn); err != nil {
+			if err := s.handler.Handle(ctx, tc.Conn()); err != nil {
 				s.options.logger.Error(err)
 				if v := xmetrics.GetCounter(xmetrics.MetricServiceHandlerErrorsCounter,
 					metrics.Labels{"service": s.name, "client": clientIP}); v != nil {
@@ -201,6 +313,16 @@ func (s *defaultService) Serve() error {
 	}
 }
 
+// Shutdown closes the service svc and waits for it to be completely shut down,
+// see GracePeriodOption.
+func Shutdown(svc service.Service) error {
+	err := svc.Close()
+	if v, ok := svc.(interface{ Done() <-chan struct{} }); ok {
+		<-v.Done()
+	}
+	return err
+}
+
 func (s *defaultService) execCmds(phase string, cmds []string) {
 	for _, cmd := range cmds {
 		cmd := strings.TrimSpace(cmd)